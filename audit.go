@@ -0,0 +1,210 @@
+package env
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The auditLimit is the maximum number of records in the audit trail,
+// the oldest records are removed when the limit is reached.
+const auditLimit = 1024
+
+// AuditRecord is the record about one change of the environment
+// made through this package. The values aren't stored in the record,
+// only their hashes keyed by the random key of the process: the equal
+// values have the equal hashes within the process, but the values
+// (even the short ones, like PINs) can't be found by the hashes from
+// the logs without the key.
+type AuditRecord struct {
+	Time    time.Time // time of the change
+	Op      string    // operation: set, unset or clear
+	Key     string    // key name, empty for the clear operation
+	OldHash string    // hash of the old value, empty if the key was unset
+	NewHash string    // hash of the new value, empty if the key is unset
+	Caller  string    // file:line of the code that made the change
+}
+
+// String returns the record as a single line for logging.
+func (r AuditRecord) String() string {
+	return fmt.Sprintf("%s %s %s %s->%s %s",
+		r.Time.Format(time.RFC3339), r.Op, r.Key,
+		r.OldHash, r.NewHash, r.Caller)
+}
+
+var (
+	// The auditEnabled is true if the changes are recorded.
+	auditEnabled atomic.Bool
+
+	// The auditMu protects the auditTrail, auditHead and auditHook.
+	auditMu sync.Mutex
+
+	// The auditTrail is the ring buffer of the recorded changes,
+	// it grows up to the auditLimit records.
+	auditTrail []AuditRecord
+
+	// The auditHead is the index of the oldest record
+	// in the auditTrail when it is full.
+	auditHead int
+
+	// The auditHook is called for each recorded change.
+	auditHook func(AuditRecord)
+
+	// The auditKey is the random key of the hashes of the values,
+	// generated once for the process.
+	auditKey = newAuditKey()
+)
+
+// EnableAudit starts recording of every change of the environment made
+// through this package (Set, Unset, Clear, Load, Update, Marshal etc.).
+// The records are available through AuditTrail. If hook isn't nil, it's
+// called synchronously for each record (for example, to write the record
+// into a log). The hook is called without the lock, so it can change
+// the environment (the changes are recorded too) or read the trail.
+//
+// The trail keeps the last 1024 records.
+//
+// # Examples
+//
+//	env.EnableAudit(func(r env.AuditRecord) {
+//		log.Println("env:", r)
+//	})
+func EnableAudit(hook func(AuditRecord)) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	auditHook = hook
+	auditEnabled.Store(true)
+}
+
+// DisableAudit stops recording of the changes and clears the trail.
+func DisableAudit() {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	auditEnabled.Store(false)
+	auditHook, auditTrail, auditHead = nil, nil, 0
+}
+
+// AuditTrail returns a copy of the recorded changes
+// in the order in which they were made.
+func AuditTrail() []AuditRecord {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	result := make([]AuditRecord, 0, len(auditTrail))
+	result = append(result, auditTrail[auditHead:]...)
+	return append(result, auditTrail[:auditHead]...)
+}
+
+// The audit records the change of the environment if the audit is
+// enabled. The old and new values are passed with the flags whether
+// they are set.
+func audit(op, key, oldValue string, oldOk bool, newValue string, newOk bool) {
+	r := AuditRecord{
+		Time:   time.Now(),
+		Op:     op,
+		Key:    key,
+		Caller: auditCaller(),
+	}
+
+	if oldOk {
+		r.OldHash = valueHash(oldValue)
+	}
+
+	if newOk {
+		r.NewHash = valueHash(newValue)
+	}
+
+	auditMu.Lock()
+
+	// The audit can be disabled while the record was created.
+	if !auditEnabled.Load() {
+		auditMu.Unlock()
+		return
+	}
+
+	// The oldest record is overwritten if the trail is full.
+	if len(auditTrail) < auditLimit {
+		auditTrail = append(auditTrail, r)
+	} else {
+		auditTrail[auditHead] = r
+		auditHead = (auditHead + 1) % auditLimit
+	}
+	hook := auditHook
+	auditMu.Unlock()
+
+	// The hook is called without the lock,
+	// so it can change the environment.
+	if hook != nil {
+		hook(r)
+	}
+}
+
+// The newAuditKey returns the random key for the hashes of the values.
+func newAuditKey() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("env: audit key: %v", err))
+	}
+
+	return key
+}
+
+// The valueHash returns the short hash of the value
+// keyed by the key of the process (HMAC-SHA256).
+func valueHash(value string) string {
+	mac := hmac.New(sha256.New, auditKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// The auditCaller returns the file:line of the first caller
+// outside of this package (tests of the package are considered
+// as the outside code).
+func auditCaller() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		inside := strings.HasPrefix(frame.Function, modulePath+".") &&
+			!strings.HasSuffix(frame.File, "_test.go")
+		if !inside {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// The auditSet records setting of the key if the audit is enabled.
+// It must be called before the change to get the old value.
+func auditSet(key, value string) func() {
+	if !auditEnabled.Load() {
+		return func() {}
+	}
+
+	old, ok := getenv(key)
+	return func() { audit("set", key, old, ok, value, true) }
+}
+
+// The auditUnset records unsetting of the key if the audit is enabled.
+// It must be called before the change to get the old value.
+func auditUnset(key string) func() {
+	if !auditEnabled.Load() {
+		return func() {}
+	}
+
+	old, ok := getenv(key)
+	return func() { audit("unset", key, old, ok, "", false) }
+}
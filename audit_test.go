@@ -0,0 +1,134 @@
+package env
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAudit tests EnableAudit and AuditTrail functions.
+func TestAudit(t *testing.T) {
+	defer DisableAudit()
+
+	var hooked []AuditRecord
+	os.Clearenv()
+	os.Setenv("KEY_0", "old")
+	EnableAudit(func(r AuditRecord) { hooked = append(hooked, r) })
+
+	Set("KEY_0", "new")
+	Unset("KEY_0")
+	Update("./fixtures/simple.env")
+	Clear()
+
+	trail := AuditTrail()
+	expected := []struct{ op, key string }{
+		{"set", "KEY_0"},
+		{"unset", "KEY_0"},
+		{"set", "KEY_0"},
+		{"set", "KEY_1"},
+		{"clear", ""},
+	}
+
+	if len(trail) != len(expected) || len(hooked) != len(expected) {
+		t.Fatalf("expected %d records but %d (%d hooked)",
+			len(expected), len(trail), len(hooked))
+	}
+
+	for i, e := range expected {
+		if trail[i].Op != e.op || trail[i].Key != e.key {
+			t.Errorf("record %d: expected %s %s but %s %s",
+				i, e.op, e.key, trail[i].Op, trail[i].Key)
+		}
+	}
+
+	// Hashes.
+	if trail[0].OldHash != valueHash("old") ||
+		trail[0].NewHash != valueHash("new") {
+		t.Errorf("incorrect hashes: %v", trail[0])
+	}
+
+	if trail[1].NewHash != "" || trail[2].OldHash != "" {
+		t.Errorf("hashes of unset values should be empty")
+	}
+
+	// The caller is outside the package code.
+	if !strings.Contains(trail[0].Caller, "audit_test.go") {
+		t.Errorf("incorrect caller: %s", trail[0].Caller)
+	}
+
+	if strings.Contains(trail[0].String(), "old") {
+		t.Errorf("the record shouldn't contain values: %s", trail[0])
+	}
+
+	// Disabled audit.
+	DisableAudit()
+	Set("KEY_0", "value")
+	if len(AuditTrail()) != 0 {
+		t.Error("the trail should be empty")
+	}
+}
+
+// TestAuditHook tests that the hook can change the environment
+// and read the trail.
+func TestAuditHook(t *testing.T) {
+	defer DisableAudit()
+
+	var trails []int
+	os.Clearenv()
+	EnableAudit(func(r AuditRecord) {
+		if r.Key == "KEY_0" {
+			Set("KEY_1", r.NewHash) // recorded too
+		}
+		trails = append(trails, len(AuditTrail()))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Set("KEY_0", "value")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the hook is deadlocked")
+	}
+
+	if fmt.Sprint(trails) != "[2 2]" {
+		t.Errorf("expected `[2 2]` but `%v`", trails)
+	}
+
+	// The hash isn't the plain hash of the value.
+	sum := sha256.Sum256([]byte("value"))
+	if h := AuditTrail()[0].NewHash; h == hex.EncodeToString(sum[:8]) {
+		t.Errorf("the hash should be keyed: %s", h)
+	}
+}
+
+// TestAuditLimit tests that the trail keeps the last records only.
+func TestAuditLimit(t *testing.T) {
+	defer DisableAudit()
+
+	os.Clearenv()
+	EnableAudit(nil)
+
+	const extra = 10
+	for i := 0; i < auditLimit+extra; i++ {
+		Set(fmt.Sprintf("KEY_%d", i), "value")
+	}
+
+	trail := AuditTrail()
+	if len(trail) != auditLimit {
+		t.Fatalf("expected %d records but %d", auditLimit, len(trail))
+	}
+
+	for i, r := range trail {
+		if key := fmt.Sprintf("KEY_%d", i+extra); r.Key != key {
+			t.Fatalf("record %d: expected %s but %s", i, key, r.Key)
+		}
+	}
+}
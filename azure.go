@@ -0,0 +1,229 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// The keyVaultPrefix is the prefix of the Key Vault reference
+	// in the settings of Azure App Service and Azure Functions.
+	keyVaultPrefix = "@Microsoft.KeyVault("
+
+	// The keyVaultDomain is the default domain of the Key Vault.
+	keyVaultDomain = ".vault.azure.net"
+)
+
+// The keyVaultDomains are the domains of the Key Vault
+// in the public and sovereign clouds of Azure.
+var keyVaultDomains = []string{
+	keyVaultDomain,
+	".vault.azure.cn",
+	".vault.usgovcloudapi.net",
+	".vault.microsoftazure.de",
+}
+
+// KeyVaultReference is the reference to the secret of Azure Key Vault.
+type KeyVaultReference struct {
+	VaultURL      string // like https://myvault.vault.azure.net
+	SecretName    string // name of the secret
+	SecretVersion string // version of the secret, empty for the latest
+}
+
+// URI returns the identifier of the secret, like
+// https://myvault.vault.azure.net/secrets/mysecret/version.
+func (r KeyVaultReference) URI() string {
+	uri := fmt.Sprintf("%s/secrets/%s", r.VaultURL, r.SecretName)
+	if r.SecretVersion != "" {
+		uri += "/" + r.SecretVersion
+	}
+
+	return uri
+}
+
+// ParseKeyVaultReference parses the value as a reference to the secret
+// of Azure Key Vault. The following forms are supported:
+//
+//   - @Microsoft.KeyVault(SecretUri=https://myvault.vault.azure.net/
+//     secrets/mysecret/version) - the reference of Azure App Service;
+//   - @Microsoft.KeyVault(VaultName=myvault;SecretName=mysecret;
+//     SecretVersion=version) - the same with the version optional;
+//   - {"uri":"https://myvault.vault.azure.net/secrets/mysecret"} - the
+//     Key Vault reference of Azure App Configuration, the JSON values
+//     with the uri of other hosts (not *.vault.azure.net or the domains
+//     of the sovereign clouds) aren't references.
+//
+// The boolean is false if the value isn't a reference, an error is
+// returned if it looks like a reference but is malformed.
+func ParseKeyVaultReference(value string) (KeyVaultReference, bool, error) {
+	var ref KeyVaultReference
+
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, keyVaultPrefix):
+		body, ok := strings.CutSuffix(value[len(keyVaultPrefix):], ")")
+		if !ok {
+			return ref, true, fmt.Errorf("missing closing bracket: %s",
+				value)
+		}
+
+		for _, item := range strings.Split(body, ";") {
+			k, v, _ := strings.Cut(item, "=")
+			switch strings.TrimSpace(k) {
+			case "SecretUri":
+				r, err := parseSecretURI(strings.TrimSpace(v))
+				if err != nil {
+					return ref, true, err
+				}
+				ref = r
+			case "VaultName":
+				ref.VaultURL = "https://" + strings.TrimSpace(v) +
+					keyVaultDomain
+			case "SecretName":
+				ref.SecretName = strings.TrimSpace(v)
+			case "SecretVersion":
+				ref.SecretVersion = strings.TrimSpace(v)
+			}
+		}
+	case strings.HasPrefix(value, "{") && strings.Contains(value, `"uri"`):
+		var data struct {
+			URI string `json:"uri"`
+		}
+
+		// The ordinary JSON value isn't a reference.
+		if err := json.Unmarshal([]byte(value), &data); err != nil ||
+			!isKeyVaultURI(data.URI) {
+			return ref, false, nil
+		}
+
+		r, err := parseSecretURI(data.URI)
+		if err != nil {
+			return ref, true, err
+		}
+		ref = r
+	default:
+		return ref, false, nil
+	}
+
+	if ref.VaultURL == "" || ref.SecretName == "" {
+		return ref, true, fmt.Errorf("incorrect reference: %s", value)
+	}
+
+	return ref, true, nil
+}
+
+// The parseSecretURI parses the identifier of the Key Vault secret.
+func parseSecretURI(uri string) (KeyVaultReference, error) {
+	var ref KeyVaultReference
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ref, err
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Scheme != "https" || u.Host == "" || len(parts) < 2 ||
+		len(parts) > 3 || parts[0] != "secrets" || parts[1] == "" {
+		return ref, fmt.Errorf("incorrect secret uri: %s", uri)
+	}
+
+	ref.VaultURL = "https://" + u.Host
+	ref.SecretName = parts[1]
+	if len(parts) == 3 {
+		ref.SecretVersion = parts[2]
+	}
+
+	return ref, nil
+}
+
+// The isKeyVaultURI returns true if the host
+// of the uri is the host of the Key Vault.
+func isKeyVaultURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range keyVaultDomains {
+		if strings.HasSuffix(host, domain) && len(host) > len(domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyVaultResolver returns the value of the secret by the reference,
+// usually it is implemented by the Azure SDK client of the Key Vault.
+type KeyVaultResolver func(ctx context.Context,
+	ref KeyVaultReference) (string, error)
+
+// WithKeyVault returns the Source that resolves the values of the src
+// that are Key Vault references (see ParseKeyVaultReference) by the
+// resolver, other values are returned as is. So the same Unmarshal
+// workflow can be used with the settings of Azure App Service or the
+// keys of Azure App Configuration (wrapped into Source) that refer to
+// the Key Vault.
+//
+// # Examples
+//
+//	client, _ := azsecrets.NewClient(vaultURL, credential, nil)
+//	resolver := func(ctx context.Context,
+//		ref env.KeyVaultReference) (string, error) {
+//		resp, err := client.GetSecret(ctx, ref.SecretName,
+//			ref.SecretVersion, nil)
+//		if err != nil {
+//			return "", err
+//		}
+//		return *resp.Value, nil
+//	}
+//
+//	src := env.WithKeyVault(env.EnvSource(), resolver)
+//	err := env.UnmarshalSource(ctx, src, "APP_", &config)
+func WithKeyVault(src Source, resolve KeyVaultResolver) Source {
+	return &keyVaultSource{src: src, resolve: resolve}
+}
+
+// The keyVaultSource is the Source that resolves
+// the Key Vault references (see WithKeyVault).
+type keyVaultSource struct {
+	src     Source
+	resolve KeyVaultResolver
+}
+
+// Lookup returns the value of the key, the reference is resolved.
+func (s *keyVaultSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	value, ok, err := s.src.Lookup(ctx, key)
+	if err != nil || !ok {
+		return value, ok, err
+	}
+
+	ref, isRef, err := ParseKeyVaultReference(value)
+	if err != nil {
+		return "", false, &KeyError{Key: key, Err: err}
+	} else if !isRef {
+		return value, true, nil
+	}
+
+	value, err = s.resolve(ctx, ref)
+	if err != nil {
+		return "", false, &KeyError{Key: key, Err: err}
+	}
+
+	return value, true, nil
+}
+
+// Keys returns the keys of the source, nil if it doesn't list them
+// (see KeyLister).
+func (s *keyVaultSource) Keys(ctx context.Context) ([]string, error) {
+	if lister, ok := s.src.(KeyLister); ok {
+		return lister.Keys(ctx)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseKeyVaultReference tests ParseKeyVaultReference function.
+func TestParseKeyVaultReference(t *testing.T) {
+	const uri = "https://vault.vault.azure.net/secrets/db/v1"
+
+	tests := []struct {
+		value string
+		uri   string
+		isRef bool
+		err   bool
+	}{
+		{"plain value", "", false, false},
+		{"@Microsoft.KeyVault(SecretUri=" + uri + ")", uri, true, false},
+		{"@Microsoft.KeyVault(VaultName=vault;SecretName=db;" +
+			"SecretVersion=v1)", uri, true, false},
+		{"@Microsoft.KeyVault(VaultName=vault;SecretName=db)",
+			"https://vault.vault.azure.net/secrets/db", true, false},
+		{`{"uri":"` + uri + `"}`, uri, true, false},
+		{"@Microsoft.KeyVault(VaultName=vault", "", true, true},
+		{"@Microsoft.KeyVault(VaultName=vault)", "", true, true},
+		{"@Microsoft.KeyVault(SecretUri=http://vault/keys/db)", "",
+			true, true},
+		{`{"uri":"https://v.vault.azure.net/keys/db"}`, "", true, true},
+		{`{"uri":"https://idp.example.com"}`, "", false, false},
+		{`{"uri":"https://vault.azure.net.example.com/secrets/db"}`, "",
+			false, false},
+		{`{"uri":`, "", false, false},
+	}
+
+	for _, test := range tests {
+		ref, isRef, err := ParseKeyVaultReference(test.value)
+		if isRef != test.isRef || (err != nil) != test.err {
+			t.Errorf("%s: expected %v/%v but %v/%v", test.value,
+				test.isRef, test.err, isRef, err)
+			continue
+		}
+
+		if test.uri != "" && ref.URI() != test.uri {
+			t.Errorf("expected `%s` but `%s`", test.uri, ref.URI())
+		}
+	}
+}
+
+// TestWithKeyVault tests WithKeyVault function.
+func TestWithKeyVault(t *testing.T) {
+	values := map[string]string{
+		"HOST":     "localhost",
+		"PASSWORD": "@Microsoft.KeyVault(VaultName=v;SecretName=db)",
+		"TOKEN":    "@Microsoft.KeyVault(VaultName=v;SecretName=token)",
+	}
+
+	src := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		value, ok := values[key]
+		return value, ok, nil
+	})
+
+	errNotFound := errors.New("secret not found")
+	resolver := func(ctx context.Context,
+		ref KeyVaultReference) (string, error) {
+		if ref.SecretName == "db" {
+			return "qwerty", nil
+		}
+		return "", errNotFound
+	}
+
+	var config struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD"`
+	}
+
+	ctx := context.Background()
+	kv := WithKeyVault(src, resolver)
+	if err := UnmarshalSource(ctx, kv, "", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Host != "localhost" || config.Password != "qwerty" {
+		t.Errorf("incorrect result: %v", config)
+	}
+
+	if _, _, err := kv.Lookup(ctx, "TOKEN"); !errors.Is(err, errNotFound) {
+		t.Errorf("expected errNotFound but %v", err)
+	}
+
+	if _, ok, err := kv.Lookup(ctx, "USER"); ok || err != nil {
+		t.Errorf("expected missing key but %v/%v", ok, err)
+	}
+
+	// The ordinary JSON value isn't a reference.
+	values["IDP"] = `{"uri":"https://idp.example.com"}`
+	if v, _, err := kv.Lookup(ctx, "IDP"); err != nil || v != values["IDP"] {
+		t.Errorf("expected `%s` but `%s` (%v)", values["IDP"], v, err)
+	}
+
+	// The keys of the source are listed for the maps of structures.
+	filename := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(filename, []byte("DB_MAIN_PASSWORD="+
+		"@Microsoft.KeyVault(VaultName=v;SecretName=db)\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dbs struct {
+		DB map[string]struct {
+			Password string `env:"PASSWORD"`
+		} `env:"DB"`
+	}
+
+	kv = WithKeyVault(FileSource(filename), resolver)
+	if err := UnmarshalSource(ctx, kv, "", &dbs); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := dbs.DB["MAIN"].Password; v != "qwerty" {
+		t.Errorf("expected `qwerty` but `%s`", v)
+	}
+}
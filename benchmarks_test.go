@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -46,11 +48,11 @@ func BenchmarkLookup(b *testing.B) {
 }
 
 // Benchmark string parsing
-func BenchmarkSplitN(b *testing.B) {
+func BenchmarkSplitList(b *testing.B) {
 	str := "value1,value2,value3,value4,value5"
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		splitN(str, ",", -1)
+		splitList(str, ",")
 	}
 }
 
@@ -67,6 +69,20 @@ func BenchmarkMarshalSimple(b *testing.B) {
 	}
 }
 
+func BenchmarkEncoderSimple(b *testing.B) {
+	config := testConfig{
+		Host: "localhost",
+		Port: 8080,
+		IPs:  []string{"127.0.0.1", "192.168.1.1"},
+	}
+	enc := NewEncoder("TEST_")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.Encode(config)
+	}
+}
+
 func BenchmarkUnmarshalSimple(b *testing.B) {
 	Set("TEST_HOST", "localhost")
 	Set("TEST_PORT", "8080")
@@ -102,6 +118,25 @@ NESTED_VALUE=42
 	}
 }
 
+// Benchmark loading of the large env-file
+func BenchmarkLoadLargeEnvFile(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "# Comment for the KEY_%d\n", i)
+		fmt.Fprintf(&sb, "KEY_%d=\"value_%d\" # inline comment\n", i, i)
+	}
+
+	tmpfile := b.TempDir() + "/.env"
+	if err := os.WriteFile(tmpfile, []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Update(tmpfile)
+	}
+}
+
 // Benchmark parallel operations
 func BenchmarkParallelUnmarshal(b *testing.B) {
 	Set("TEST_HOST", "localhost")
@@ -116,6 +151,35 @@ func BenchmarkParallelUnmarshal(b *testing.B) {
 	})
 }
 
+// Benchmark sequential and parallel unmarshal of the structures
+// with different number of fields
+func BenchmarkUnmarshalParallel(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		fields := make([]reflect.StructField, n)
+		for i := range fields {
+			fields[i] = reflect.StructField{
+				Name: fmt.Sprintf("Field%d", i),
+				Type: reflect.TypeOf([]float64{}),
+				Tag:  reflect.StructTag(fmt.Sprintf(`env:"KEY_%d"`, i)),
+			}
+			Set(fmt.Sprintf("BENCH_KEY_%d", i), "1.5 2.5 3.5 4.5")
+		}
+		typ := reflect.StructOf(fields)
+
+		b.Run(fmt.Sprintf("Sequential-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Unmarshal("BENCH_", reflect.New(typ).Interface())
+			}
+		})
+
+		b.Run(fmt.Sprintf("Parallel-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				UnmarshalParallel("BENCH_", reflect.New(typ).Interface(), 0)
+			}
+		})
+	}
+}
+
 // Benchmark with different parallel tasks settings
 func BenchmarkParallelTasks(b *testing.B) {
 	tests := []int{2, 4, 8, 16}
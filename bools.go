@@ -0,0 +1,100 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// The strictBool is true if the boolean values are parsed
+	// without the float fallback (see StrictBool).
+	strictBool atomic.Bool
+
+	// The boolMu protects the boolStrings.
+	boolMu sync.RWMutex
+
+	// The boolStrings contains the registered synonyms of the boolean
+	// values in lower case (see RegisterBoolStrings).
+	boolStrings = map[string]bool{}
+)
+
+// StrictBool sets whether the values of the boolean fields are parsed
+// strictly. Returns the previous mode.
+//
+// The boolean value can be one of the forms accepted by strconv.ParseBool
+// (1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False) or one of the
+// synonyms registered by RegisterBoolStrings. In the default (not strict)
+// mode any other number is also accepted and is true if its absolute
+// value is greater than 0.7 (so 0.8 is true), in the strict mode it is
+// an error.
+//
+// # Examples
+//
+//	env.StrictBool(true)
+//	env.Set("DEBUG", "0.8")
+//
+//	var config struct {
+//		Debug bool `env:"DEBUG"`
+//	}
+//	err := env.Unmarshal("", &config) // error
+func StrictBool(enabled bool) bool {
+	return strictBool.Swap(enabled)
+}
+
+// RegisterBoolStrings registers the additional words accepted as boolean
+// values, like yes/no or on/off. The words are case-insensitive. They are
+// accepted in the values of the boolean fields (including def tags checked
+// by ValidateStructTags) and in the required and secret tags.
+//
+// Returns an error if the word is empty or is one of the forms accepted
+// by strconv.ParseBool with the other meaning, nothing is registered
+// in this case. The registered word can be overridden.
+//
+// # Examples
+//
+//	err := env.RegisterBoolStrings(map[string]bool{
+//		"yes": true, "on": true, "enabled": true,
+//		"no": false, "off": false, "disabled": false,
+//	})
+func RegisterBoolStrings(values map[string]bool) error {
+	for word, value := range values {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			return errors.New("empty boolean word")
+		}
+
+		if r, err := strconv.ParseBool(word); err == nil && r != value {
+			return fmt.Errorf("the %s word can't be %t", word, value)
+		}
+	}
+
+	boolMu.Lock()
+	defer boolMu.Unlock()
+	for word, value := range values {
+		boolStrings[strings.ToLower(strings.TrimSpace(word))] = value
+	}
+
+	return nil
+}
+
+// The parseBool converts the string to bool by strconv.ParseBool
+// or by the registered synonyms, without the float fallback.
+func parseBool(value string) (bool, error) {
+	r, err := strconv.ParseBool(value)
+	if err == nil {
+		return r, nil
+	}
+
+	boolMu.RLock()
+	r, ok := boolStrings[strings.ToLower(strings.TrimSpace(value))]
+	boolMu.RUnlock()
+	if !ok {
+		return false, err
+	}
+
+	return r, nil
+}
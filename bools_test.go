@@ -0,0 +1,96 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestStrictBool tests parsing of the boolean values in both modes.
+func TestStrictBool(t *testing.T) {
+	defer StrictBool(false)
+
+	tests := []struct {
+		value  string
+		want   bool
+		strict bool // true if accepted in the strict mode
+	}{
+		{"true", true, true},
+		{"F", false, true},
+		{"1", true, true},
+		{"0.8", true, false},
+		{"-5", true, false},
+		{"0.5", false, false},
+	}
+
+	for _, test := range tests {
+		for _, strict := range []bool{false, true} {
+			StrictBool(strict)
+			r, err := strToBool(test.value)
+			if strict && !test.strict {
+				if err == nil {
+					t.Errorf("%s: expected an error in strict mode",
+						test.value)
+				}
+				continue
+			}
+
+			if err != nil || r != test.want {
+				t.Errorf("%s: expected `%t` but `%t, %v`",
+					test.value, test.want, r, err)
+			}
+		}
+	}
+
+	if _, err := strToBool("yes"); err == nil {
+		t.Error("expected an error for unknown value")
+	}
+}
+
+// TestRegisterBoolStrings tests the registered boolean synonyms.
+func TestRegisterBoolStrings(t *testing.T) {
+	defer func() {
+		boolMu.Lock()
+		boolStrings = map[string]bool{}
+		boolMu.Unlock()
+	}()
+
+	err := RegisterBoolStrings(map[string]bool{
+		"yes": true, "On": true, "enabled": true,
+		"no": false, "off": false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for value, want := range map[string]bool{
+		"YES": true, "on": true, "Enabled": true, "no": false, "OFF": false,
+	} {
+		if r, err := strToBool(value); err != nil || r != want {
+			t.Errorf("%s: expected `%t` but `%t, %v`", value, want, r, err)
+		}
+	}
+
+	type config struct {
+		Debug bool `env:"DEBUG" def:"on" required:"yes" secret:"no"`
+	}
+
+	problems, err := ValidateStructTags(&config{})
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems but `%v, %v`", problems, err)
+	}
+
+	tg := newTagGroup(reflect.TypeOf(config{}).Field(0))
+	if !tg.required || tg.secret {
+		t.Errorf("expected required and not secret but %+v", tg)
+	}
+
+	for _, values := range []map[string]bool{
+		{"": true},
+		{"true": false},
+		{"0": true},
+	} {
+		if err := RegisterBoolStrings(values); err == nil {
+			t.Errorf("expected an error for `%v`", values)
+		}
+	}
+}
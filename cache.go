@@ -0,0 +1,124 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The cacheEntry is the cached result of the lookup.
+type cacheEntry struct {
+	value      string    // value of the key
+	ok         bool      // true if the key is found
+	expires    time.Time // time when the entry becomes stale
+	refreshing bool      // true if the entry is refreshing now
+}
+
+// The minRefreshTimeout is the minimum timeout
+// of the background refresh of the cached value.
+const minRefreshTimeout = time.Second
+
+// The cachedSource is the Source that memoizes the lookups.
+type cachedSource struct {
+	src     Source
+	ttl     time.Duration
+	timeout time.Duration // timeout of the background refresh
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// Cached returns the source that memoizes the lookups of the src for the
+// ttl duration. The first lookup of the key is made synchronously, after
+// the ttl expires the cached value is still returned while the new value
+// is requested in the background, so the lookups stay fast and the source
+// is resilient to brief outages: if the refresh fails, the stale value is
+// kept and the refresh is repeated on the next lookup.
+//
+// The background refresh is canceled after the ttl (but not earlier
+// than in a second), so the hung source doesn't block the next
+// refreshes of the key.
+//
+// # Examples
+//
+//	src := env.Cached(env.WithRetry(remote, policy), time.Minute)
+//	if err := env.UnmarshalSource(ctx, src, "", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func Cached(src Source, ttl time.Duration) Source {
+	timeout := ttl
+	if timeout < minRefreshTimeout {
+		timeout = minRefreshTimeout
+	}
+
+	return &cachedSource{
+		src:     src,
+		ttl:     ttl,
+		timeout: timeout,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Lookup returns the cached value of the key.
+func (s *cachedSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if ok {
+		value, found := entry.value, entry.ok
+		if time.Now().After(entry.expires) && !entry.refreshing {
+			entry.refreshing = true
+			go s.refresh(key)
+		}
+		s.mu.Unlock()
+
+		return value, found, nil
+	}
+	s.mu.Unlock()
+
+	value, found, err := s.src.Lookup(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+
+	s.mu.Lock()
+	s.entries[key] = &cacheEntry{
+		value:   value,
+		ok:      found,
+		expires: time.Now().Add(s.ttl),
+	}
+	s.mu.Unlock()
+
+	return value, found, nil
+}
+
+// Keys returns the keys of the source, nil if it doesn't list them
+// (see KeyLister). The keys aren't cached.
+func (s *cachedSource) Keys(ctx context.Context) ([]string, error) {
+	if lister, ok := s.src.(KeyLister); ok {
+		return lister.Keys(ctx)
+	}
+
+	return nil, nil
+}
+
+// The refresh requests the new value of the key in the background,
+// the request is canceled after the timeout of the source.
+func (s *cachedSource) refresh(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	value, found, err := s.src.Lookup(ctx, key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	entry.refreshing = false
+	if err != nil {
+		return // keep the stale value
+	}
+
+	entry.value, entry.ok = value, found
+	entry.expires = time.Now().Add(s.ttl)
+}
@@ -0,0 +1,128 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCached tests Cached function.
+func TestCached(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+		value = "a"
+		fail  bool
+	)
+
+	src := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if fail {
+			return "", false, errors.New("unavailable")
+		}
+		return value, true, nil
+	})
+
+	// The get returns the number of calls to the source.
+	get := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+
+	ctx := context.Background()
+	cached := Cached(src, 20*time.Millisecond)
+
+	// The value is memoized.
+	for i := 0; i < 3; i++ {
+		v, ok, err := cached.Lookup(ctx, "KEY")
+		if err != nil || !ok || v != "a" {
+			t.Fatalf("expected `a` but `%s` (%v)", v, err)
+		}
+	}
+
+	if n := get(); n != 1 {
+		t.Errorf("expected 1 call but %d", n)
+	}
+
+	// The stale value is returned while refreshing.
+	mu.Lock()
+	value = "b"
+	mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+
+	if v, _, _ := cached.Lookup(ctx, "KEY"); v != "a" {
+		t.Errorf("expected stale `a` but `%s`", v)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if v, _, _ := cached.Lookup(ctx, "KEY"); v == "b" {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("the value hasn't been refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The stale value is kept during the outage.
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		v, ok, err := cached.Lookup(ctx, "KEY")
+		if err != nil || !ok || v != "b" {
+			t.Errorf("expected `b` but `%s` (%v)", v, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The new key isn't available.
+	if _, _, err := cached.Lookup(ctx, "OTHER"); err == nil {
+		t.Error("an error is expected for unavailable source")
+	}
+}
+
+// TestCachedTimeout tests that the hung refresh is canceled.
+func TestCachedTimeout(t *testing.T) {
+	var calls atomic.Int32
+	src := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		if calls.Add(1) == 1 {
+			return "a", true, nil
+		}
+
+		<-ctx.Done() // the source hangs
+		return "", false, ctx.Err()
+	})
+
+	ctx := context.Background()
+	cached := Cached(src, 10*time.Millisecond)
+	cached.(*cachedSource).timeout = 10 * time.Millisecond
+
+	if v, _, _ := cached.Lookup(ctx, "KEY"); v != "a" {
+		t.Fatalf("expected `a` but `%s`", v)
+	}
+
+	// The refresh is repeated after the hung one is canceled.
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("the refresh isn't repeated")
+		}
+
+		if v, _, _ := cached.Lookup(ctx, "KEY"); v != "a" {
+			t.Fatalf("expected stale `a` but `%s`", v)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LoadCascade loads the env-files of the environment (like development,
+// test or production) in the conventional dotenv order, as Rails, Vite
+// and Next.js do. The files are taken from the current directory, from
+// the highest priority to the lowest:
+//
+//   - .env.<env>.local - local overrides of the environment;
+//   - .env.local - local overrides, skipped for the test environment
+//     to make the tests reproducible;
+//   - .env.<env> - settings of the environment;
+//   - .env - default settings.
+//
+// The missing files are skipped (it isn't an error if there are no files
+// at all), the files with <env> are skipped if the env is empty. The keys are loaded like Load does: the keys that are
+// already set in the environment aren't updated, so they have the
+// highest priority, and the values of the files with the higher
+// priority can be used in the expansion of the values of the files
+// with the lower priority.
+//
+// Returns an error if any existing env-file contains incorrect data
+// or can't be read.
+//
+// # Examples
+//
+//	if err := env.LoadCascade(os.Getenv("APP_ENV")); err != nil {
+//		log.Fatal(err)
+//	}
+func LoadCascade(env string) error {
+	return loadCascade("", env)
+}
+
+// The loadCascade works like LoadCascade,
+// but takes the env-files from the dir.
+func loadCascade(dir, env string) error {
+	var names []string
+	if env != "" {
+		names = append(names, ".env."+env+".local")
+	}
+
+	if env != "test" {
+		names = append(names, ".env.local")
+	}
+
+	if env != "" {
+		names = append(names, ".env."+env)
+	}
+	names = append(names, ".env")
+
+	filenames := make([]string, 0, len(names))
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		_, err := os.Stat(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		filenames = append(filenames, filename)
+	}
+
+	// There is nothing to load, like in the container
+	// that gets the configuration from the environment.
+	if len(filenames) == 0 {
+		return nil
+	}
+
+	return Load(filenames...)
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadCascade tests LoadCascade function.
+func TestLoadCascade(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":                  "A=env\nB=env\nC=env\nD=env\nE=env\n",
+		".env.production":       "A=production\nB=production\nC=production\n",
+		".env.local":            "A=local\nB=local\n",
+		".env.production.local": "A=production.local\n",
+		".env.test":             "C=test\n",
+	}
+	for name, data := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		env      string
+		expected map[string]string
+	}{
+		{"production", map[string]string{
+			"A": "production.local",
+			"B": "local",
+			"C": "production",
+			"D": "env",
+			"E": "existing",
+		}},
+		{"test", map[string]string{ // without .env.local
+			"A": "env",
+			"B": "env",
+			"C": "test",
+			"D": "env",
+		}},
+		{"", map[string]string{
+			"A": "local",
+			"B": "local",
+			"C": "env",
+		}},
+		{"staging", map[string]string{ // missing files
+			"A": "local",
+			"C": "env",
+		}},
+	}
+
+	for _, test := range tests {
+		os.Clearenv()
+		os.Setenv("E", "existing")
+		if err := loadCascade(dir, test.env); err != nil {
+			t.Fatal(err)
+		}
+
+		for key, value := range test.expected {
+			if v := Get(key); v != value {
+				t.Errorf("%s: expected `%s` but `%s`", test.env, value, v)
+			}
+		}
+	}
+
+	// Incorrect file.
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("A\n"), 0o600)
+	if err := loadCascade(dir, "production"); err == nil {
+		t.Error("an error is expected for the incorrect file")
+	}
+	// No files at all.
+	os.Clearenv()
+	if err := loadCascade(t.TempDir(), "production"); err != nil {
+		t.Errorf("unexpected error for the empty directory: %v", err)
+	}
+}
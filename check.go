@@ -0,0 +1,415 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The names of the checks of the env-file (compatible with dotenv-linter).
+const (
+	checkDuplicatedKey      = "DuplicatedKey"
+	checkExpiredKey         = "ExpiredKey"
+	checkLowercaseKey       = "LowercaseKey"
+	checkQuoteCharacter     = "QuoteCharacter"
+	checkTrailingWhitespace = "TrailingWhitespace"
+	checkUnorderedKey       = "UnorderedKey"
+)
+
+// LintWarning is the problem of the env-file found by the Check function.
+type LintWarning struct {
+	Line    int    // line number, starting from 1
+	Key     string // key name, empty if the problem isn't about the key
+	Check   string // name of the check, like LowercaseKey
+	Message string // description of the problem
+}
+
+// String returns the warning in the format of dotenv-linter.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%d %s: %s", w.Line, w.Check, w.Message)
+}
+
+// Check checks the env-file by the common rules of dotenv-linter:
+//
+//   - DuplicatedKey - the key is set more than once;
+//   - ExpiredKey - the key has expired by the expires comment, like
+//     # expires=2025-01-01 (see SetWithTTL), it can't be fixed;
+//   - LowercaseKey - the key contains lowercase characters;
+//   - QuoteCharacter - the value is quoted, but quotes aren't needed;
+//   - TrailingWhitespace - the line ends with whitespace;
+//   - UnorderedKey - the keys of the group (lines separated by empty
+//     lines or comments) aren't sorted alphabetically.
+//
+// If fix is true, the file is rewritten with the problems fixed: the
+// duplicated keys are commented out, the keys are converted to upper
+// case, the unnecessary quotes and trailing whitespaces are removed
+// and the groups of keys are sorted. The returned warnings describe
+// the file before the fix.
+//
+// # Examples
+//
+//	warnings, err := env.Check(".env", false)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, w := range warnings {
+//		fmt.Printf(".env:%s\n", w) // .env:2 LowercaseKey: ...
+//	}
+func Check(filename string, fix bool) ([]LintWarning, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(data)
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	warnings, fixed := lintLines(lines)
+	if !fix {
+		return warnings, nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	result := strings.Join(fixed, "\n")
+	if strings.HasSuffix(text, "\n") {
+		result += "\n"
+	}
+
+	if result == text {
+		return warnings, nil
+	}
+
+	return warnings, os.WriteFile(filename, []byte(result), info.Mode())
+}
+
+// The lintLines checks the lines of the env-file and returns
+// the warnings and the fixed lines.
+func lintLines(lines []string) ([]LintWarning, []string) {
+	var warnings []LintWarning
+
+	warn := func(line int, key, check, format string, a ...interface{}) {
+		warnings = append(warnings, LintWarning{
+			Line:    line + 1,
+			Key:     key,
+			Check:   check,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	// The number of lines of the expressions, the continuation lines
+	// (see joinContinued) have zero lines, so they are kept with their
+	// expressions when the keys are sorted. The lines are checked
+	// without trailing whitespace, as they will be fixed.
+	spans := make([]int, len(lines))
+	for i := 0; i < len(lines); i++ {
+		spans[i] = 1
+		line := strings.TrimRight(lines[i], " \t\r")
+		if !isUnquotedExpression(line) {
+			continue
+		}
+
+		start := i
+		for isContinued(line) && i+1 < len(lines) &&
+			isContinuation(strings.TrimRight(lines[i+1], " \t\r")) {
+			i++
+			line = strings.TrimRight(lines[i], " \t\r")
+			spans[i] = 0
+		}
+		spans[start] = i - start + 1
+	}
+
+	fixed := make([]string, len(lines))
+	keys := make([]string, len(lines)) // keys of the expressions
+	seen := make(map[string]bool)
+	commented := 0 // the lines before it are commented out
+	for i, line := range lines {
+		fixed[i] = line
+
+		if trimmed := strings.TrimRight(line, " \t\r"); trimmed != line {
+			warn(i, "", checkTrailingWhitespace,
+				"Trailing whitespace detected")
+			fixed[i], line = trimmed, trimmed
+		}
+
+		if spans[i] == 0 {
+			if i < commented {
+				fixed[i] = "# " + fixed[i]
+			}
+			continue
+		}
+
+		if isEmpty(line) {
+			continue
+		}
+
+		key, value, err := parseExpression(line)
+		if err != nil {
+			continue // incorrect line isn't linted
+		}
+
+		if upper := strings.ToUpper(key); upper != key {
+			warn(i, key, checkLowercaseKey,
+				"The %s key should be in uppercase", key)
+			eq := strings.IndexRune(fixed[i], '=')
+			pos := strings.LastIndex(fixed[i][:eq], key)
+			fixed[i] = fixed[i][:pos] + upper + fixed[i][pos+len(key):]
+			key = upper
+		}
+
+		if t, ok := parseExpiry(line); ok && !now().Before(t) {
+			warn(i, key, checkExpiredKey, "The %s key expired on %s",
+				key, t.Format(time.DateOnly))
+		}
+
+		if seen[key] {
+			warn(i, key, checkDuplicatedKey,
+				"The %s key is duplicated", key)
+			fixed[i] = "# " + fixed[i]
+			commented = i + spans[i]
+			continue
+		}
+		seen[key] = true
+		keys[i] = key
+
+		if unquoted, ok := removeQuotes(fixed[i], value); ok {
+			warn(i, key, checkQuoteCharacter,
+				"The value has quote characters (', \", `)")
+			fixed[i] = unquoted
+		}
+	}
+
+	// Check the order of the keys in the groups
+	// of consecutive expressions.
+	for start := 0; start < len(lines); {
+		if keys[start] == "" {
+			start++
+			continue
+		}
+
+		// The first lines of the expressions of the group.
+		var group []int
+		end := start
+		for end < len(lines) && keys[end] != "" {
+			group = append(group, end)
+			end += spans[end]
+		}
+
+		for i := 1; i < len(group); i++ {
+			prev, key := keys[group[i-1]], keys[group[i]]
+			if key < prev {
+				warn(group[i], key, checkUnorderedKey,
+					"The %s key should go before the %s key", key, prev)
+			}
+		}
+
+		sort.SliceStable(group, func(a, b int) bool {
+			return keys[group[a]] < keys[group[b]]
+		})
+
+		sorted := make([]string, 0, end-start)
+		for _, index := range group {
+			sorted = append(sorted, fixed[index:index+spans[index]]...)
+		}
+		copy(fixed[start:end], sorted)
+
+		start = end
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Line < warnings[j].Line
+	})
+
+	return warnings, fixed
+}
+
+// The removeQuotes returns the line without quotes around the value if
+// they aren't needed: the value isn't empty and has no spaces, hashes
+// and quotes. The boolean is false if the quotes are needed.
+func removeQuotes(line, value string) (string, bool) {
+	pos := strings.IndexRune(line, '=')
+	raw := strings.TrimSpace(line[pos+1:])
+	if raw == "" || !strings.ContainsRune("'\"`", rune(raw[0])) ||
+		value == "" || strings.ContainsAny(value, " \t#'\"`") {
+		return line, false
+	}
+
+	// Keep the inline comment after the closing quote.
+	end := strings.IndexRune(raw[1:], rune(raw[0])) + 1
+	if end <= 0 {
+		return line, false
+	}
+
+	result := line[:pos+1] + value + raw[end+1:]
+
+	// Make sure the value hasn't changed.
+	if _, v, err := parseExpression(result); err != nil || v != value {
+		return line, false
+	}
+
+	return result, true
+}
+
+// Occurrence is the key in the env-file found by the CrossCheck function.
+type Occurrence struct {
+	File  string // path to the env-file
+	Line  int    // line number, starting from 1
+	Value string // value as it's written, without inline comment
+}
+
+// Conflict is the key that is set differently in several env-files.
+type Conflict struct {
+	Key string // key name
+
+	// The Cosmetic is true if the values differ by the whitespaces
+	// around the value or by the quoting only, like A=1 and A="1".
+	Cosmetic bool
+
+	// The Occurrences are the last occurrences of the key
+	// in the files, in the order of the files.
+	Occurrences []Occurrence
+}
+
+// String returns the conflict as a string, like
+// PORT: .env:2=8080, .env.production:5=80.
+func (c Conflict) String() string {
+	items := make([]string, len(c.Occurrences))
+	for i, o := range c.Occurrences {
+		items[i] = fmt.Sprintf("%s:%d=%s", o.File, o.Line, o.Value)
+	}
+
+	kind := ""
+	if c.Cosmetic {
+		kind = " (whitespace or quoting)"
+	}
+
+	return fmt.Sprintf("%s%s: %s", c.Key, kind, strings.Join(items, ", "))
+}
+
+// CrossCheck finds the keys that are set in several env-files with
+// different values, to catch the drift between the files like .env,
+// .env.production and the env-files of docker-compose. The values are
+// compared after parsing (so A=1 and A='1' # comment are the same), the
+// conflicts of the values that differ by the whitespaces or quoting only
+// are reported as cosmetic. The last occurrence of the key in the file
+// is used, as Update does.
+//
+// The conflicts are sorted by the keys. Returns an error if any file
+// can't be read or contains the incorrect expression.
+//
+// # Examples
+//
+//	conflicts, err := env.CrossCheck(".env", ".env.production")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, c := range conflicts {
+//		fmt.Println(c) // PORT: .env:2=8080, .env.production:5=80
+//	}
+func CrossCheck(files ...string) ([]Conflict, error) {
+	type entry struct {
+		Occurrence
+		value string // parsed value
+	}
+
+	entries := make(map[string][]entry)
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		last := make(map[string]entry)
+		text := strings.ReplaceAll(string(data), "\r\n", "\n")
+		lines := joinContinued(strings.Split(text, "\n"))
+		for i, line := range lines {
+			if isEmpty(line) {
+				continue
+			}
+
+			key, value, err := parseExpression(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", filename, i+1, err)
+			}
+
+			last[key] = entry{
+				Occurrence: Occurrence{
+					File:  filename,
+					Line:  i + 1,
+					Value: writtenValue(line),
+				},
+				value: value,
+			}
+		}
+
+		for key, e := range last {
+			entries[key] = append(entries[key], e)
+		}
+	}
+
+	var conflicts []Conflict
+	for key, list := range entries {
+		var (
+			differ, cosmetic = false, false
+			first            = list[0]
+		)
+
+		for _, e := range list[1:] {
+			switch {
+			case e.value != first.value &&
+				strings.TrimSpace(e.value) != strings.TrimSpace(first.value):
+				differ = true
+			case e.value != first.value || e.Value != first.Value:
+				cosmetic = true
+			}
+		}
+
+		if !differ && !cosmetic {
+			continue
+		}
+
+		c := Conflict{Key: key, Cosmetic: !differ}
+		for _, e := range list {
+			c.Occurrences = append(c.Occurrences, e.Occurrence)
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+
+	return conflicts, nil
+}
+
+// The writtenValue returns the value of the expression as it's written:
+// with the quotes, but without the inline comment.
+func writtenValue(line string) string {
+	raw := strings.TrimSpace(line[strings.IndexRune(line, '=')+1:])
+	if raw == "" {
+		return raw
+	}
+
+	if strings.ContainsRune("'\"`", rune(raw[0])) {
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '\\' {
+				i++
+			} else if raw[i] == raw[0] {
+				return raw[:i+1]
+			}
+		}
+		return raw
+	}
+
+	if pos := strings.IndexRune(raw, '#'); pos != -1 {
+		raw = strings.TrimSpace(raw[:pos])
+	}
+
+	return raw
+}
@@ -0,0 +1,207 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheck tests Check function.
+func TestCheck(t *testing.T) {
+	const text = "# Server.\n" +
+		"PORT=8080\n" +
+		"HOST=\"localhost\" # comment \n" +
+		"export debug=true\n" +
+		"\n" +
+		"EMPTY=''\n" +
+		"NAME='my app'\n" +
+		"PORT=80\n" +
+		"API_KEY=`key`\n"
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings, err := Check(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"3 TrailingWhitespace: Trailing whitespace detected",
+		"3 QuoteCharacter: The value has quote characters (', \", `)",
+		"3 UnorderedKey: The HOST key should go before the PORT key",
+		"4 LowercaseKey: The debug key should be in uppercase",
+		"4 UnorderedKey: The DEBUG key should go before the HOST key",
+		"8 DuplicatedKey: The PORT key is duplicated",
+		"9 QuoteCharacter: The value has quote characters (', \", `)",
+	}
+
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings but %d: %v",
+			len(expected), len(warnings), warnings)
+	}
+
+	for i, w := range warnings {
+		if w.String() != expected[i] {
+			t.Errorf("expected `%s` but `%s`", expected[i], w)
+		}
+	}
+
+	// Fix mode.
+	if _, err := Check(filename, true); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixed := "# Server.\n" +
+		"export DEBUG=true\n" +
+		"HOST=localhost # comment\n" +
+		"PORT=8080\n" +
+		"\n" +
+		"EMPTY=''\n" +
+		"NAME='my app'\n" +
+		"# PORT=80\n" +
+		"API_KEY=key\n"
+
+	if string(data) != fixed {
+		t.Errorf("expected:\n%s\nbut:\n%s", fixed, data)
+	}
+
+	// The fixed file has no warnings.
+	if warnings, err = Check(filename, false); err != nil ||
+		len(warnings) != 0 {
+		t.Errorf("expected no warnings but %v (%v)", warnings, err)
+	}
+}
+
+// TestCheckContinuation tests Check function with the continued values.
+func TestCheckContinuation(t *testing.T) {
+	const text = "B=x\n" +
+		"A=one \\\n" +
+		"  two\n" +
+		"a=y \\\n" +
+		"  z\n" +
+		"D=1\n" +
+		"C=three \\\n" +
+		"  four\n"
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Check(filename, true); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixed := "A=one \\\n" +
+		"  two\n" +
+		"B=x\n" +
+		"# A=y \\\n" +
+		"#   z\n" +
+		"C=three \\\n" +
+		"  four\n" +
+		"D=1\n"
+
+	if string(data) != fixed {
+		t.Errorf("expected:\n%s\nbut:\n%s", fixed, data)
+	}
+
+	pairs, err := Parse(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pairs) != 4 || pairs["A"] != "one two" ||
+		pairs["C"] != "three four" {
+		t.Errorf("unexpected values: %v", pairs)
+	}
+}
+
+// TestCrossCheck tests CrossCheck function.
+func TestCrossCheck(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env": "HOST=localhost\n" +
+			"PORT=8080\n" +
+			"NAME=app # comment\n" +
+			"TOKEN=abc\n" +
+			"DEBUG=true\n",
+		".env.production": "HOST=localhost\n" +
+			"PORT=80\n" +
+			"NAME=\"app\"\n" +
+			"TOKEN=\"abc \"\n" +
+			"LEVEL=info\n",
+		"compose.env": "PORT=8080\n" +
+			"PORT=80\n" + // the last occurrence is used
+			"DEBUG=false\n",
+	}
+
+	var paths []string
+	for _, name := range []string{".env", ".env.production",
+		"compose.env"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]),
+			0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	conflicts, err := CrossCheck(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		key      string
+		cosmetic bool
+		values   []string
+	}{
+		{"DEBUG", false, []string{"true", "false"}},
+		{"NAME", true, []string{"app", `"app"`}},
+		{"PORT", false, []string{"8080", "80", "80"}},
+		{"TOKEN", true, []string{"abc", `"abc "`}},
+	}
+
+	if len(conflicts) != len(expected) {
+		t.Fatalf("expected %d conflicts but %v", len(expected), conflicts)
+	}
+
+	for i, c := range conflicts {
+		e := expected[i]
+		if c.Key != e.key || c.Cosmetic != e.cosmetic ||
+			len(c.Occurrences) != len(e.values) {
+			t.Errorf("expected `%v` but `%v`", e, c)
+			continue
+		}
+
+		for j, o := range c.Occurrences {
+			if o.Value != e.values[j] {
+				t.Errorf("expected `%s` but `%s`", e.values[j], o.Value)
+			}
+		}
+	}
+
+	if c := conflicts[2].Occurrences[2]; c.Line != 2 ||
+		c.File != paths[2] {
+		t.Errorf("expected the last occurrence but %v", c)
+	}
+
+	// Incorrect file.
+	os.WriteFile(paths[0], []byte("INVALID\n"), 0o600)
+	if _, err := CrossCheck(paths...); err == nil {
+		t.Error("expected an error for incorrect expression")
+	}
+}
@@ -0,0 +1,31 @@
+// Command envtags statically checks the tags of the structures configured
+// by the github.com/goloop/env package, like env, def and sep, for the
+// same problems that are found by the env.ValidateStructTags function at
+// runtime, so they can be found in CI without running the program. See
+// the envtags package in the passes directory for the list of checks.
+//
+// The command is the separate module, so the env package itself has no
+// dependencies of the analysis framework. The Analyzer of the passes
+// directory can be used with the multichecker of other linters.
+//
+// Usage:
+//
+//	envtags [-flag] [package ...]
+//
+// Or as the tool of go vet:
+//
+//	go install github.com/goloop/env/cmd/envtags@latest
+//	go vet -vettool=$(which envtags) ./...
+//
+// The problems are printed in the go vet format, the exit code is non-zero
+// if any problem is found.
+package main
+
+import (
+	"github.com/goloop/env/cmd/envtags/passes/envtags"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(envtags.Analyzer)
+}
@@ -0,0 +1,316 @@
+// Package envtags defines the Analyzer that checks the tags of the
+// structures configured by the github.com/goloop/env package, like env,
+// def and sep, for the same problems that are found by the
+// env.ValidateStructTags function at runtime:
+//
+//   - invalid key names;
+//   - duplicate keys (after adding prefixes of nested structures);
+//   - def values that can't be parsed into the field type;
+//   - sep, kvsep, decimal, path and delims tags on fields of the
+//     wrong types or with invalid values;
+//   - required and secret tags with non-boolean values.
+//
+// The types of the fields are taken from the type checker, so the nested
+// structures and the named types declared in other packages are resolved
+// too. The structure is rebuilt by the reflect package and checked by the
+// env.ValidateStructTags itself, so the rules and the tag names are the
+// same as at runtime. The fields of the types that are decoded as the
+// single value by their own methods (like UnmarshalText) or by the
+// decoders registered at runtime can't be checked statically, they are
+// checked by their underlying types if they have no such methods.
+//
+// Only the structures that have at least one field with the tags of the
+// env package (see env.TagNames) are checked, the problems are reported
+// at the top-level fields of the checked structure.
+package envtags
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	htmltemplate "html/template"
+	"net/url"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/goloop/env"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer checks the tags of the structures of the env package.
+//
+// # Examples
+//
+//	func main() {
+//		multichecker.Main(envtags.Analyzer, ...)
+//	}
+var Analyzer = &analysis.Analyzer{
+	Name:     "envtags",
+	Doc:      "check the env, def, sep and other tags of the structures",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const (
+	// The envPath is the import path of the env package.
+	envPath = "github.com/goloop/env"
+
+	// The pkgPath is the package path of the unexported fields
+	// of the rebuilt structures.
+	pkgPath = "envtags"
+)
+
+// The knownTypes are the named types that are decoded
+// by the env package in a special way, by the full name.
+var knownTypes = map[string]reflect.Type{
+	"time.Duration":           reflect.TypeOf(time.Duration(0)),
+	"time.Time":               reflect.TypeOf(time.Time{}),
+	"net/url.URL":             reflect.TypeOf(url.URL{}),
+	"text/template.Template":  reflect.TypeOf(texttemplate.Template{}),
+	"html/template.Template":  reflect.TypeOf(htmltemplate.Template{}),
+	envPath + ".SecretString": reflect.TypeOf(env.SecretString{}),
+	envPath + ".Range":        reflect.TypeOf(env.Range{}),
+	envPath + ".HostPort":     reflect.TypeOf(env.HostPort{}),
+	envPath + ".Port":         reflect.TypeOf(env.Port(0)),
+	envPath + ".CronSpec":     reflect.TypeOf(env.CronSpec("")),
+	envPath + ".Version":      reflect.TypeOf(env.Version{}),
+	envPath + ".Weighted":     reflect.TypeOf(env.Weighted{}),
+	envPath + ".WeightedList": reflect.TypeOf(env.WeightedList{}),
+}
+
+// The basicTypes are the reflect types of the basic types.
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:       reflect.TypeOf(false),
+	types.Int:        reflect.TypeOf(int(0)),
+	types.Int8:       reflect.TypeOf(int8(0)),
+	types.Int16:      reflect.TypeOf(int16(0)),
+	types.Int32:      reflect.TypeOf(int32(0)),
+	types.Int64:      reflect.TypeOf(int64(0)),
+	types.Uint:       reflect.TypeOf(uint(0)),
+	types.Uint8:      reflect.TypeOf(uint8(0)),
+	types.Uint16:     reflect.TypeOf(uint16(0)),
+	types.Uint32:     reflect.TypeOf(uint32(0)),
+	types.Uint64:     reflect.TypeOf(uint64(0)),
+	types.Uintptr:    reflect.TypeOf(uintptr(0)),
+	types.Float32:    reflect.TypeOf(float32(0)),
+	types.Float64:    reflect.TypeOf(float64(0)),
+	types.Complex64:  reflect.TypeOf(complex64(0)),
+	types.Complex128: reflect.TypeOf(complex128(0)),
+	types.String:     reflect.TypeOf(""),
+}
+
+// The opaque is the type of the fields that can't be checked
+// statically, it's decoded as the single value and accepts any value.
+type opaque struct{}
+
+// UnmarshalText accepts any value.
+func (*opaque) UnmarshalText([]byte) error { return nil }
+
+// The opaqueType is the reflect type of the opaque.
+var opaqueType = reflect.TypeOf(opaque{})
+
+// The run checks the structures declared in the package.
+func run(pass *analysis.Pass) (interface{}, error) {
+	tags := env.TagNames()
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.TypeSpec)(nil)}, func(n ast.Node) {
+		ts := n.(*ast.TypeSpec)
+		if ts.TypeParams != nil {
+			return // the types of the fields aren't known
+		}
+
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return
+		}
+
+		obj, ok := pass.TypesInfo.Defs[ts.Name].(*types.TypeName)
+		if !ok {
+			return
+		}
+
+		named, ok := obj.Type().(*types.Named)
+		if !ok || !hasTags(named.Underlying().(*types.Struct), tags) {
+			return
+		}
+
+		rt, ok := convert(named)
+		if !ok {
+			return
+		}
+
+		problems, err := env.ValidateStructTags(reflect.New(rt).Interface())
+		if err != nil {
+			return
+		}
+
+		for _, p := range problems {
+			pass.Report(analysis.Diagnostic{
+				Pos:     fieldPos(st, p.Field),
+				Message: p.String(),
+			})
+		}
+	})
+
+	return nil, nil
+}
+
+// The hasTags returns true if any field of the structure
+// has at least one of the tags.
+func hasTags(st *types.Struct, tags []string) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		tag := reflect.StructTag(st.Tag(i))
+		for _, name := range tags {
+			if _, ok := tag.Lookup(name); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// The fieldPos returns the position of the top-level field
+// of the Go-style path to the field, like DB.Host.
+func fieldPos(st *ast.StructType, path string) token.Pos {
+	name, _, _ := strings.Cut(path, ".")
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 && embeddedName(field.Type) == name {
+			return field.Pos()
+		}
+
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return ident.Pos()
+			}
+		}
+	}
+
+	return st.Pos()
+}
+
+// The embeddedName returns the name of the embedded field by its type.
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+
+	return ""
+}
+
+// The convert returns the reflect type of the structure type t.
+// Returns false if the type can't be rebuilt by the reflect package.
+func convert(t *types.Named) (rt reflect.Type, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			rt, ok = nil, false // like the invalid field names
+		}
+	}()
+
+	c := converter{stack: map[*types.Named]bool{t: true}}
+	return c.structOf(t.Underlying().(*types.Struct)), true
+}
+
+// The converter rebuilds the types of the type checker
+// by the reflect package.
+type converter struct {
+	stack map[*types.Named]bool // named types being converted
+}
+
+// The typeOf returns the reflect type of the type t.
+func (c *converter) typeOf(t types.Type) reflect.Type {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		return c.namedOf(t)
+	case *types.Basic:
+		if rt, ok := basicTypes[t.Kind()]; ok {
+			return rt
+		}
+	case *types.Pointer:
+		return reflect.PointerTo(c.typeOf(t.Elem()))
+	case *types.Slice:
+		return reflect.SliceOf(c.typeOf(t.Elem()))
+	case *types.Array:
+		return reflect.ArrayOf(int(t.Len()), c.typeOf(t.Elem()))
+	case *types.Map:
+		return reflect.MapOf(c.typeOf(t.Key()), c.typeOf(t.Elem()))
+	case *types.Struct:
+		return c.structOf(t)
+	}
+
+	return opaqueType // like channels, functions and interfaces
+}
+
+// The namedOf returns the reflect type of the named type t.
+func (c *converter) namedOf(t *types.Named) reflect.Type {
+	obj := t.Obj()
+	if obj.Pkg() != nil {
+		name := obj.Pkg().Path() + "." + obj.Name()
+		if rt, ok := knownTypes[name]; ok {
+			return rt
+		}
+
+		if obj.Pkg().Path() == envPath {
+			return opaqueType // the type decoded by the env package
+		}
+	}
+
+	// The recursive types and the types decoded by their own methods.
+	if c.stack[t] || isTextUnmarshaler(t) {
+		switch t.Underlying().(type) {
+		case *types.Slice, *types.Array:
+			return reflect.SliceOf(opaqueType)
+		}
+		return opaqueType
+	}
+
+	c.stack[t] = true
+	defer delete(c.stack, t)
+
+	return c.typeOf(t.Underlying())
+}
+
+// The structOf returns the reflect type of the structure type t.
+func (c *converter) structOf(t *types.Struct) reflect.Type {
+	fields := make([]reflect.StructField, 0, t.NumFields())
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		field := reflect.StructField{
+			Name: f.Name(),
+			Type: c.typeOf(f.Type()),
+			Tag:  reflect.StructTag(t.Tag(i)),
+		}
+
+		// The embedded fields are rebuilt as the named ones with
+		// the type name, the env package uses it as the key name.
+		if !f.Exported() {
+			field.PkgPath = pkgPath
+		}
+
+		fields = append(fields, field)
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// The isTextUnmarshaler returns true if the type t or the pointer
+// to it has the UnmarshalText method.
+func isTextUnmarshaler(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true,
+		nil, "UnmarshalText")
+	_, ok := obj.(*types.Func)
+	return ok
+}
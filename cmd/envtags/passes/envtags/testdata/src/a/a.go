@@ -0,0 +1,46 @@
+package a
+
+import (
+	"net"
+	"net/url"
+	"time"
+)
+
+type DB struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT" def:"port"` // want `Port \(PORT\): def tag: strconv.ParseInt`
+}
+
+type Duration = time.Duration
+
+type Level int
+
+func (l *Level) UnmarshalText(data []byte) error { return nil }
+
+type Config struct {
+	Host    string        `env:"HOST" def:"localhost"`
+	Port    uint8         `env:"PORT" def:"300"` // want `Port \(PORT\): def tag: 300 is out of range for uint8`
+	Hosts   []string      `env:"HOSTS" sep:","`
+	Name    string        `env:"NAME" sep:","`         // want `Name \(NAME\): sep tag: field is not an array, slice or map`
+	Address string        `env:"HOST"`                 // want `Address \(HOST\): env tag: duplicate key, also used by Host`
+	Wrong   string        `env:"1_KEY"`                // want `Wrong \(1_KEY\): env tag: invalid key name`
+	Debug   bool          `env:"DEBUG" required:"yes"` // want `Debug \(DEBUG\): required tag: invalid boolean value: yes`
+	Codes   [2]int        `env:"CODES" def:"1 2 3"`    // want `Codes \(CODES\): def tag: .*`
+	URL     url.URL       `env:"URL" def:"http://a.com"`
+	DB      *DB           `env:"DB"`      // want `DB.Port \(DB_PORT\): def tag: strconv.ParseInt`
+	DBHost  string        `env:"DB_HOST"` // want `DBHost \(DB_HOST\): env tag: duplicate key, also used by DB.Host`
+	Self    *Config       `env:"SELF"`
+	Timeout time.Duration `env:"TIMEOUT" def:"soon"` // want `Timeout \(TIMEOUT\): def tag: .*`
+	Since   time.Time     `env:"SINCE" def:"2025-01-01T00:00:00Z"`
+	IP      net.IP        `env:"IP" def:"anything"`
+	Level   Level         `env:"LEVEL" def:"anything"`
+	Rate    float64       `env:"RATE" decimal:";"` // want `Rate \(RATE\): decimal tag: .*`
+	Ch      chan int      `env:"CH"`
+	Alias   Duration      `env:"ALIAS" def:"later"` // want `Alias \(ALIAS\): def tag: .*`
+	private int           `env:"PRIVATE" def:"x"`   // want `private \(PRIVATE\): def tag: .*`
+}
+
+type Plain struct {
+	Name string
+	Age  int `json:"age"`
+}
@@ -0,0 +1,111 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The funcNameRgx matches characters that
+// can't be used in the shell function names.
+var funcNameRgx = regexp.MustCompile(`\W`)
+
+// Completion writes the shell completion script for the command that
+// lists the environment variables of all registered configuration
+// objects (see Register) with their descriptions from desc tags.
+//
+// The shell can be "bash" or "zsh". The script completes the words of
+// the command as KEY= so the operator can discover the variables while
+// running the binary manually; zsh shows descriptions too. The fields
+// of the maps of structures (like map[string]DB) aren't completed, as
+// the names of their entries are unknown.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST" desc:"server host"`
+//		Port int    `env:"PORT" desc:"server port"`
+//	}
+//
+//	env.Register("APP_", &Config{})
+//	env.Completion(os.Stdout, "bash", "myapp")
+//
+//	// Load completion:
+//	//  $ source <(myapp completion bash)
+//	//  $ myapp <TAB>
+//	//  APP_HOST=  APP_PORT=
+func Completion(w io.Writer, shell, command string) error {
+	type variable struct{ key, desc string }
+
+	var vars []variable
+	registryMu.Lock()
+	for _, r := range registry {
+		err := walkFields(r.prefix, reflect.TypeOf(r.obj),
+			func(fi fieldInfo) error {
+				// The names of the map entries are unknown.
+				if !isStructMap(fi.field.Type) {
+					vars = append(vars, variable{fi.tg.key, fi.tg.desc})
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			registryMu.Unlock()
+			return err
+		}
+	}
+	registryMu.Unlock()
+
+	sort.SliceStable(vars, func(i, j int) bool {
+		return vars[i].key < vars[j].key
+	})
+
+	fn := "_" + funcNameRgx.ReplaceAllString(command, "_") + "_env"
+	switch shell {
+	case "bash":
+		words := make([]string, len(vars))
+		for i, v := range vars {
+			words[i] = v.key + "="
+		}
+
+		_, err := fmt.Fprintf(w, "# bash completion of environment "+
+			"variables for %[1]s\n"+
+			"%[2]s() {\n"+
+			"\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n"+
+			"\tCOMPREPLY=($(compgen -W %[3]s -- \"$cur\"))\n"+
+			"}\n"+
+			"complete -o nospace -F %[2]s %[1]s\n",
+			command, fn, shellQuote(strings.Join(words, " ")))
+		return err
+	case "zsh":
+		var sb strings.Builder
+		for _, v := range vars {
+			item := v.key
+			if v.desc != "" {
+				item += ":" + v.desc
+			}
+			fmt.Fprintf(&sb, "\t\t%s\n", shellQuote(item))
+		}
+
+		_, err := fmt.Fprintf(w, "#compdef %[1]s\n"+
+			"# zsh completion of environment variables for %[1]s\n"+
+			"%[2]s() {\n"+
+			"\tlocal -a vars\n"+
+			"\tvars=(\n%[3]s\t)\n"+
+			"\t_describe 'environment variable' vars -S '='\n"+
+			"}\n"+
+			"compdef %[2]s %[1]s\n",
+			command, fn, sb.String())
+		return err
+	}
+
+	return fmt.Errorf("unsupported shell: %s", shell)
+}
+
+// The shellQuote quotes the string by single quotes for the shell.
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
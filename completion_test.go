@@ -0,0 +1,66 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCompletion tests Completion function.
+func TestCompletion(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST" desc:"server's host"`
+		Port int    `env:"PORT"`
+		DBs  map[string]struct {
+			Host string `env:"HOST"`
+		} `env:"DB"`
+	}
+
+	registryMu.Lock()
+	saved := registry
+	registry = nil
+	registryMu.Unlock()
+	defer func() { registry = saved }()
+
+	if err := Register("APP_", &Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := Completion(&sb, "bash", "my-app"); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(sb.String(), "APP_DB") {
+		t.Errorf("the map of structures is completed:\n%s", sb.String())
+	}
+
+	for _, s := range []string{
+		"_my_app_env() {",
+		"compgen -W 'APP_HOST= APP_PORT='",
+		"complete -o nospace -F _my_app_env my-app",
+	} {
+		if !strings.Contains(sb.String(), s) {
+			t.Errorf("expected `%s` in:\n%s", s, sb.String())
+		}
+	}
+
+	sb.Reset()
+	if err := Completion(&sb, "zsh", "my-app"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		"#compdef my-app",
+		`'APP_HOST:server'\''s host'`,
+		"'APP_PORT'",
+		"compdef _my_app_env my-app",
+	} {
+		if !strings.Contains(sb.String(), s) {
+			t.Errorf("expected `%s` in:\n%s", s, sb.String())
+		}
+	}
+
+	if err := Completion(&sb, "fish", "my-app"); err == nil {
+		t.Error("an error is expected for unsupported shell")
+	}
+}
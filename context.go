@@ -0,0 +1,35 @@
+package env
+
+import "context"
+
+// The contextKey is the key for storing the configuration of type T
+// in the context. Each type T has its own key, so configurations of
+// different types don't overwrite each other.
+type contextKey[T any] struct{}
+
+// NewContext returns a copy of the parent context that carries the
+// configuration. The configuration can be retrieved with FromContext
+// using the same type.
+//
+// # Examples
+//
+//	var cfg Config
+//	if err := env.Unmarshal("", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	ctx := env.NewContext(context.Background(), &cfg)
+//	...
+//	if cfg, ok := env.FromContext[*Config](ctx); ok {
+//		fmt.Println(cfg.Host)
+//	}
+func NewContext[T any](ctx context.Context, cfg T) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, cfg)
+}
+
+// FromContext returns the configuration of type T stored in the context
+// by NewContext. The boolean is false if there is no such configuration.
+func FromContext[T any](ctx context.Context) (T, bool) {
+	cfg, ok := ctx.Value(contextKey[T]{}).(T)
+	return cfg, ok
+}
@@ -0,0 +1,39 @@
+package env
+
+import (
+	"context"
+	"testing"
+)
+
+// TestContext tests NewContext and FromContext functions.
+func TestContext(t *testing.T) {
+	type configA struct {
+		Host string `env:"HOST"`
+	}
+
+	type configB struct {
+		Host string `env:"HOST"`
+	}
+
+	ctx := context.Background()
+	if _, ok := FromContext[*configA](ctx); ok {
+		t.Error("the context shouldn't contain the configuration")
+	}
+
+	a, b := &configA{Host: "a"}, configB{Host: "b"}
+	ctx = NewContext(ctx, a)
+	ctx = NewContext(ctx, b)
+
+	if v, ok := FromContext[*configA](ctx); !ok || v != a {
+		t.Errorf("expected %v but %v", a, v)
+	}
+
+	if v, ok := FromContext[configB](ctx); !ok || v.Host != "b" {
+		t.Errorf("expected %v but %v", b, v)
+	}
+
+	// The value and the pointer are different types.
+	if _, ok := FromContext[configA](ctx); ok {
+		t.Error("the context shouldn't contain the configA value")
+	}
+}
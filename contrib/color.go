@@ -0,0 +1,58 @@
+package contrib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is the RGBA color parsed from the hex notation: #RGB, #RGBA,
+// #RRGGBB or #RRGGBBAA (the # is optional). The alpha is 255 if it
+// isn't set. The empty value is the zero (transparent black) color.
+type Color struct {
+	R, G, B, A uint8
+}
+
+// ParseColor parses the color from the hex notation.
+func ParseColor(value string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if hex == "" {
+		return Color{}, nil
+	}
+
+	// Expand the short notation, like #abc to #aabbcc.
+	if len(hex) == 3 || len(hex) == 4 {
+		var sb strings.Builder
+		for _, r := range hex {
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		}
+		hex = sb.String()
+	}
+
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return Color{}, fmt.Errorf("invalid color: %q", value)
+	}
+
+	return Color{
+		R: uint8(n >> 24),
+		G: uint8(n >> 16),
+		B: uint8(n >> 8),
+		A: uint8(n),
+	}, nil
+}
+
+// String returns the color as #rrggbb, or as #rrggbbaa
+// if the color isn't opaque.
+func (c Color) String() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
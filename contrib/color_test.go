@@ -0,0 +1,31 @@
+package contrib
+
+import "testing"
+
+// TestParseColor tests ParseColor function.
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Color
+		str   string
+		ok    bool
+	}{
+		{"#336699", Color{0x33, 0x66, 0x99, 0xff}, "#336699", true},
+		{"abc", Color{0xaa, 0xbb, 0xcc, 0xff}, "#aabbcc", true},
+		{"#abc8", Color{0xaa, 0xbb, 0xcc, 0x88}, "#aabbcc88", true},
+		{"#11223344", Color{0x11, 0x22, 0x33, 0x44}, "#11223344", true},
+		{"", Color{}, "#00000000", true},
+		{"#12345", Color{}, "", false},
+		{"#gggggg", Color{}, "", false},
+	}
+
+	for _, test := range tests {
+		c, err := ParseColor(test.value)
+		if ok := err == nil; ok != test.ok || c != test.want {
+			t.Errorf("%q: expected `%v, %t` but `%v, %v`",
+				test.value, test.want, test.ok, c, err)
+		} else if ok && c.String() != test.str {
+			t.Errorf("expected `%s` but `%s`", test.str, c)
+		}
+	}
+}
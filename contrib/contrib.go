@@ -0,0 +1,33 @@
+// Package contrib provides the ready types for the common shapes of the
+// configuration values: hex colors, log formats, lists of HTTP methods and
+// lists of glob patterns. The types are registered in the env package by
+// env.RegisterDecoder when this package is imported, so they can be used
+// as the types of the fields of the configuration structures.
+//
+// The package also demonstrates how to extend the env package with
+// the custom types.
+//
+// # Examples
+//
+//	type Config struct {
+//		Accent  contrib.Color       `env:"ACCENT" def:"#336699"`
+//		Format  contrib.LogFormat   `env:"LOG_FORMAT" def:"json"`
+//		Methods contrib.HTTPMethods `env:"CORS_METHODS" def:"GET,POST"`
+//		Ignore  contrib.Globs       `env:"IGNORE" def:"*.tmp,*.log"`
+//	}
+//
+//	var config Config
+//	if err := env.Unmarshal("", &config); err != nil {
+//		log.Fatal(err)
+//	}
+package contrib
+
+import "github.com/goloop/env"
+
+// Initializer.
+func init() {
+	env.RegisterDecoder(ParseColor)
+	env.RegisterDecoder(ParseLogFormat)
+	env.RegisterDecoder(ParseHTTPMethods)
+	env.RegisterDecoder(ParseGlobs)
+}
@@ -0,0 +1,51 @@
+package contrib
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goloop/env"
+)
+
+// TestUnmarshal tests the registered types in the configuration.
+func TestUnmarshal(t *testing.T) {
+	type config struct {
+		Accent  Color       `env:"ACCENT" def:"#336699"`
+		Format  LogFormat   `env:"LOG_FORMAT"`
+		Methods HTTPMethods `env:"CORS_METHODS" def:"GET,POST"`
+		Ignore  Globs       `env:"IGNORE"`
+	}
+
+	os.Clearenv()
+	env.Set("LOG_FORMAT", "JSON")
+	env.Set("IGNORE", "*.tmp, build/*")
+
+	var data config
+	if err := env.Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Accent != (Color{0x33, 0x66, 0x99, 0xff}) ||
+		data.Format != LogJSON || !data.Methods.Contains("post") ||
+		!data.Ignore.Match("build/app") {
+		t.Errorf("incorrect values: %+v", data)
+	}
+
+	items, err := env.Marshal("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"ACCENT=#336699", "LOG_FORMAT=json",
+		"CORS_METHODS=GET,POST", "IGNORE=*.tmp,build/*"}
+	for i, item := range expected {
+		if items[i] != item {
+			t.Errorf("expected `%s` but `%s`", item, items[i])
+		}
+	}
+
+	env.Set("CORS_METHODS", "GET,FETCH")
+	if err := env.Unmarshal("", &config{}); err == nil {
+		t.Error("expected an error for unknown method")
+	}
+}
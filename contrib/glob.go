@@ -0,0 +1,45 @@
+package contrib
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Globs is the list of the glob patterns (in the syntax of path.Match)
+// parsed from the comma-separated value, like *.tmp,build/*.
+type Globs []string
+
+// ParseGlobs parses the comma-separated list of the glob patterns.
+func ParseGlobs(value string) (Globs, error) {
+	var globs Globs
+	for _, item := range strings.Split(value, ",") {
+		pattern := strings.TrimSpace(item)
+		if pattern == "" {
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		globs = append(globs, pattern)
+	}
+
+	return globs, nil
+}
+
+// Match returns true if the name matches any of the patterns.
+func (g Globs) Match(name string) bool {
+	for _, pattern := range g {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the comma-separated list of the patterns.
+func (g Globs) String() string {
+	return strings.Join(g, ",")
+}
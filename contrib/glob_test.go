@@ -0,0 +1,27 @@
+package contrib
+
+import "testing"
+
+// TestParseGlobs tests ParseGlobs function.
+func TestParseGlobs(t *testing.T) {
+	globs, err := ParseGlobs("*.tmp, build/*,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := globs.String(); s != "*.tmp,build/*" {
+		t.Errorf("expected `*.tmp,build/*` but `%s`", s)
+	}
+
+	for name, want := range map[string]bool{
+		"a.tmp": true, "build/app": true, "a.go": false, "build/a/b": false,
+	} {
+		if globs.Match(name) != want {
+			t.Errorf("%s: expected %t", name, want)
+		}
+	}
+
+	if _, err := ParseGlobs("[a-"); err == nil {
+		t.Error("expected an error for invalid pattern")
+	}
+}
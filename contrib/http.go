@@ -0,0 +1,53 @@
+package contrib
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The httpMethods contains the standard HTTP methods.
+var httpMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodConnect: true, http.MethodOptions: true,
+	http.MethodTrace: true,
+}
+
+// HTTPMethods is the list of the standard HTTP methods parsed from the
+// comma-separated value, like GET,POST (the methods are case-insensitive).
+type HTTPMethods []string
+
+// ParseHTTPMethods parses the comma-separated list of the HTTP methods.
+func ParseHTTPMethods(value string) (HTTPMethods, error) {
+	var methods HTTPMethods
+	for _, item := range strings.Split(value, ",") {
+		method := strings.ToUpper(strings.TrimSpace(item))
+		if method == "" {
+			continue
+		}
+
+		if !httpMethods[method] {
+			return nil, fmt.Errorf("unknown HTTP method: %q", item)
+		}
+		methods = append(methods, method)
+	}
+
+	return methods, nil
+}
+
+// Contains returns true if the method is in the list.
+func (m HTTPMethods) Contains(method string) bool {
+	for _, item := range m {
+		if strings.EqualFold(item, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the comma-separated list of the methods.
+func (m HTTPMethods) String() string {
+	return strings.Join(m, ",")
+}
@@ -0,0 +1,23 @@
+package contrib
+
+import "testing"
+
+// TestParseHTTPMethods tests ParseHTTPMethods function.
+func TestParseHTTPMethods(t *testing.T) {
+	methods, err := ParseHTTPMethods("get, Post,,DELETE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := methods.String(); s != "GET,POST,DELETE" {
+		t.Errorf("expected `GET,POST,DELETE` but `%s`", s)
+	}
+
+	if !methods.Contains("delete") || methods.Contains("PUT") {
+		t.Errorf("incorrect Contains for %s", methods)
+	}
+
+	if _, err := ParseHTTPMethods("GET,GOT"); err == nil {
+		t.Error("expected an error for unknown method")
+	}
+}
@@ -0,0 +1,34 @@
+package contrib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogFormat is the format of the log records.
+type LogFormat string
+
+// The supported log formats.
+const (
+	LogText   LogFormat = "text"
+	LogJSON   LogFormat = "json"
+	LogLogfmt LogFormat = "logfmt"
+)
+
+// ParseLogFormat parses the log format: text, json or logfmt in any case.
+// The empty value is the text format.
+func ParseLogFormat(value string) (LogFormat, error) {
+	switch f := LogFormat(strings.ToLower(strings.TrimSpace(value))); f {
+	case "":
+		return LogText, nil
+	case LogText, LogJSON, LogLogfmt:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown log format: %q", value)
+}
+
+// String returns the name of the format.
+func (f LogFormat) String() string {
+	return string(f)
+}
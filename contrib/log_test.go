@@ -0,0 +1,20 @@
+package contrib
+
+import "testing"
+
+// TestParseLogFormat tests ParseLogFormat function.
+func TestParseLogFormat(t *testing.T) {
+	for value, want := range map[string]LogFormat{
+		"":        LogText,
+		"text":    LogText,
+		" JSON ":  LogJSON,
+		"logfmt":  LogLogfmt,
+		"xml":     "",
+		"json,tx": "",
+	} {
+		f, err := ParseLogFormat(value)
+		if f != want || (err == nil) != (want != "") {
+			t.Errorf("%q: expected `%s` but `%s, %v`", value, want, f, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ConvertFunc converts the value of the key and sets the result into
+// the item, the settable value of the field (or the item of the array
+// or slice field).
+type ConvertFunc func(item reflect.Value, value string) error
+
+var (
+	// The kindConverters contains the converters registered
+	// by the kinds (see RegisterKindConverter).
+	kindConverters sync.Map
+
+	// The typeConverters contains the converters registered
+	// by the types (see RegisterTypeConverter).
+	typeConverters sync.Map
+)
+
+// RegisterKindConverter overrides the built-in conversion of the values
+// of the basic kind (bool, numbers and string) for all types of this
+// kind, like the int fields that are parsed as hexadecimal numbers in
+// the legacy system. The nil function restores the built-in conversion.
+//
+// The converters of the kinds are used for the types without the special
+// conversion only: the types registered by RegisterDecoder or
+// RegisterTypeConverter and the types of the package (like Port)
+// are converted as before. The converter is called for the empty
+// value too.
+//
+// Returns an error if the kind isn't the basic kind.
+//
+// # Examples
+//
+//	err := env.RegisterKindConverter(reflect.Int,
+//		func(item reflect.Value, value string) error {
+//			n, err := strconv.ParseInt(value, 16, 64)
+//			if err != nil {
+//				return err
+//			}
+//			item.SetInt(n)
+//			return nil
+//		})
+func RegisterKindConverter(kind reflect.Kind, convert ConvertFunc) error {
+	switch kind {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8,
+		reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return fmt.Errorf("the %s kind can't be converted", kind)
+	}
+
+	if convert == nil {
+		kindConverters.Delete(kind)
+	} else {
+		kindConverters.Store(kind, convert)
+	}
+
+	return nil
+}
+
+// RegisterTypeConverter overrides the conversion of the values of
+// the type, like RegisterDecoder, but the type is known at runtime
+// only (for example, the type of the plugin or the generated code).
+// The type is marshaled like the types registered by RegisterDecoder.
+// The nil function removes the converter of the type.
+//
+// The decoder registered by RegisterDecoder for the same type takes
+// precedence over the converter.
+//
+// Returns an error if the type is nil.
+//
+// # Examples
+//
+//	t := reflect.TypeOf(legacy.Code(0))
+//	err := env.RegisterTypeConverter(t,
+//		func(item reflect.Value, value string) error {
+//			code, err := legacy.ParseCode(value)
+//			if err != nil {
+//				return err
+//			}
+//			item.Set(reflect.ValueOf(code))
+//			return nil
+//		})
+func RegisterTypeConverter(t reflect.Type, convert ConvertFunc) error {
+	if t == nil {
+		return errors.New("the type is nil")
+	}
+
+	if convert == nil {
+		typeConverters.Delete(t)
+	} else {
+		typeConverters.Store(t, convert)
+	}
+
+	return nil
+}
+
+// The hasConverter returns true if the converter of the type is
+// registered by RegisterTypeConverter.
+func hasConverter(t reflect.Type) bool {
+	_, ok := typeConverters.Load(t)
+	return ok
+}
+
+// The convertType converts the value by the converter registered for
+// the item type. The boolean is false if there is no such converter.
+func convertType(item reflect.Value, value string) (bool, error) {
+	convert, ok := typeConverters.Load(item.Type())
+	if !ok {
+		return false, nil
+	}
+
+	if err := convert.(ConvertFunc)(item, value); err != nil {
+		return true, &decoderError{err: err}
+	}
+
+	return true, nil
+}
+
+// The convertKind converts the value by the converter registered for
+// the item kind. The boolean is false if there is no such converter.
+func convertKind(item reflect.Value, value string) (bool, error) {
+	convert, ok := kindConverters.Load(item.Kind())
+	if !ok {
+		return false, nil
+	}
+
+	if err := convert.(ConvertFunc)(item, value); err != nil {
+		return true, &decoderError{err: err}
+	}
+
+	return true, nil
+}
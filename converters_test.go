@@ -0,0 +1,114 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPoint is the struct type with the registered converter.
+type testPoint struct {
+	X, Y int
+}
+
+// TestRegisterKindConverter tests the converters of the kinds.
+func TestRegisterKindConverter(t *testing.T) {
+	err := RegisterKindConverter(reflect.Int,
+		func(item reflect.Value, value string) error {
+			n, err := strconv.ParseInt(value, 16, 64)
+			if err != nil {
+				return err
+			}
+			item.SetInt(n)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RegisterKindConverter(reflect.Int, nil)
+
+	type config struct {
+		Code    int           `env:"CODE"`
+		Codes   []int         `env:"CODES" sep:","`
+		Code64  int64         `env:"CODE64"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	os.Clearenv()
+	Set("CODE", "ff")
+	Set("CODES", "a,10")
+	Set("CODE64", "10")
+	Set("TIMEOUT", "10")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{255, []int{10, 16}, 10, 10}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, data)
+	}
+
+	Set("CODE", "xyz")
+	if err := Unmarshal("", &data); err == nil ||
+		!strings.Contains(err.Error(), "CODE") {
+		t.Errorf("expected error with the key but `%v`", err)
+	}
+
+	// Restore the built-in conversion.
+	RegisterKindConverter(reflect.Int, nil)
+	Set("CODE", "10")
+	Set("CODES", "10")
+	data = config{}
+	if err := Unmarshal("", &data); err != nil || data.Code != 10 {
+		t.Errorf("expected `10` but `%d` (%v)", data.Code, err)
+	}
+
+	if err := RegisterKindConverter(reflect.Struct, nil); err == nil {
+		t.Error("expected an error for the struct kind")
+	}
+}
+
+// TestRegisterTypeConverter tests the converters of the types.
+func TestRegisterTypeConverter(t *testing.T) {
+	typ := reflect.TypeOf(testPoint{})
+	err := RegisterTypeConverter(typ,
+		func(item reflect.Value, value string) error {
+			var p testPoint
+			x, y, _ := strings.Cut(value, "x")
+			p.X, _ = strconv.Atoi(x)
+			p.Y, _ = strconv.Atoi(y)
+			item.Set(reflect.ValueOf(p))
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RegisterTypeConverter(typ, nil)
+
+	type config struct {
+		Size  testPoint   `env:"SIZE" def:"640x480"`
+		Sizes []testPoint `env:"SIZES" sep:","`
+	}
+
+	os.Clearenv()
+	Set("SIZES", "1x2,3x4")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{testPoint{640, 480}, []testPoint{{1, 2}, {3, 4}}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, data)
+	}
+
+	if err := RegisterTypeConverter(nil, nil); err == nil {
+		t.Error("expected an error for the nil type")
+	}
+}
@@ -0,0 +1,177 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSpec is the cron expression validated at unmarshal time, so the
+// typos in the schedules are found at the start of the program. The
+// value is the standard expression of 5 fields (minute, hour, day of
+// month, month, day of week), or of 6 fields with the seconds first,
+// or one of the descriptors: @yearly, @annually, @monthly, @weekly,
+// @daily, @midnight, @hourly or @every <duration>. The empty value
+// means no schedule.
+//
+// The fields can contain the numbers, the ranges (1-5), the steps (*/15,
+// 1-30/5), the lists (1,15,30), the * and ? wildcards and the names of
+// the months (JAN-DEC) and days of the week (SUN-SAT), the Sunday is 0
+// or 7.
+//
+// # Examples
+//
+//	type Config struct {
+//		Backup env.CronSpec `env:"BACKUP_SCHEDULE" def:"0 3 * * *"`
+//	}
+//
+//	var config Config
+//	if err := env.Unmarshal("", &config); err != nil {
+//		log.Fatal(err) // BACKUP_SCHEDULE: invalid cron expression ...
+//	}
+//
+//	scheduler.AddFunc(string(config.Backup), backup)
+type CronSpec string
+
+// The cronField describes the field of the cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of the values starting from min, if any
+}
+
+var (
+	// The cronFields are the standard fields of the cron expression.
+	cronFields = []cronField{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB",
+			"MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV",
+			"DEC"}},
+		{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON",
+			"TUE", "WED", "THU", "FRI", "SAT"}},
+	}
+
+	// The cronSeconds is the optional first field of the expression.
+	cronSeconds = cronField{name: "second", min: 0, max: 59}
+
+	// The cronDescriptors are the predefined schedules.
+	cronDescriptors = map[string]bool{
+		"@yearly": true, "@annually": true, "@monthly": true,
+		"@weekly": true, "@daily": true, "@midnight": true,
+		"@hourly": true,
+	}
+)
+
+// Initializer.
+func init() {
+	RegisterDecoder(ParseCronSpec)
+}
+
+// ParseCronSpec validates the cron expression (see CronSpec).
+func ParseCronSpec(value string) (CronSpec, error) {
+	spec := strings.TrimSpace(value)
+	if spec == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		if every, ok := strings.CutPrefix(spec, "@every "); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(every))
+			if err != nil || d <= 0 {
+				return "", fmt.Errorf("invalid cron expression %q: "+
+					"invalid duration", value)
+			}
+		} else if !cronDescriptors[spec] {
+			return "", fmt.Errorf("invalid cron expression %q: "+
+				"unknown descriptor", value)
+		}
+
+		return CronSpec(spec), nil
+	}
+
+	items := strings.Fields(spec)
+	fields := cronFields
+	switch len(items) {
+	case 5:
+	case 6:
+		fields = append([]cronField{cronSeconds}, cronFields...)
+	default:
+		return "", fmt.Errorf("invalid cron expression %q: expected 5 "+
+			"or 6 fields, got %d", value, len(items))
+	}
+
+	for i, item := range items {
+		if err := fields[i].check(item); err != nil {
+			return "", fmt.Errorf("invalid cron expression %q: %s: %w",
+				value, fields[i].name, err)
+		}
+	}
+
+	return CronSpec(strings.Join(items, " ")), nil
+}
+
+// String returns the cron expression.
+func (c CronSpec) String() string {
+	return string(c)
+}
+
+// The check validates the value of the field: the comma-separated
+// list of the ranges with the optional steps.
+func (f cronField) check(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step %q", step)
+			}
+		}
+
+		if rng == "*" || rng == "?" {
+			continue
+		}
+
+		low, high, isRange := strings.Cut(rng, "-")
+		min, err := f.value(low)
+		if err != nil {
+			return err
+		}
+
+		max := min
+		if isRange {
+			if max, err = f.value(high); err != nil {
+				return err
+			}
+		}
+
+		if min > max {
+			return fmt.Errorf("invalid range %q", rng)
+		}
+	}
+
+	return nil
+}
+
+// The value returns the number of the value of the field,
+// the value can be the number or the name.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", n, f.min, f.max)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestParseCronSpec tests ParseCronSpec function.
+func TestParseCronSpec(t *testing.T) {
+	tests := map[string]bool{
+		"0 3 * * *":            true,
+		"*/15 0-23/2 1,15 * ?": true,
+		"0 0 12 * JAN-MAR mon": true,
+		"30 0 3 * * 7":         true,
+		"@daily":               true,
+		"@every 1h30m":         true,
+		"":                     true,
+		"0 3 * *":              false,
+		"60 3 * * *":           false,
+		"0 3 * * * * *":        false,
+		"0 3 32 * *":           false,
+		"0 3 * FOO *":          false,
+		"0 5-3 * * *":          false,
+		"*/0 * * * *":          false,
+		"@often":               false,
+		"@every soon":          false,
+	}
+
+	for value, ok := range tests {
+		if _, err := ParseCronSpec(value); (err == nil) != ok {
+			t.Errorf("%q: expected %t but `%v`", value, ok, err)
+		}
+	}
+}
+
+// TestUnmarshalCronSpec tests the CronSpec fields.
+func TestUnmarshalCronSpec(t *testing.T) {
+	type config struct {
+		Backup  CronSpec   `env:"BACKUP_SCHEDULE" def:"0 3 * * *"`
+		Reports []CronSpec `env:"REPORTS" sep:";"`
+	}
+
+	os.Clearenv()
+	Set("REPORTS", "@hourly;0  9 * * MON-FRI")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Backup != "0 3 * * *" || len(data.Reports) != 2 ||
+		data.Reports[1] != "0 9 * * MON-FRI" {
+		t.Errorf("incorrect values: %+v", data)
+	}
+
+	Set("BACKUP_SCHEDULE", "0 25 * * *")
+	err := Unmarshal("", &config{})
+
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) || keyErr.Key != "BACKUP_SCHEDULE" ||
+		!strings.Contains(err.Error(), "hour") {
+		t.Errorf("expected the error of the BACKUP_SCHEDULE key but `%v`",
+			err)
+	}
+}
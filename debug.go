@@ -0,0 +1,135 @@
+package env
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// The debugTemplate is the HTML template of the DebugHandler page.
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Configuration</title></head>
+<body>
+<table>
+<tr><th>Key</th><th>Value</th></tr>
+{{- range .}}
+<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// DebugHandler returns an http.Handler that renders the current
+// configuration of the object as it would be marshaled into the
+// environment. The obj is marshaled on each request, so if a pointer
+// is passed, the handler always displays the actual values.
+//
+// The values of the fields marked by the `secret:"true"` tag and of the
+// keys that match one of the maskPatterns are replaced by the mask.
+// Patterns use the path.Match syntax and are case-insensitive,
+// for example: "*PASSWORD*", "DB_*". If maskPatterns is nil, the default
+// patterns are used (keys containing PASSWORD, SECRET, TOKEN etc.),
+// to disable masking pass an empty slice.
+//
+// The configuration is rendered as JSON by default, or as HTML if the
+// request has the format=html query parameter or accepts text/html.
+//
+// # Examples
+//
+//	http.Handle("/debug/config", env.DebugHandler("", &cfg, nil))
+func DebugHandler(prefix string, obj interface{},
+	maskPatterns []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pairs, err := effectiveConfig(prefix, obj, maskPatterns)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		accept := r.Header.Get("Accept")
+		if format == "" && strings.Contains(accept, "text/html") {
+			format = "html"
+		}
+
+		if format == "html" {
+			type row struct{ Key, Value string }
+			rows := make([]row, len(pairs))
+			for i, p := range pairs {
+				rows[i] = row{Key: p.key, Value: p.value}
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			debugTemplate.Execute(w, rows)
+			return
+		}
+
+		values := make(map[string]string, len(pairs))
+		for _, p := range pairs {
+			values[p.key] = p.value
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(values)
+	})
+}
+
+// The expvarMu guards the check and the publishing of the expvar
+// variable by PublishExpvar, the expvar.Publish panics if the name
+// is already registered.
+var expvarMu sync.Mutex
+
+// PublishExpvar registers the current configuration of the object
+// under expvar, so it appears in /debug/vars alongside other runtime
+// metrics. The obj is marshaled each time the variables are requested,
+// so if a pointer is passed, the actual values are always displayed.
+//
+// The values of the fields marked by the `secret:"true"` tag and of the
+// keys that look like secrets (containing PASSWORD, SECRET, TOKEN etc.)
+// are masked.
+//
+// The variable is published with the name "env" or "env:<prefix>" if the
+// prefix isn't empty. Returns an error if the name is already registered.
+//
+// # Examples
+//
+//	if err := env.PublishExpvar("APP_", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func PublishExpvar(prefix string, obj interface{}) error {
+	name := "env"
+	if prefix != "" {
+		name += ":" + prefix
+	}
+
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %s is already published", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		pairs, err := effectiveConfig(prefix, obj, nil)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+
+		values := make(map[string]string, len(pairs))
+		for _, p := range pairs {
+			values[p.key] = p.value
+		}
+
+		return values
+	}))
+
+	return nil
+}
@@ -1,22 +1,112 @@
 package env
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"net/url"
-	"os"
 	"reflect"
+	"runtime/pprof"
 	"strconv"
-	"strings"
+	"time"
 )
 
+// The durationType is the reflect.Type of the time.Duration.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// The timeType is the reflect.Type of the time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
 // Unmarshaler is the interface implements by types that can
 // unmarshal an environment variables of themselves.
 type Unmarshaler interface {
 	UnmarshalEnv() error
 }
 
+// PartialUnmarshaler is the interface implemented by types that refine
+// the result of the automatic unmarshaling. Unlike Unmarshaler, the
+// fields are unmarshaled first (with the default values, prefixes and
+// required keys from the tags), then the UnmarshalEnvPartial method
+// is called to adjust or validate them.
+//
+// The type can't implement both interfaces, ErrAmbiguousUnmarshaler
+// is returned in this case.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST" def:"localhost"`
+//		Port int    `env:"PORT" def:"8080"`
+//		Addr string `env:"ADDR"`
+//	}
+//
+//	func (c *Config) UnmarshalEnvPartial() error {
+//		if c.Port < 1024 {
+//			return errors.New("privileged port")
+//		}
+//		if c.Addr == "" {
+//			c.Addr = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+//		}
+//		return nil
+//	}
+type PartialUnmarshaler interface {
+	UnmarshalEnvPartial() error
+}
+
+// The customUnmarshaler returns the custom Unmarshaler of the object,
+// or nil if the object doesn't implement it (or implements the
+// PartialUnmarshaler instead).
+func customUnmarshaler(obj interface{}) (Unmarshaler, error) {
+	unmarshaler, ok := obj.(Unmarshaler)
+	if !ok {
+		return nil, nil
+	}
+
+	if _, ok := obj.(PartialUnmarshaler); ok {
+		return nil, ErrAmbiguousUnmarshaler
+	}
+
+	return unmarshaler, nil
+}
+
+// The unmarshalerType is the reflect.Type of the Unmarshaler interface.
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// The unmarshalerOf returns the Unmarshaler of the settable item. The
+// method can be declared on the value or pointer receiver, the nil
+// pointer is initialized by a new value before it's returned.
+func unmarshalerOf(item reflect.Value) (Unmarshaler, bool) {
+	t := item.Type()
+	if t.Kind() == reflect.Ptr {
+		if !t.Implements(unmarshalerType) || !item.CanSet() {
+			return nil, false
+		}
+
+		if item.IsNil() {
+			item.Set(reflect.New(t.Elem()))
+		}
+		return item.Interface().(Unmarshaler), true
+	}
+
+	if !item.CanAddr() || !item.CanInterface() ||
+		!reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil, false
+	}
+
+	return item.Addr().Interface().(Unmarshaler), true
+}
+
+// The refine calls the UnmarshalEnvPartial method of the object
+// if it implements the PartialUnmarshaler interface.
+func refine(obj interface{}) error {
+	if partial, ok := obj.(PartialUnmarshaler); ok {
+		return partial.UnmarshalEnvPartial()
+	}
+
+	return nil
+}
+
 // The validateStruct checks whether the object is a pointer to the structure,
 // and returns reflect.Type and reflect.Value of the object. If the object is
 // not a pointer to the structure or object is nil, it returns an error.
@@ -45,10 +135,14 @@ func validateStruct(obj interface{}) (reflect.Type, reflect.Value, error) {
 // int64, uin, uint8, uin16, uint32, in64, float32, float64, string, bool,
 // struct, url.URL and pointers, array or slice from types like (i.e. *int,
 // *uint, ..., []int, ..., []bool, ..., [2]*url.URL, etc.). The fields as
-// a struct or pointer on the struct will be processed recursively.
+// a struct or pointer on the struct will be processed recursively. The maps
+// with string keys and values are parsed from the items like a=1 separated
+// by the sep tag, the keys and values are separated by the kvsep tag. The
+// maps of structures are unmarshaled by the prefix: the DB_MAIN_HOST key
+// sets the Host field of the MAIN entry of the map with the DB key.
 //
-// For other type of the fields (i.e chan, map ...) or upon occurrence other
-// conversion problems will be returned an error.
+// For other type of the fields (i.e chan, map[string]int ...) or upon
+// occurrence other conversion problems will be returned an error.
 //
 // The prefix argument filters keys by a certain prefix and used as a marker
 // of the nesting level during the recursive processing of object fields
@@ -56,77 +150,220 @@ func validateStruct(obj interface{}) (reflect.Type, reflect.Value, error) {
 //
 // The obj is a pointer to an initialized object where need to
 // save variables from the environment.
-func unmarshalEnv(prefix string, obj interface{}) error {
-	t, v, err := validateStruct(obj)
+//
+// The work is labeled with the env.phase and env.prefix pprof labels.
+// In the snapshot mode the values are taken from the snapshot of the
+// environment (see SnapshotMode).
+func unmarshalEnv(prefix string, obj interface{}) (err error) {
+	lookup, keys := lookupFunc(lookupEnv), keysFunc(environKeys)
+	if snapshotMode.Load() {
+		lookup, keys = snapshotLookup(prefix)
+	}
+
+	labels := pprof.Labels("env.phase", "unmarshal", "env.prefix", prefix)
+	pprof.Do(context.Background(), labels, func(context.Context) {
+		err = unmarshalWith(lookup, keys, prefix, obj)
+	})
+
+	return err
+}
+
+// The lookupFunc retrieves the value of the key from a store. The boolean
+// is false if the key isn't found. An error is returned if the store
+// can't be reached.
+type lookupFunc func(key string) (string, bool, error)
+
+// The lookupEnv is the lookupFunc for the process environment.
+func lookupEnv(key string) (string, bool, error) {
+	value, ok := lookupenv(key)
+	return value, ok, nil
+}
+
+// The unmarshalWith works like unmarshalEnv but takes the values of
+// the keys by the lookup function and the names of the entries of
+// the maps of structures by the keys function.
+//
+// If lookup is nil, only the default values from def tags are used:
+// the fields without default values are skipped, the required keys
+// aren't checked and the custom Unmarshaler isn't called (as it
+// reads the environment by itself), as well as the PartialUnmarshaler.
+func unmarshalWith(lookup lookupFunc, keys keysFunc, prefix string,
+	obj interface{}) error {
+	_, v, err := validateStruct(obj)
 	if err != nil {
 		return err
 	}
 
 	// If objects implements Unmarshaler interface
 	// try to calling a custom Unmarshal method.
-	if unmarshaler, ok := obj.(Unmarshaler); ok {
+	unmarshaler, err := customUnmarshaler(obj)
+	if err != nil {
+		return err
+	} else if unmarshaler != nil && lookup != nil {
 		return unmarshaler.UnmarshalEnv()
 	}
 
 	// Note: It makes no sense to execute the following code in goroutines,
 	// because the environment variables are global and the access to them
-	// is not thread-safe.
+	// is not thread-safe (see UnmarshalParallel for the snapshot mode).
 
 	// Walk through all the fields of the structure
 	// and save data from the environment.
 	e := v.Elem()
 	for i := 0; i < e.NumField(); i++ {
-		field := t.Elem().Field(i)
-
-		// Get parameters from tags.
-		// The name of the key.
-		key := strings.TrimSpace(field.Tag.Get(tagNameKey))
-		if key == "" {
-			key = field.Name
+		if err := unmarshalField(lookup, keys, prefix, e, i); err != nil {
+			return err
 		}
+	}
 
-		// Separator value for slices/arrays.
-		sep := field.Tag.Get(tagNameSep)
-		if sep == "" {
-			sep = defValueSep
-		}
+	// The custom method refines the result.
+	if lookup != nil {
+		return refine(obj)
+	}
 
-		// Create tag group.
-		tg := &tagGroup{
-			key:   fmt.Sprintf("%s%s", prefix, key),
-			value: field.Tag.Get(tagNameValue),
-			sep:   sep,
-		}
+	return nil
+}
+
+// The unmarshalField sets the value of the i-th field of the
+// structure e by the lookup function (see unmarshalWith).
+func unmarshalField(lookup lookupFunc, keys keysFunc, prefix string,
+	e reflect.Value, i int) error {
+	field := e.Type().Field(i)
+
+	// Get parameters from tags.
+	tg := newTagGroup(field)
+	tg.addPrefix(prefix)
+
+	if !tg.isValid() {
+		return fmt.Errorf(
+			"the %s field does not have a valid key name value: %s",
+			field.Name,
+			tg.key,
+		)
+	}
 
-		if !tg.isValid() {
-			return fmt.Errorf(
-				"the %s field does not have a valid key name value: %s",
-				field.Name,
-				tg.key,
-			)
+	// If the key exists - take its value from environment.
+	// The required key must exist or have a default value,
+	// nested structures are checked by their own fields.
+	if lookup != nil {
+		value, ok, err := tg.lookup(lookup)
+		if err != nil {
+			return err
 		}
 
-		// If the key exists - take its value from environment.
-		if value, ok := os.LookupEnv(tg.key); ok {
+		if ok {
 			tg.value = value
+		} else if tg.required && tg.value == "" &&
+			!isNestedStruct(field.Type) && !isStructMap(field.Type) {
+			return fmt.Errorf("%w: %s", ErrRequired, tg.key)
 		}
+	} else if tg.value == "" && !isNestedStruct(field.Type) {
+		return nil // there is no default value
+	}
 
-		// Set value to field.
-		item := e.FieldByName(field.Name)
-		if err := setFieldValue(&item, tg); err != nil {
-			return err
-		}
+	// Set value to field.
+	// The errors of the registered decoders and
+	// converters are returned with the key name.
+	item := e.Field(i)
+	err := setFieldValue(&item, tg, lookup, keys)
+
+	var decErr *decoderError
+	if errors.As(err, &decErr) {
+		err = &KeyError{Key: tg.key, Err: decErr.err}
 	}
 
-	return nil
+	return err
+}
+
+// The isNestedStruct returns true if the type is a structure or a pointer
+// to a structure whose fields are processed recursively (not a url.URL
+// or other value structure).
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !isValueStruct(t)
+}
+
+// The isValueStruct returns true if the structure is converted from
+// the single value: url.URL, SecretString, Range, HostPort, Template
+// of the text/template and html/template packages, the structure
+// with the registered decoder or that implements the
+// encoding.TextUnmarshaler.
+func isValueStruct(t reflect.Type) bool {
+	if hasDecoder(t) || isTextUnmarshaler(t) {
+		return true // the registered or text type, see RegisterDecoder
+	}
+
+	switch t {
+	case reflect.TypeOf(url.URL{}), secretStringType, rangeType, timeType,
+		hostPortType, textTemplateType.Elem(), htmlTemplateType.Elem():
+		return true
+	}
+
+	return false
 }
 
 // The setFieldValue sets value to field from the tag arguments.
-func setFieldValue(item *reflect.Value, tg *tagGroup) error {
+// The nested structures are unmarshaled by the lookup function,
+// the entries of the maps of structures are found by the keys.
+func setFieldValue(item *reflect.Value, tg *tagGroup, lookup lookupFunc,
+	keys keysFunc) error {
+	// The templates are compiled with the options of the tags.
+	if isTemplateType(item.Type()) {
+		return setTemplate(item, tg)
+	}
+
+	// The registered types (including slices) are decoded as a whole.
+	if hasDecoder(item.Type()) {
+		return setValue(*item, tg.value)
+	}
+
+	// The fields that implement Unmarshaler read the environment by
+	// themselves (the nested structures are checked by unmarshalWith).
+	if lookup != nil && !isNestedStruct(item.Type()) {
+		if unmarshaler, ok := unmarshalerOf(*item); ok {
+			return unmarshaler.UnmarshalEnv()
+		}
+	}
+
+	// The types that implement encoding.TextUnmarshaler (including
+	// slices, like net.IP) are unmarshaled as a whole.
+	if isTextUnmarshaler(item.Type()) {
+		return setValue(*item, tg.value)
+	}
+
+	// The float values in the local format, like 3,14, are
+	// converted to the Go format before the conversion.
+	if tg.decimal != "" && isFloatType(item.Type()) {
+		value, err := localizeFloats(item.Type(), tg)
+		if err != nil {
+			return err
+		}
+
+		local := *tg
+		local.value = value
+		tg = &local
+	}
+
+	// The paths are resolved and checked at unmarshal only
+	// (not for the default values and validation of the tags).
+	if tg.path != "" && lookup != nil && isStringType(item.Type()) {
+		value, err := resolvePaths(item.Type(), tg, false)
+		if err != nil {
+			return err
+		}
+
+		local := *tg
+		local.value = value
+		tg = &local
+	}
+
 	switch item.Kind() {
 	case reflect.Array:
 		max := item.Type().Len()
-		seq := splitN(tg.value, tg.sep, -1)
+		seq := splitList(tg.value, tg.sep)
 		if len(seq) > max {
 			return fmt.Errorf("%d overflows the [%d]array", len(seq), max)
 		}
@@ -135,16 +372,43 @@ func setFieldValue(item *reflect.Value, tg *tagGroup) error {
 			return err
 		}
 	case reflect.Slice:
-		seq := splitN(tg.value, tg.sep, -1)
+		seq := splitList(tg.value, tg.sep)
 		tmp := reflect.MakeSlice(item.Type(), len(seq), len(seq))
 		if err := setSequence(&tmp, seq); err != nil {
 			return err
 		}
 
+		// The default values replace the slice (see Defaults).
+		if lookup == nil {
+			item.Set(tmp)
+			break
+		}
+
 		item.Set(reflect.AppendSlice(*item, tmp))
+	case reflect.Map:
+		// The map of structures is unmarshaled by the prefix,
+		// its entries have no default values.
+		if isStructMap(item.Type()) {
+			if lookup == nil {
+				break
+			}
+
+			return setStructMap(item, tg.key+"_", lookup, keys)
+		}
+
+		if err := setMap(item, tg.value, tg.sep, tg.kvsep); err != nil {
+			return err
+		}
 	case reflect.Ptr:
-		if item.Type().Elem().Kind() != reflect.Struct {
+		if item.Type().Elem().Kind() != reflect.Struct ||
+			(isValueStruct(item.Type().Elem()) &&
+				item.Type() != reflect.TypeOf((*url.URL)(nil))) {
 			// If the pointer of a structure.
+			// The nil pointer is initialized by a new value.
+			if item.IsNil() {
+				item.Set(reflect.New(item.Type().Elem()))
+			}
+
 			tmp := reflect.Indirect(*item)
 			if err := setValue(tmp, tg.value); err != nil {
 				return err
@@ -160,15 +424,21 @@ func setFieldValue(item *reflect.Value, tg *tagGroup) error {
 
 		// If a pointer to a structure of the another's types (not a *url.URL).
 		// Perform recursive analysis of nested structure fields.
+		// The default values are set in place (see Defaults).
+		p := fmt.Sprintf("%s_", tg.key)
+		if lookup == nil && !item.IsNil() {
+			return unmarshalWith(nil, keys, p, item.Interface())
+		}
+
 		tmp := reflect.New(item.Type().Elem()).Interface()
-		if err := unmarshalEnv(fmt.Sprintf("%s_", tg.key), tmp); err != nil {
+		if err := unmarshalWith(lookup, keys, p, tmp); err != nil {
 			return err
 		}
 
 		item.Set(reflect.ValueOf(tmp))
 	case reflect.Struct:
-		if item.Type() == reflect.TypeOf(url.URL{}) {
-			// If a url.URL structure.
+		if isValueStruct(item.Type()) {
+			// If a url.URL or other value structure.
 			if err := setValue(*item, tg.value); err != nil {
 				return err
 			}
@@ -177,8 +447,14 @@ func setFieldValue(item *reflect.Value, tg *tagGroup) error {
 
 		// If a structure of the another's types (not a url.URL).
 		// Perform recursive analysis of nested structure fields.
+		// The default values are set in place (see Defaults).
+		p := fmt.Sprintf("%s_", tg.key)
+		if lookup == nil && item.CanAddr() {
+			return unmarshalWith(nil, keys, p, item.Addr().Interface())
+		}
+
 		tmp := reflect.New(item.Type()).Interface()
-		if err := unmarshalEnv(fmt.Sprintf("%s_", tg.key), tmp); err != nil {
+		if err := unmarshalWith(lookup, keys, p, tmp); err != nil {
 			return err
 		}
 
@@ -218,6 +494,11 @@ func setSequence(item *reflect.Value, seq []string) error {
 func setValue(item reflect.Value, value string) error {
 	kind := item.Kind()
 
+	// The registered types, see RegisterDecoder.
+	if ok, err := decodeValue(item, value); ok {
+		return err
+	}
+
 	// The *url.URL pointer only.
 	if kind == reflect.Ptr && item.Type() == reflect.TypeOf((*url.URL)(nil)) {
 		u, err := url.Parse(value)
@@ -238,6 +519,90 @@ func setValue(item reflect.Value, value string) error {
 		return nil
 	}
 
+	// The SecretString struct only.
+	if item.Type() == secretStringType {
+		item.Set(reflect.ValueOf(NewSecretString(value)))
+		return nil
+	}
+
+	// The empty value of the Range, HostPort or Port is the zero value.
+	if t := item.Type(); value == "" &&
+		(t == rangeType || t == hostPortType || t == portType) {
+		item.Set(reflect.Zero(t))
+		return nil
+	}
+
+	// The Range struct only.
+	if item.Type() == rangeType {
+		r, err := ParseRange(value)
+		if err != nil {
+			return err
+		}
+		item.Set(reflect.ValueOf(r))
+		return nil
+	}
+
+	// The HostPort struct only.
+	if item.Type() == hostPortType {
+		hp, err := ParseHostPort(value)
+		if err != nil {
+			return err
+		}
+		item.Set(reflect.ValueOf(hp))
+		return nil
+	}
+
+	// The Port is validated by ParsePort.
+	if item.Type() == portType {
+		p, err := ParsePort(value)
+		if err != nil {
+			return err
+		}
+		item.SetUint(uint64(p))
+		return nil
+	}
+
+	// The time.Duration is parsed by time.ParseDuration,
+	// the integer value is the number of nanoseconds.
+	if item.Type() == durationType && value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			item.SetInt(n)
+			return nil
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		item.SetInt(int64(d))
+		return nil
+	}
+
+	// The time.Time is parsed in the RFC3339 format,
+	// the empty value is the zero time.
+	if item.Type() == timeType {
+		var t time.Time
+		if value != "" {
+			tmp, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return err
+			}
+			t = tmp
+		}
+		item.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	// The types that implement encoding.TextUnmarshaler.
+	if ok, err := unmarshalText(item, value); ok {
+		return err
+	}
+
+	// The kinds with the custom conversion, see RegisterKindConverter.
+	if ok, err := convertKind(item, value); ok {
+		return err
+	}
+
 	switch kind {
 	case reflect.Int, reflect.Int8, reflect.Int16,
 		reflect.Int32, reflect.Int64:
@@ -275,7 +640,8 @@ func setValue(item reflect.Value, value string) error {
 }
 
 // The strToIntKind converts string to int64 type with out-of-range checking
-// for int. Returns 0 if value is empty.
+// for int. Returns 0 if value is empty. The accepted forms of the value are
+// set by the SetNumberFormat function.
 func strToIntKind(value string, kind reflect.Kind) (int64, error) {
 	var min, max int64
 
@@ -285,7 +651,12 @@ func strToIntKind(value string, kind reflect.Kind) (int64, error) {
 	}
 
 	// Convert string to int64.
-	r, err := strconv.ParseInt(value, 10, 64)
+	number, base, err := normalizeNumber(value)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := strconv.ParseInt(number, base, 64)
 	if err != nil {
 		return 0, err
 	}
@@ -322,7 +693,8 @@ func strToIntKind(value string, kind reflect.Kind) (int64, error) {
 }
 
 // The strToUintKind convert string to uint64 type with out-of-range checking
-// for uint. Returns 0 if value is empty.
+// for uint. Returns 0 if value is empty. The accepted forms of the value are
+// set by the SetNumberFormat function.
 func strToUintKind(value string, kind reflect.Kind) (uint64, error) {
 	var max uint64
 
@@ -332,7 +704,12 @@ func strToUintKind(value string, kind reflect.Kind) (uint64, error) {
 	}
 
 	// Convert string to uint64.
-	r, err := strconv.ParseUint(value, 10, 64)
+	number, base, err := normalizeNumber(value)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := strconv.ParseUint(number, base, 64)
 	if err != nil {
 		return 0, err
 	}
@@ -403,6 +780,8 @@ func strToFloatKind(value string, kind reflect.Kind) (float64, error) {
 
 // The strToBool convert string to bool type.
 // Returns false if value is empty.
+//
+// The numbers are accepted only in the non-strict mode (see StrictBool).
 func strToBool(v string) (bool, error) {
 	// For empty string returns false.
 	if len(v) == 0 {
@@ -410,16 +789,17 @@ func strToBool(v string) (bool, error) {
 	}
 
 	// Try to convert string to bool.
-	// It accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.
-	r, err := strconv.ParseBool(v)
+	// It accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+	// and the registered synonyms (see RegisterBoolStrings).
+	r, err := parseBool(v)
 	if err == nil {
 		return r, nil
 	}
 
-	// If strconv.ParseBool() fails, try to parse as a float and check if the
+	// If parseBool() fails, try to parse as a float and check if the
 	// absolute value is greater than 0.7.
 	f, err := strconv.ParseFloat(v, 64)
-	if err != nil {
+	if err != nil || strictBool.Load() {
 		return false, fmt.Errorf("'%s' cannot be converted to a boolean", v)
 	}
 
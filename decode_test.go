@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // The configDecode structure with custom UnmarshalEnv method.
@@ -35,6 +37,44 @@ func (c *configDecodeErr) UnmarshalEnv() error {
 	return errors.New("error message")
 }
 
+// The configPartial structure with custom UnmarshalEnvPartial method.
+type configPartial struct {
+	Host string `env:"HOST" def:"localhost"`
+	Port int    `env:"PORT" def:"8080"`
+	Addr string `env:"ADDR"`
+}
+
+// UnmarshalEnvPartial refines the unmarshaled fields.
+func (c *configPartial) UnmarshalEnvPartial() error {
+	if c.Port < 1024 {
+		return errors.New("privileged port")
+	}
+
+	if c.Addr == "" {
+		c.Addr = fmt.Sprintf("%s:%d", c.Host, c.Port)
+	}
+	return nil
+}
+
+// The configAmbiguous structure implements both custom methods.
+type configAmbiguous struct {
+	configDecode
+}
+
+// UnmarshalEnvPartial the custom method that is never called.
+func (c *configAmbiguous) UnmarshalEnvPartial() error {
+	return nil
+}
+
+// The hostsDecode slice with custom UnmarshalEnv method.
+type hostsDecode []string
+
+// UnmarshalEnv the custom method for unmarshalling of the hosts.
+func (h *hostsDecode) UnmarshalEnv() error {
+	*h = strings.Split(Get("HOSTS_LIST"), ";")
+	return nil
+}
+
 // TestUnmarshalEnvNil tests unmarshalEnv for nil object.
 func TestUnmarshalEnvNil(t *testing.T) {
 	if err := unmarshalEnv("", nil); err == nil {
@@ -438,8 +478,9 @@ func TestUnmarshalEnvSlice(t *testing.T) {
 			// Bool string.
 			value = "true#true#true#true#false#false#false#false"
 		} else if key == "KEY_GROUP" {
-			// Checking if the group has been split correctly.
-			value = "one#\"two:three\"#four#five"
+			// Checking if the group has been split correctly,
+			// the quotes around the item are removed.
+			value = "one#two:three#four#five"
 			for i := 0; i < len(d.KeyGroup); i++ {
 				d.KeyGroup[i] = strings.Replace(d.KeyGroup[i], "#", ":", 1)
 			}
@@ -521,8 +562,9 @@ func TestUnmarshalEnvArray(t *testing.T) {
 		if key == "KEY_BOOL" {
 			value = "true:true:true:true:false:false:false:false"
 		} else if key == "KEY_GROUP" {
-			// Checking if the group has been split correctly.
-			value = "one:\"two#three\":four:five"
+			// Checking if the group has been split correctly,
+			// the quotes around the item are removed.
+			value = "one:two#three:four:five"
 			for i := 0; i < len(d.KeyGroup); i++ {
 				d.KeyGroup[i] = strings.Replace(d.KeyGroup[i], ":", "#", 1)
 			}
@@ -909,3 +951,225 @@ func TestMarshalMultiService(t *testing.T) {
 		t.Errorf("expected `B` but `%s`", v)
 	}
 }
+
+// TestUnmarshalRequired tests unmarshalEnv for required keys.
+func TestUnmarshalRequired(t *testing.T) {
+	type nested struct {
+		Label string `env:"LABEL"`
+	}
+
+	data := struct {
+		Host   string `env:"HOST" required:"true"`
+		Port   int    `env:"PORT" def:"80" required:"true"`
+		Nested nested `env:"NESTED" required:"true"`
+	}{}
+
+	os.Clearenv()
+	if err := unmarshalEnv("", &data); !errors.Is(err, ErrRequired) {
+		t.Errorf("expected ErrRequired but %v", err)
+	}
+
+	os.Setenv("HOST", "")
+	if err := unmarshalEnv("", &data); err != nil {
+		t.Error(err)
+	}
+
+	if data.Port != 80 {
+		t.Errorf("expected 80 but %d", data.Port)
+	}
+}
+
+// TestUnmarshalEnvNilPtr tests unmarshalEnv for nil pointers
+// to the basic types.
+func TestUnmarshalEnvNilPtr(t *testing.T) {
+	data := struct {
+		KeyInt  *int  `env:"KEY_INT"`
+		KeyBool *bool `env:"KEY_BOOL"`
+	}{}
+
+	os.Clearenv()
+	os.Setenv("KEY_INT", "7")
+	if err := unmarshalEnv("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.KeyInt == nil || *data.KeyInt != 7 {
+		t.Errorf("expected 7 but %v", data.KeyInt)
+	}
+
+	if data.KeyBool == nil || *data.KeyBool {
+		t.Errorf("expected false but %v", data.KeyBool)
+	}
+}
+
+// TestUnmarshalEnvDuration tests the time.Duration fields.
+func TestUnmarshalEnvDuration(t *testing.T) {
+	type data struct {
+		Timeout time.Duration   `env:"TIMEOUT" def:"30s"`
+		Nanos   time.Duration   `env:"NANOS"`
+		Backoff []time.Duration `env:"BACKOFF" sep:","`
+	}
+
+	os.Clearenv()
+	Set("NANOS", "1500")
+	Set("BACKOFF", "1s,5s,1m30s")
+
+	var d data
+	if err := unmarshalEnv("", &d); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []time.Duration{time.Second, 5 * time.Second,
+		90 * time.Second}
+	if d.Timeout != 30*time.Second || d.Nanos != 1500 ||
+		!reflect.DeepEqual(d.Backoff, expected) {
+		t.Errorf("incorrect durations: %+v", d)
+	}
+
+	Set("BACKOFF", "1s,5x")
+	if err := unmarshalEnv("", &data{}); err == nil {
+		t.Error("expected an error for invalid duration")
+	}
+}
+
+// TestUnmarshalEnvTime tests the time.Time fields.
+func TestUnmarshalEnvTime(t *testing.T) {
+	type data struct {
+		Deadline time.Time   `env:"DEADLINE"`
+		Start    *time.Time  `env:"START" def:"2025-01-01T00:00:00Z"`
+		Empty    time.Time   `env:"EMPTY"`
+		Dates    []time.Time `env:"DATES" sep:","`
+	}
+
+	os.Clearenv()
+	Set("DEADLINE", "2025-06-30T18:00:00+02:00")
+	Set("DATES", "2025-01-01T00:00:00Z,2025-01-02T00:00:00.5Z")
+
+	var d data
+	if err := unmarshalEnv("", &d); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Date(2025, 6, 30, 16, 0, 0, 0, time.UTC)
+	if !d.Deadline.Equal(deadline) {
+		t.Errorf("expected `%v` but `%v`", deadline, d.Deadline)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d.Start == nil || !d.Start.Equal(start) {
+		t.Errorf("expected `%v` but `%v`", start, d.Start)
+	}
+
+	if !d.Empty.IsZero() {
+		t.Errorf("expected zero time but `%v`", d.Empty)
+	}
+
+	if len(d.Dates) != 2 || d.Dates[1].Nanosecond() != 5e8 {
+		t.Errorf("incorrect dates: %v", d.Dates)
+	}
+
+	Set("DEADLINE", "30.06.2025")
+	if err := unmarshalEnv("", &data{}); err == nil {
+		t.Error("expected an error for invalid time")
+	}
+}
+
+// TestUnmarshalEnvPartial tests the PartialUnmarshaler objects.
+func TestUnmarshalEnvPartial(t *testing.T) {
+	os.Clearenv()
+	Set("APP_HOST", "example.com")
+
+	var data configPartial
+	if err := unmarshalEnv("APP_", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Addr != "example.com:8080" {
+		t.Errorf("expected `example.com:8080` but `%s`", data.Addr)
+	}
+
+	Set("APP_PORT", "80")
+	if err := unmarshalEnv("APP_", &data); err == nil {
+		t.Error("expected an error from UnmarshalEnvPartial")
+	}
+
+	Set("APP_PORT", "9090")
+	data = configPartial{}
+	if err := UnmarshalParallel("APP_", &data, 2); err != nil ||
+		data.Addr != "example.com:9090" {
+		t.Errorf("expected `example.com:9090` but `%s` (%v)",
+			data.Addr, err)
+	}
+
+	var ambiguous configAmbiguous
+	err := unmarshalEnv("", &ambiguous)
+	if !errors.Is(err, ErrAmbiguousUnmarshaler) {
+		t.Errorf("expected ErrAmbiguousUnmarshaler but `%v`", err)
+	}
+}
+
+// TestUnmarshalEnvFieldUnmarshaler tests the fields
+// that implement Unmarshaler interface.
+func TestUnmarshalEnvFieldUnmarshaler(t *testing.T) {
+	type config struct {
+		Hosts  hostsDecode  `env:"HOSTS"`
+		Ptr    *hostsDecode `env:"PTR"`
+		Server configDecode `env:"SERVER"`
+	}
+
+	os.Clearenv()
+	Set("HOSTS_LIST", "a;b")
+
+	var data config
+	if err := unmarshalEnv("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := strings.Join(data.Hosts, ","); v != "a,b" {
+		t.Errorf("expected `a,b` but `%s`", v)
+	}
+
+	if data.Ptr == nil || len(*data.Ptr) != 2 {
+		t.Errorf("expected `[a b]` but `%v`", data.Ptr)
+	}
+
+	if data.Server.Host != "192.168.0.3" {
+		t.Errorf("expected `192.168.0.3` but `%s`", data.Server.Host)
+	}
+}
+
+// TestUnmarshalEnvFallback tests the fallback names of the keys.
+func TestUnmarshalEnvFallback(t *testing.T) {
+	type config struct {
+		Port int    `env:"PORT|NOMAD_PORT_http" def:"80"`
+		Host string `env:"HOST|LEGACY_HOST" required:"true"`
+	}
+
+	os.Clearenv()
+	Set("APP_NOMAD_PORT_http", "8080")
+	Set("APP_LEGACY_HOST", "legacy")
+
+	var data config
+	if err := unmarshalEnv("APP_", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Port != 8080 || data.Host != "legacy" {
+		t.Errorf("expected `8080 legacy` but `%d %s`", data.Port, data.Host)
+	}
+
+	Set("APP_HOST", "new")
+	if err := unmarshalEnv("APP_", &data); err != nil || data.Host != "new" {
+		t.Errorf("expected `new` but `%s` (%v)", data.Host, err)
+	}
+
+	os.Clearenv()
+	if err := unmarshalEnv("APP_", &data); !errors.Is(err, ErrRequired) {
+		t.Errorf("expected ErrRequired but `%v`", err)
+	}
+
+	items, err := marshalEnv("APP_", data, true)
+	if err != nil || strings.Join(items, " ") != "APP_PORT=80 APP_HOST=new" {
+		t.Errorf("expected `APP_PORT=80 APP_HOST=new` but `%v`", items)
+	}
+}
@@ -0,0 +1,112 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// The decoders contains the registered decoders by the types,
+// the values are func(string) (reflect.Value, error).
+var decoders sync.Map
+
+// RegisterDecoder registers the function that converts the value of the
+// key into the fields of the T type (and *T, []T etc.), it overrides the
+// built-in conversion if T is the built-in type. The decoder is called
+// for the empty value too. The nil function removes the decoder of T.
+// The decoder error is returned by Unmarshal as the *KeyError with the
+// key of the field.
+//
+// The registered types are marshaled by their String or MarshalText
+// method (see fmt.Stringer and encoding.TextMarshaler), or in the %v
+// format if they have no such methods.
+//
+// The registry is global, so the decoders are usually registered in the
+// init functions of the packages that declare the types (see the contrib
+// package with the examples).
+//
+// # Examples
+//
+//	type Level int
+//
+//	func init() {
+//		env.RegisterDecoder(func(value string) (Level, error) {
+//			switch value {
+//			case "debug":
+//				return 0, nil
+//			case "info", "":
+//				return 1, nil
+//			}
+//			return 0, fmt.Errorf("unknown level: %s", value)
+//		})
+//	}
+func RegisterDecoder[T any](decode func(value string) (T, error)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if decode == nil {
+		decoders.Delete(typ)
+		return
+	}
+
+	decoders.Store(typ, func(value string) (reflect.Value, error) {
+		v, err := decode(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	})
+}
+
+// The hasDecoder returns true if the decoder of the type is registered
+// (or the converter, see RegisterTypeConverter).
+func hasDecoder(t reflect.Type) bool {
+	_, ok := decoders.Load(t)
+	return ok || hasConverter(t)
+}
+
+// The decodeValue converts the value by the registered decoder of the
+// item type (or the registered converter) and sets it into the item.
+// The boolean is false if there is no decoder for the type.
+func decodeValue(item reflect.Value, value string) (bool, error) {
+	decode, ok := decoders.Load(item.Type())
+	if !ok {
+		return convertType(item, value)
+	}
+
+	v, err := decode.(func(string) (reflect.Value, error))(value)
+	if err != nil {
+		return true, &decoderError{err: err}
+	}
+
+	item.Set(v)
+	return true, nil
+}
+
+// The decoderError is the error of the registered decoder or converter,
+// it's returned by Unmarshal as the *KeyError with the key of the field.
+type decoderError struct {
+	err error
+}
+
+// Error returns the message of the decoder error.
+func (e *decoderError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the decoder error.
+func (e *decoderError) Unwrap() error {
+	return e.err
+}
+
+// The encodeValue converts the value of the registered type to string.
+func encodeValue(item reflect.Value) (string, error) {
+	switch v := item.Interface().(type) {
+	case fmt.Stringer:
+		return v.String(), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), err
+	}
+
+	return fmt.Sprint(item.Interface()), nil
+}
@@ -0,0 +1,91 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testLevel is the type with the registered decoder.
+type testLevel int
+
+// String returns the name of the level.
+func (l testLevel) String() string {
+	return [...]string{"debug", "info"}[l]
+}
+
+// testPair is the struct type with the registered decoder.
+type testPair struct {
+	A, B string
+}
+
+// testList is the slice type with the registered decoder.
+type testList []string
+
+// TestRegisterDecoder tests the registered decoders.
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder(func(value string) (testLevel, error) {
+		switch value {
+		case "debug":
+			return 0, nil
+		case "info", "":
+			return 1, nil
+		}
+		return 0, fmt.Errorf("unknown level: %s", value)
+	})
+	RegisterDecoder(func(value string) (testPair, error) {
+		a, b, _ := strings.Cut(value, "/")
+		return testPair{a, b}, nil
+	})
+	RegisterDecoder(func(value string) (testList, error) {
+		return strings.Split(value, "|"), nil
+	})
+	defer func() {
+		RegisterDecoder[testLevel](nil)
+		RegisterDecoder[testPair](nil)
+		RegisterDecoder[testList](nil)
+	}()
+
+	type config struct {
+		Level  testLevel   `env:"LEVEL"`
+		Levels []testLevel `env:"LEVELS" sep:","`
+		Ptr    *testLevel  `env:"PTR" def:"debug"`
+		Pair   testPair    `env:"PAIR"`
+		List   testList    `env:"LIST"`
+	}
+
+	os.Clearenv()
+	Set("LEVELS", "debug,info")
+	Set("PAIR", "a/b")
+	Set("LIST", "x|y z")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Level != 1 || len(data.Levels) != 2 || data.Levels[0] != 0 ||
+		data.Ptr == nil || *data.Ptr != 0 ||
+		data.Pair != (testPair{"a", "b"}) || len(data.List) != 2 {
+		t.Errorf("incorrect values: %+v", data)
+	}
+
+	items, err := marshalEnv("", data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"LEVEL=info", "LEVELS=debug,info", "PTR=debug",
+		"PAIR={a b}", "LIST=[x y z]"}
+	for i, item := range expected {
+		if items[i] != item {
+			t.Errorf("expected `%s` but `%s`", item, items[i])
+		}
+	}
+
+	Set("LEVEL", "trace")
+	if err := Unmarshal("", &config{}); err == nil {
+		t.Error("expected an error for unknown level")
+	}
+}
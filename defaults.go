@@ -0,0 +1,27 @@
+package env
+
+// Defaults populates the structure by the values from def tags only,
+// the environment isn't read. The nested structures are processed
+// recursively in place, the fields without def tag keep their values.
+// The slices with def tag are replaced by the default values.
+//
+// Unlike Unmarshal, the required fields without default values don't
+// cause an error, and the custom Unmarshaler isn't called.
+//
+// The obj must be a non-nil pointer to a structure.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST" def:"localhost"`
+//		Port int    `env:"PORT" def:"8080"`
+//	}
+//
+//	var config Config
+//	if err := env.Defaults(&config); err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(config) // {localhost 8080}
+func Defaults(obj interface{}) error {
+	return unmarshalWith(nil, nil, "", obj)
+}
@@ -0,0 +1,75 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+// TestDefaults tests Defaults function.
+func TestDefaults(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST" def:"localhost"`
+		User string `env:"USER" required:"true"`
+	}
+
+	type Config struct {
+		Port  int      `env:"PORT" def:"8080"`
+		Hosts []string `env:"HOSTS" def:"a,b" sep:","`
+		Debug *bool    `env:"DEBUG" def:"true"`
+		Name  string   `env:"NAME"`
+		DB    *DB      `env:"DB"`
+	}
+
+	os.Clearenv()
+	os.Setenv("PORT", "80")
+	os.Setenv("DB_HOST", "example.com")
+
+	config := Config{Name: "app"}
+	if err := Defaults(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Port != 8080 || len(config.Hosts) != 2 ||
+		config.Debug == nil || !*config.Debug || config.Name != "app" {
+		t.Errorf("incorrect defaults: %v", config)
+	}
+
+	if config.DB == nil || config.DB.Host != "localhost" {
+		t.Errorf("incorrect defaults of nested struct: %v", config.DB)
+	}
+
+	// The set values of the nested struct are kept,
+	// the slices are replaced.
+	type Nested struct {
+		DB    DB       `env:"DB"`
+		Ptr   *DB      `env:"PTR"`
+		Hosts []string `env:"HOSTS" def:"a,b" sep:","`
+	}
+
+	nested := Nested{
+		DB:    DB{User: "keep"},
+		Ptr:   &DB{User: "root"},
+		Hosts: []string{"x"},
+	}
+	if err := Defaults(&nested); err != nil {
+		t.Fatal(err)
+	}
+
+	if nested.DB.User != "keep" || nested.DB.Host != "localhost" ||
+		nested.Ptr.User != "root" || nested.Ptr.Host != "localhost" {
+		t.Errorf("incorrect defaults of nested struct: %+v", nested)
+	}
+
+	if len(nested.Hosts) != 2 || nested.Hosts[0] != "a" {
+		t.Errorf("expected [a b] but %v", nested.Hosts)
+	}
+
+	// Incorrect default value.
+	var wrong struct {
+		Port int `env:"PORT" def:"http"`
+	}
+
+	if err := Defaults(&wrong); err == nil {
+		t.Error("an error is expected for incorrect default value")
+	}
+}
@@ -0,0 +1,110 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// DeleteFromFile removes the keys from the env-file: all expressions of
+// the keys are removed (including the lines of the line continuation),
+// and all other lines (other keys, comments and empty lines) stay
+// intact. The keys that aren't in the file are ignored, the file isn't
+// rewritten if nothing is removed. The environment isn't changed.
+//
+// Returns an error if the file can't be read or written.
+//
+// # Examples
+//
+// The file /tmp/.env contains:
+//
+//	# credentials
+//	API_KEY=secret # rotate monthly
+//	HOST=localhost
+//
+// Remove the revoked key:
+//
+//	if err := env.DeleteFromFile("/tmp/.env", "API_KEY"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The result in the file /tmp/.env
+//
+//	# credentials
+//	HOST=localhost
+func DeleteFromFile(filename string, keys ...string) error {
+	return deleteFromFile(filename, false, keys)
+}
+
+// DeleteFromFileMarked works like DeleteFromFile, but leaves the marker
+// comment with the date of the removal in place of the removed key,
+// like # removed 2025-01-02: API_KEY, so the revocation is visible
+// in the file.
+//
+// # Examples
+//
+//	env.DeleteFromFileMarked("/tmp/.env", "API_KEY")
+//
+// The result in the file /tmp/.env
+//
+//	# credentials
+//	# removed 2025-01-02: API_KEY
+//	HOST=localhost
+func DeleteFromFileMarked(filename string, keys ...string) error {
+	return deleteFromFile(filename, true, keys)
+}
+
+// The deleteFromFile removes the keys from the env-file,
+// the marked is true to leave the marker comments.
+func deleteFromFile(filename string, marked bool, keys []string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	removed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		removed[key] = true
+	}
+
+	var (
+		lines  = strings.Split(string(data), "\n")
+		result = make([]string, 0, len(lines))
+		date   = now().Format(time.DateOnly)
+		found  = false
+	)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		tmp := keyRgx.FindStringSubmatch(line)
+		if isEmpty(line) || len(tmp) < 2 || !removed[tmp[1]] {
+			result = append(result, lines[i])
+			continue
+		}
+
+		// Skip the lines of the line continuation too.
+		continued := isUnquotedExpression(line)
+		for continued && isContinued(line) && i+1 < len(lines) &&
+			isContinuation(strings.TrimSuffix(lines[i+1], "\r")) {
+			i++
+			line = strings.TrimSuffix(lines[i], "\r")
+		}
+
+		if marked {
+			result = append(result, "# removed "+date+": "+tmp[1])
+		}
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+
+	return os.WriteFile(filename, []byte(strings.Join(result, "\n")),
+		info.Mode())
+}
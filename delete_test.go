@@ -0,0 +1,83 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDeleteFromFile tests DeleteFromFile function.
+func TestDeleteFromFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := "# credentials\nAPI_KEY=secret # rotate monthly\n" +
+		"HOST=localhost  \n\nexport TOKEN=abc\nOPTS=a \\\n  b\n" +
+		"API_KEY=again\nPORT=8080"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := DeleteFromFile(filename, "API_KEY", "TOKEN", "OPTS", "MISSING")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# credentials\nHOST=localhost  \n\nPORT=8080"
+	if v := string(data); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	if info, err := os.Stat(filename); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected `%v` but `%v`", os.FileMode(0o600), info.Mode())
+	}
+
+	// The missing file.
+	if err := DeleteFromFile(filename + ".missing"); err == nil {
+		t.Error("an error is expected for the missing file")
+	}
+}
+
+// TestDeleteFromFileMarked tests DeleteFromFileMarked function.
+func TestDeleteFromFileMarked(t *testing.T) {
+	current := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := "# credentials\nAPI_KEY=secret\nHOST=localhost\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteFromFileMarked(filename, "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# credentials\n# removed 2025-01-02: API_KEY\n" +
+		"HOST=localhost\n"
+	if v := string(data); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// The marker is a comment, the file can be loaded.
+	os.Clearenv()
+	if err := Load(filename); err != nil {
+		t.Error(err)
+	}
+
+	if Exists("API_KEY") || Get("HOST") != "localhost" {
+		t.Errorf("expected `[HOST=localhost]` but `%v`", Environ())
+	}
+}
@@ -1,19 +1,33 @@
 package env
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// The bufferPool contains the buffers to build the values of sequences,
+// so the repeated marshaling (see Encoder) doesn't allocate them again.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
 // Marshaler is the interface implemented by types that can marshal
 // themselves into valid object.
 type Marshaler interface {
 	MarshalEnv() ([]string, error)
 }
 
+// The setFunc sets the value of the key while marshaling,
+// the nil function doesn't change the environment.
+type setFunc func(key, value string) error
+
 // The marshalEnv saves object's fields to environment.
 // Changes the environment if idle == false only.
 //
@@ -21,11 +35,57 @@ type Marshaler interface {
 // uin, uint8, uin16, uint32, in64, float32, float64, string, bool, url.URL
 // and pointers, array or slice from thous types (i.e. *int, ...,
 // []int, ..., []bool, ..., [2]*url.URL, etc.). The nested structures will be
-// processed recursively.
+// processed recursively. The types that implement encoding.TextMarshaler
+// are saved by the MarshalText method. The maps with string keys and
+// values are saved as the items like a=1 sorted by keys, the entries of
+// the maps of structures are saved with the names in the keys, like
+// DB_MAIN_HOST.
 //
-// For other filed's types (like chan, map ...) will be returned an error.
+// For other filed's types (like chan, map[string]int ...) will be returned
+// an error.
+//
+// The keys are checked against the target platform (see SetTarget)
+// before the environment is changed: the items are built once, the
+// values of the fields are set after the check. The custom MarshalEnv
+// methods that set the keys by themselves change the environment
+// when they are called, only their items are checked.
 func marshalEnv(prefix string, obj interface{}, idle bool) ([]string, error) {
-	var result []string
+	var pending [][2]string // key/value pairs to set after the check
+	items, err := appendEnv(nil, prefix, obj, func(key, value string) error {
+		pending = append(pending, [2]string{key, value})
+		return nil
+	})
+	if err == nil {
+		err = checkTarget(items)
+	}
+
+	if err != nil || idle {
+		return items, err
+	}
+
+	for _, p := range pending {
+		if err := Set(p[0], p[1]); err != nil {
+			return items, err
+		}
+	}
+
+	return items, nil
+}
+
+// The appendEnv works like marshalEnv but appends the KEY=VALUE items
+// to the dst slice, so the slice can be reused by the Encoder. The
+// values of the fields are set by the set function, if any.
+func appendEnv(dst []string, prefix string, obj interface{},
+	set setFunc) ([]string, error) {
+	result := dst
+	if obj == nil {
+		return result, errors.New("obj should be an initialized struct")
+	}
+
+	// Implements Marshaler interface (by the value or pointer receiver).
+	if m, ok := marshalerOf(reflect.ValueOf(obj)); ok {
+		return appendMarshaler(result, m)
+	}
 
 	// Convert *object to object and mean that we use
 	// reflection on the object but not a pointer on it.
@@ -40,48 +100,23 @@ func marshalEnv(prefix string, obj interface{}, idle bool) ([]string, error) {
 		return result, errors.New("obj should be an initialized struct")
 	}
 
-	// Get a pointer to the object.
-	ptr := reflect.New(rt)
-	ptr.Elem().Set(rv)
-
-	// Implements Marshaler interface.
-	if ptr.Type().Implements(reflect.TypeOf((*Marshaler)(nil)).Elem()) {
-		// Try to run custom MarshalEnv function.
-		if m := ptr.MethodByName("MarshalEnv"); m.IsValid() {
-			tmp := m.Call([]reflect.Value{}) // len == 2
-			if err := tmp[1].Interface(); err != nil {
-				return result, fmt.Errorf("custom marshal method: %v", err)
-			}
+	// Walk through the fields.
+	if result == nil {
+		result = make([]string, 0, rv.NumField())
+	}
 
-			value := tmp[0].Interface()
-			return value.([]string), nil
-		}
+	// The values of the previous fields by the keys with and without
+	// the prefix, for the expansion of the references between them.
+	var fields map[string]string
+	if expandOnMarshal.Load() {
+		fields = make(map[string]string, 2*rv.NumField())
 	}
 
-	// Walk through the fields.
-	result = make([]string, 0, rv.NumField())
 	for i := 0; i < rv.NumField(); i++ {
 		field := rt.Field(i)
 
 		// Get parameters from tags.
-		// The name of the key.
-		key := strings.TrimSpace(field.Tag.Get(tagNameKey))
-		if key == "" {
-			key = field.Name
-		}
-
-		// Separator value for slices/arrays.
-		sep := field.Tag.Get(tagNameSep)
-		if sep == "" {
-			sep = defValueSep
-		}
-
-		// Create tag group.
-		tg := &tagGroup{
-			key:   key,
-			value: field.Tag.Get(tagNameValue),
-			sep:   sep,
-		}
+		tg := newTagGroup(field)
 
 		if !tg.isValid() {
 			return result, fmt.Errorf(
@@ -92,34 +127,82 @@ func marshalEnv(prefix string, obj interface{}, idle bool) ([]string, error) {
 		}
 
 		// Get item.
+		// The field that implements Marshaler is saved by its method.
+		// The template is saved as the source it was compiled from.
 		item := rv.FieldByName(field.Name)
-		if item.Kind() == reflect.Ptr {
+		if m, ok := marshalerOf(item); ok && !hasDecoder(item.Type()) {
+			value, err := appendMarshaler(result, m)
+			if err != nil {
+				return value, err
+			}
+
+			result = value
+			continue
+		} else if isTemplateType(item.Type()) {
+			source, err := templateSource(item)
+			if err != nil {
+				return result, err
+			}
+			item = reflect.ValueOf(source)
+		} else if item.Kind() == reflect.Ptr {
 			item = item.Elem()
 		}
 
 		switch item.Kind() {
 		case reflect.Array, reflect.Slice:
+			// The registered and text types (like net.IP)
+			// are saved as a whole.
+			if hasDecoder(item.Type()) || isTextMarshaler(item.Type()) {
+				value, err := toStr(item)
+				if err != nil {
+					return result, err
+				}
+				tg.value = value
+				break // break switch
+			}
+
 			value, err := getSequence(&item, tg.sep)
 			if err != nil {
 				return result, err
 			}
 			tg.value = value
+		case reflect.Map:
+			if isStructMap(item.Type()) {
+				p := prefix + tg.key + "_"
+				value, err := appendStructMap(result, p, item, set)
+				if err != nil {
+					return value, err
+				}
+
+				result = value
+				continue // the entries are saved by their fields
+			}
+
+			value, err := getMap(item, tg.sep, tg.kvsep)
+			if err != nil {
+				return result, err
+			}
+			tg.value = value
 		case reflect.Struct:
-			// Support for url.URL struct.
-			if u, ok := item.Interface().(url.URL); ok {
-				tg.value = u.String()
+			// Support for url.URL and other value structs.
+			if !isNestedStruct(item.Type()) {
+				value, err := toStr(item)
+				if err != nil {
+					return result, err
+				}
+				tg.value = value
 				break // break switch
 			}
 
 			// Another struct.
 			// Recursive analysis of the nested structure.
-			p := fmt.Sprintf("%s%s_", prefix, tg.key)
-			value, err := marshalEnv(p, item.Interface(), false)
+			p := prefix + tg.key + "_"
+			value, err := appendEnv(result, p, item.Interface(), set)
 			if err != nil {
-				return result, err
+				return value, err
 			}
 
-			result = append(result, value...)
+			result = value
 			continue // value of the recursive field is not to saved
 		default:
 			value, err := toStr(item)
@@ -129,22 +212,81 @@ func marshalEnv(prefix string, obj interface{}, idle bool) ([]string, error) {
 			tg.value = value
 		} // switch
 
+		// Resolve the references to the previous fields.
+		if fields != nil {
+			tg.value = expandFields(tg.value, fields)
+			fields[tg.key] = tg.value
+			fields[prefix+tg.key] = tg.value
+		}
+
 		// Set into environment and add to result list.
-		tg.key = fmt.Sprintf("%s%s", prefix, tg.key)
-		if !idle {
-			// Changes the environment if idle == false only.
-			if err := Set(tg.key, tg.value); err != nil {
+		tg.key = prefix + tg.key
+		if set != nil {
+			// Changes the environment if the set function is given.
+			if err := set(tg.key, tg.value); err != nil {
 				return result, err
 			}
 		}
 
-		result = append(result, fmt.Sprintf("%s=%s", tg.key, tg.value))
+		result = append(result, tg.key+"="+tg.value)
 	} // for
 
 	return result, nil
 }
 
-// The getSequence get sequence as string.
+// The expandFields replaces the references to the fields in the value,
+// the references to other variables are kept as is.
+func expandFields(value string, fields map[string]string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	e := expander{
+		mapping: func(key string) (string, bool) {
+			v, ok := fields[key]
+			return v, ok
+		},
+		keep: true,
+	}
+
+	return e.expand(value)
+}
+
+// The marshalerOf returns the Marshaler of the value. The method can be
+// declared on the value or pointer receiver: the value, its address or
+// the pointer to its copy is checked, so the values and the pointers of
+// the type are marshaled identically. The nil pointer isn't a Marshaler.
+func marshalerOf(v reflect.Value) (Marshaler, bool) {
+	if !v.IsValid() || !v.CanInterface() ||
+		(v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, false
+	}
+
+	if m, ok := v.Interface().(Marshaler); ok {
+		return m, true
+	} else if v.Kind() == reflect.Ptr {
+		return nil, false
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	m, ok := ptr.Interface().(Marshaler)
+	return m, ok
+}
+
+// The appendMarshaler appends the result of the custom
+// MarshalEnv method to the dst slice.
+func appendMarshaler(dst []string, m Marshaler) ([]string, error) {
+	items, err := m.MarshalEnv()
+	if err != nil {
+		return dst, fmt.Errorf("custom marshal method: %v", err)
+	}
+
+	return append(dst, items...), nil
+}
+
+// The getSequence get sequence as string, the items are escaped
+// by the escapeItem to be unmarshaled back as is.
 func getSequence(item *reflect.Value, sep string) (string, error) {
 	var (
 		kind reflect.Kind
@@ -164,8 +306,10 @@ func getSequence(item *reflect.Value, sep string) (string, error) {
 		return "", fmt.Errorf("incorrect type: %s", item.Type())
 	}
 
-	// Use strings.Builder for efficient string concatenation.
-	var sb strings.Builder
+	// Use the pooled buffer for efficient string concatenation.
+	sb := bufferPool.Get().(*bytes.Buffer)
+	sb.Reset()
+	defer bufferPool.Put(sb)
 
 	// For pointers and structures.
 	if kind == reflect.Ptr || kind == reflect.Struct {
@@ -183,7 +327,7 @@ func getSequence(item *reflect.Value, sep string) (string, error) {
 			if i > 0 {
 				sb.WriteString(sep)
 			}
-			sb.WriteString(v)
+			sb.WriteString(escapeItem(v, sep))
 		}
 	} else {
 		for i := 0; i < max; i++ {
@@ -195,32 +339,56 @@ func getSequence(item *reflect.Value, sep string) (string, error) {
 			if i > 0 {
 				sb.WriteString(sep)
 			}
-			sb.WriteString(v)
+			sb.WriteString(escapeItem(v, sep))
 		}
 	}
 
 	return sb.String(), nil
 }
 
-// The toStr converts any item to string.
+// The toStr converts any item to string. The unknown types
+// are converted by the MarshalText method, if any.
 func toStr(item reflect.Value) (string, error) {
+	// The registered types, see RegisterDecoder.
+	if item.IsValid() && hasDecoder(item.Type()) {
+		return encodeValue(item)
+	}
+
+	// The types that implement encoding.TextMarshaler, like net.IP
+	// or a named integer (the known structures are converted below).
+	if item.IsValid() && item.Kind() != reflect.Struct {
+		if text, ok, err := marshalText(item); ok {
+			return text, err
+		}
+	}
+
 	switch item.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16,
 		reflect.Int32, reflect.Int64:
-		return fmt.Sprintf("%d", item.Int()), nil
+		return strconv.FormatInt(item.Int(), 10), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16,
 		reflect.Uint32, reflect.Uint64:
-		return fmt.Sprintf("%d", item.Uint()), nil
+		return strconv.FormatUint(item.Uint(), 10), nil
 	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf("%f", item.Float()), nil
+		return strconv.FormatFloat(item.Float(), 'f', 6, 64), nil
 	case reflect.Bool:
-		return fmt.Sprintf("%t", item.Bool()), nil
+		return strconv.FormatBool(item.Bool()), nil
 	case reflect.String:
 		return item.String(), nil
 	case reflect.Struct:
-		// Support for url.URL struct only.
+		// Support for url.URL and other value structs only.
 		if u, ok := item.Interface().(url.URL); ok {
 			return u.String(), nil
+		} else if s, ok := item.Interface().(SecretString); ok {
+			return s.Value(), nil
+		} else if r, ok := item.Interface().(Range); ok {
+			return r.String(), nil
+		} else if hp, ok := item.Interface().(HostPort); ok {
+			return hp.String(), nil
+		} else if t, ok := item.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		} else if text, ok, err := marshalText(item); ok {
+			return text, err // like netip.Addr or big.Int
 		}
 	}
 
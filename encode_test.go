@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // The configEncode structure with custom MarshalEnv method.
@@ -46,6 +47,18 @@ func (c *configEncodeErr) MarshalEnv() ([]string, error) {
 	return []string{}, errors.New("error message")
 }
 
+// The labelsEncode map with custom MarshalEnv method on the value receiver.
+type labelsEncode map[string]string
+
+// MarshalEnv the custom method for marshalling of the labels.
+func (l labelsEncode) MarshalEnv() ([]string, error) {
+	result := make([]string, 0, len(l))
+	for k, v := range l {
+		result = append(result, "LABEL_"+k+"="+v)
+	}
+	return result, nil
+}
+
 // TestUnmarshalEnvCustomMarshalErr tests custom marshalEnv with error.
 func TestUnmarshalEnvCustomMarshalErr(t *testing.T) {
 	data := configEncodeErr{}
@@ -570,6 +583,43 @@ func TestMarshalEnvSlice(t *testing.T) {
 	}
 }
 
+// TestMarshalEnvTime tests marshaling of the time.Time fields.
+func TestMarshalEnvTime(t *testing.T) {
+	type data struct {
+		Deadline time.Time  `env:"DEADLINE"`
+		Start    *time.Time `env:"START"`
+	}
+
+	var (
+		zone  = time.FixedZone("", 2*60*60)
+		start = time.Date(2025, 1, 1, 0, 0, 0, 5e8, time.UTC)
+		d     = data{time.Date(2025, 6, 30, 18, 0, 0, 0, zone), &start}
+	)
+
+	os.Clearenv()
+	if _, err := marshalEnv("", d, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("DEADLINE"); v != "2025-06-30T18:00:00+02:00" {
+		t.Errorf("expected `2025-06-30T18:00:00+02:00` but `%s`", v)
+	}
+
+	if v := Get("START"); v != "2025-01-01T00:00:00.5Z" {
+		t.Errorf("expected `2025-01-01T00:00:00.5Z` but `%s`", v)
+	}
+
+	// The values are unmarshaled back.
+	var r data
+	if err := unmarshalEnv("", &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.Deadline.Equal(d.Deadline) || !r.Start.Equal(start) {
+		t.Errorf("expected `%v` but `%v`", d, r)
+	}
+}
+
 // TestUnmarshalMultiService tests unmarshaling of the
 // data of environment by the specified prefix.
 func TestUnmarshalMultiService(t *testing.T) {
@@ -601,3 +651,45 @@ func TestUnmarshalMultiService(t *testing.T) {
 		t.Errorf("expected `B` but `%s`", v)
 	}
 }
+
+// TestMarshalEnvReceivers tests marshalEnv function for the custom
+// MarshalEnv methods with the value and pointer receivers.
+func TestMarshalEnvReceivers(t *testing.T) {
+	type config struct {
+		Name   string        `env:"NAME"`
+		Labels labelsEncode  `env:"LABELS"`
+		Ptr    *labelsEncode `env:"PTR"`
+		Server configEncode  `env:"SERVER"`
+	}
+
+	labels := labelsEncode{"A": "1"}
+	data := config{Name: "app", Labels: labels, Ptr: &labels}
+
+	tests := []interface{}{data, &data}
+	for i, obj := range tests {
+		os.Clearenv()
+		items, err := marshalEnv("", obj, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "NAME=app LABEL_A=1 LABEL_A=1 HOST=192.168.0.1 " +
+			"PORT=80 ALLOWED_HOSTS=localhost"
+		if v := strings.Join(items, " "); v != expected {
+			t.Errorf("test %d: expected `%s` but `%s`", i, expected, v)
+		}
+	}
+
+	// The custom types that aren't structures.
+	for i, obj := range []interface{}{labels, &labels} {
+		items, err := marshalEnv("", obj, true)
+		if err != nil || len(items) != 1 || items[0] != "LABEL_A=1" {
+			t.Errorf("test %d: expected `[LABEL_A=1]` but `%v` (%v)",
+				i, items, err)
+		}
+	}
+
+	if _, err := marshalEnv("", nil, true); err == nil {
+		t.Error("should be error for nil object")
+	}
+}
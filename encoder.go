@@ -0,0 +1,75 @@
+package env
+
+// Encoder converts the configuration objects into the KEY=VALUE items
+// (like the result of the Marshal function) without changing the
+// environment. The encoder reuses its memory between calls, so it's
+// useful for the hot paths, for example, to build the environment of
+// each spawned subprocess.
+//
+// The encoder isn't safe for concurrent use, create one encoder for
+// each goroutine or use the sync.Pool.
+type Encoder struct {
+	prefix string
+	items  []string
+}
+
+// NewEncoder returns the encoder that adds the prefix to the keys.
+//
+// # Examples
+//
+//	enc := env.NewEncoder("APP_")
+//	for _, job := range jobs {
+//		items, err := enc.Encode(job.Config)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		cmd := exec.Command(job.Path)
+//		cmd.Env = items
+//		if err := cmd.Start(); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func NewEncoder(prefix string) *Encoder {
+	return &Encoder{prefix: prefix}
+}
+
+// Encode returns the object as the KEY=VALUE items. The obj is a structure
+// or a pointer to a structure with the same tags as for Marshal. The keys
+// are checked against the target platform (see SetTarget).
+//
+// The returned slice is valid until the next call of Encode, use
+// the AppendEncode method to keep the result.
+func (e *Encoder) Encode(obj interface{}) ([]string, error) {
+	items, err := appendEnv(e.items[:0], e.prefix, obj, nil)
+	if err == nil {
+		err = checkTarget(items)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.items = items
+	return items, nil
+}
+
+// AppendEncode appends the KEY=VALUE items of the object to the dst
+// and returns the extended slice, for example, to add the items to
+// the result of the os.Environ function.
+//
+// # Examples
+//
+//	enc := env.NewEncoder("APP_")
+//	cmd := exec.Command("worker")
+//	cmd.Env, err = enc.AppendEncode(os.Environ(), config)
+func (e *Encoder) AppendEncode(dst []string, obj interface{}) ([]string,
+	error) {
+	n := len(dst)
+	items, err := appendEnv(dst, e.prefix, obj, nil)
+	if err == nil {
+		err = checkTarget(items[n:])
+	}
+
+	return items, err
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestEncoderEncode tests reusing of the encoder.
+func TestEncoderEncode(t *testing.T) {
+	type config struct {
+		Host   string   `env:"HOST"`
+		Port   int      `env:"PORT"`
+		IPs    []string `env:"IPS" sep:","`
+		Nested struct {
+			Debug bool `env:"DEBUG"`
+		} `env:"NESTED"`
+	}
+
+	os.Clearenv()
+	enc := NewEncoder("APP_")
+	for _, port := range []int{80, 8080} {
+		data := config{Host: "localhost", Port: port,
+			IPs: []string{"10.0.0.1", "10.0.0.2"}}
+		data.Nested.Debug = true
+
+		expected, err := marshalEnv("APP_", data, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items, err := enc.Encode(&data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(items, expected) {
+			t.Errorf("expected `%v` but `%v`", expected, items)
+		}
+	}
+
+	// The environment isn't changed, including nested structures.
+	if environ := os.Environ(); len(environ) != 0 {
+		t.Errorf("expected empty environment but `%v`", environ)
+	}
+
+	if _, err := enc.Encode(1); err == nil {
+		t.Error("expected an error for not a struct")
+	}
+}
+
+// TestEncoderAppendEncode tests appending items to the slice.
+func TestEncoderAppendEncode(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+	}
+
+	enc := NewEncoder("")
+	items, err := enc.AppendEncode([]string{"A=1"}, config{Host: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"A=1", "HOST=x"}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, items)
+	}
+}
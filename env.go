@@ -2,9 +2,15 @@ package env
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 )
 
 const (
@@ -18,10 +24,51 @@ const (
 	// of the items in the string of value.
 	tagNameSep = "sep"
 
+	// The tagNameRequired the identifier of the tag that marks the key
+	// as required (the key must be set in the environment or must have
+	// a default value).
+	tagNameRequired = "required"
+
+	// The tagNameSecret the identifier of the tag that marks the value
+	// as secret (it is masked when the configuration is displayed).
+	tagNameSecret = "secret"
+
+	// The tagNameDesc the identifier of the tag that sets the description
+	// of the key (for generated documentation and shell completion).
+	tagNameDesc = "desc"
+
+	// The tagNameDecimal the identifier of the tag that sets the decimal
+	// separator of the float values, like "," for 3,14.
+	tagNameDecimal = "decimal"
+
+	// The tagNamePath the identifier of the tag that marks the string
+	// value as the filesystem path and sets its options (see PathError).
+	tagNamePath = "path"
+
+	// The tagNameDelims the identifier of the tag that sets the left and
+	// right delimiters of the template fields, like "[[ ]]".
+	tagNameDelims = "delims"
+
+	// The tagNameSource the identifier of the tag that pins the field
+	// to the named source (see UnmarshalSources).
+	tagNameSource = "source"
+
+	// The tagNameKVSep the identifier of the tag that sets the separator
+	// of the keys and values of the map items, like "=" for a=1.
+	tagNameKVSep = "kvsep"
+
 	// The defValueSep is the default separator of the items
 	// in the string of value.
 	defValueSep = " "
 
+	// The defKVSep is the default separator of the keys
+	// and values of the map items.
+	defKVSep = "="
+
+	// The keyAliasSep separates the fallback names of the key
+	// in the tagNameKey tag, like env:"NEW_NAME|OLD_NAME".
+	keyAliasSep = "|"
+
 	// The defValueIgnored is the value of the tagNameKey field that
 	// should be ignored during processing.
 	defValueIgnored = "-"
@@ -83,8 +130,15 @@ func ParallelTasks(pt int) int {
 // into environment. Handles variables like ${var} or $var in the value,
 // replacing them with a real result.
 //
+// Several env-files are loaded in the given order, so the first file
+// that sets the key wins (as the existing keys aren't updated), like
+// Load(".env.local", ".env") gives the priority to the .env.local file.
+// The values of the previous files can be used in the expansion.
+//
 // Returns an error if the env-file contains incorrect data,
-// file is damaged or missing.
+// file is damaged or missing. The loading stops on the first
+// failed file, the keys of the previous files are kept.
+// Returns ErrNoFiles if no env-files are given.
 //
 // Examples:
 //
@@ -121,17 +175,22 @@ func ParallelTasks(pt int) int {
 //   - KEY_1 - loaded new value;
 //   - KEY_2 - loaded new value and replaced ${LAST_ID}
 //     to the value from environment.
-func Load(filename string) error {
+func Load(filenames ...string) error {
 	expand, update, forced := true, false, false
-	return readParseStore(filename, expand, update, forced)
+	return readParseStoreAll(filenames, expand, update, forced)
 }
 
 // LoadSafe loads new keys only (without updating existing keys) from env-file
 // into environment. Doesn't handles variables like ${var} or $var -
 // doesn't turn them into a finite value.
 //
+// Several env-files are loaded in the given order, so the first file
+// that sets the key wins, like Load does it.
+//
 // Returns an error if the env-file contains incorrect data,
-// file is damaged or missing.
+// file is damaged or missing. The loading stops on the first
+// failed file, the keys of the previous files are kept.
+// Returns ErrNoFiles if no env-files are given.
 //
 // # Examples
 //
@@ -168,17 +227,23 @@ func Load(filename string) error {
 //   - KEY_1 - loaded new value;
 //   - KEY_2 - loaded new value but doesn't replace ${LAST_ID}
 //     to the value from environment.
-func LoadSafe(filename string) error {
+func LoadSafe(filenames ...string) error {
 	expand, update, forced := false, false, false
-	return readParseStore(filename, expand, update, forced)
+	return readParseStoreAll(filenames, expand, update, forced)
 }
 
 // Update loads keys from the env-file into environment, update existing keys.
 // Handles variables like ${var} or $var in the value,
 // replacing them with a real result.
 //
+// Several env-files are loaded in the given order, so the last file
+// that sets the key wins, like Update(".env", ".env.local") gives the
+// priority to the .env.local file.
+//
 // Returns an error if the env-file contains incorrect data,
-// file is damaged or missing.
+// file is damaged or missing. The loading stops on the first
+// failed file, the keys of the previous files are kept.
+// Returns ErrNoFiles if no env-files are given.
 //
 // # Examples
 //
@@ -215,17 +280,22 @@ func LoadSafe(filename string) error {
 //   - KEY_1 - loaded new value;
 //   - KEY_2 - loaded new value and replaced ${LAST_ID}
 //     to the value from environment.
-func Update(filename string) error {
+func Update(filenames ...string) error {
 	expand, update, forced := true, true, false
-	return readParseStore(filename, expand, update, forced)
+	return readParseStoreAll(filenames, expand, update, forced)
 }
 
 // UpdateSafe loads keys from the env-file into environment,
 // update existing keys. Doesn't handles variables like ${var} or $var -
 // doesn't turn them into a finite value.
 //
+// Several env-files are loaded in the given order, so the last file
+// that sets the key wins, like Update does it.
+//
 // Returns an error if the env-file contains incorrect data,
-// file is damaged or missing.
+// file is damaged or missing. The loading stops on the first
+// failed file, the keys of the previous files are kept.
+// Returns ErrNoFiles if no env-files are given.
 //
 // # Examples
 //
@@ -262,12 +332,211 @@ func Update(filename string) error {
 //   - KEY_1 - loaded new value;
 //   - KEY_2 - loaded new value but doesn't replace ${LAST_ID}
 //     to the value from environment.
-func UpdateSafe(filename string) error {
+func UpdateSafe(filenames ...string) error {
 	expand, update, forced := false, true, false
-	return readParseStore(filename, expand, update, forced)
+	return readParseStoreAll(filenames, expand, update, forced)
+}
+
+// LoadReader loads the keys of the env-file read from the r into
+// environment, like Load (if update is false) or Update (if update is
+// true) do it for the file: the variables like ${var} or $var in the
+// values are expanded. It allows to load the env content generated in
+// memory or fetched from the network without writing a temporary file.
+//
+// The stats of the loading have the empty file name (see LastLoadStats).
+// Returns an error if the content contains incorrect data or can't be
+// read.
+//
+// # Examples
+//
+//	resp, err := http.Get("https://config/app.env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer resp.Body.Close()
+//
+//	if err := env.LoadReader(resp.Body, true); err != nil {
+//		log.Fatal(err)
+//	}
+func LoadReader(r io.Reader, update bool) error {
+	return parseStore("", r, loadOptions{expand: true, update: update})
+}
+
+// LoadBytes works like LoadReader, but takes the content of the env-file
+// from the data.
+//
+// # Examples
+//
+//	err := env.LoadBytes([]byte("HOST=localhost\nPORT=8080\n"), false)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func LoadBytes(data []byte, update bool) error {
+	return LoadReader(bytes.NewReader(data), update)
+}
+
+// Parse reads and parses the env-file like Update does, but returns the
+// keys and values as a map instead of storing them into environment:
+// the quotes, comments and the export keyword are handled in the same
+// way, the variables like ${var} or $var in the values are expanded by
+// the previous keys of the file and by the environment, the last value
+// of the repeated key wins. The environment isn't changed, so the
+// content can be inspected, filtered or merged before applying it
+// (see ApplyMap).
+//
+// Returns an error if the env-file contains incorrect data,
+// doesn't exist or can't be read.
+//
+// # Examples
+//
+//	values, err := env.Parse(".env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	delete(values, "DEBUG")
+//	if err := env.ApplyMap(values, true); err != nil {
+//		log.Fatal(err)
+//	}
+func Parse(filename string) (map[string]string, error) {
+	expand, forced := true, false
+	pairs, _, err := readParse(filename, expand, forced)
+	if err != nil {
+		return nil, err
+	}
+
+	return expandPairs(pairs)
+}
+
+// ParseReader works like Parse, but reads the content
+// of the env-file from the r.
+//
+// # Examples
+//
+//	values, err := env.ParseReader(strings.NewReader("PORT=8080"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(values["PORT"]) // 8080
+func ParseReader(r io.Reader) (map[string]string, error) {
+	expand, forced := true, false
+	pairs, _, err := parseReader("", r, expand, forced)
+	if err != nil {
+		return nil, err
+	}
+
+	return expandPairs(pairs)
+}
+
+// ApplyMap stores the key/value pairs from the map into environment.
+// If update is true, existing keys are overwritten, otherwise only new
+// keys are set. The values are stored as is, without expanding variables
+// like ${var} or $var.
+//
+// The keys are applied in sorted order, so that the result and the
+// error (if any) are deterministic. The function can be used as a
+// low-level primitive by tools that get values from other sources.
+//
+// # Examples
+//
+//	err := env.ApplyMap(map[string]string{
+//		"HOST": "0.0.0.0",
+//		"PORT": "8080",
+//	}, true)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func ApplyMap(values map[string]string, update bool) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]pair, len(keys))
+	for i, key := range keys {
+		pairs[i] = pair{key: key, value: values[key]}
+	}
+
+	return applyPairs(pairs, false, update)
+}
+
+// SetMap sets all key/value pairs from the map into environment as a
+// single all-or-nothing operation: if any key can't be set, the keys
+// that have already been set are restored to their previous state.
+//
+// If validate is true, the keys are checked before the changes: a key
+// must start with a letter or underscore and contain only letters,
+// digits and underscores, and a value must not contain the NUL byte.
+//
+// The error contains a *KeyError for each incorrect key.
+//
+// # Examples
+//
+//	err := env.SetMap(map[string]string{
+//		"HOST":   "0.0.0.0",
+//		"1_PORT": "8080", // incorrect key
+//	}, true)
+//	if err != nil {
+//		log.Fatal(err) // 1_PORT: invalid key name
+//	}
+func SetMap(values map[string]string, validate bool) error {
+	if frozen.Load() {
+		return ErrFrozen
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Validate all pairs before changing the environment.
+	if validate {
+		var errs []error
+		for _, key := range keys {
+			if !validKeyRgx.MatchString(key) {
+				errs = append(errs, &KeyError{
+					Key: key,
+					Err: errors.New("invalid key name"),
+				})
+			} else if strings.ContainsRune(values[key], 0) {
+				errs = append(errs, &KeyError{
+					Key: key,
+					Err: errors.New("value contains NUL byte"),
+				})
+			}
+		}
+
+		if len(errs) != 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	// Apply the values and roll back on error.
+	previous := make([]rawValue, 0, len(keys))
+	for i, key := range keys {
+		old, ok := getenv(key)
+		previous = append(previous, rawValue{value: old, ok: ok})
+
+		if err := setenv(key, values[key]); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if previous[j].ok {
+					setenv(keys[j], previous[j].value)
+				} else {
+					unsetenv(keys[j])
+				}
+			}
+
+			return &KeyError{Key: key, Err: err}
+		}
+	}
+
+	return nil
 }
 
 // Save saves the object to a file without changing the environment.
+// The values are quoted if needed to be loaded back as is (see Quote).
 //
 // # Example
 //
@@ -303,13 +572,197 @@ func Save(filename, prefix string, obj interface{}) error {
 	}
 
 	for _, item := range items {
-		result.WriteString(item)
+		result.WriteString(quoteItem(item))
+		result.WriteString("\n")
+	}
+
+	return os.WriteFile(filename, result.Bytes(), 0o644)
+}
+
+// SaveCommented works like Save, but writes the comment line with
+// the description from the desc tag above each key, and the keys of
+// the nested structures are grouped under the section headers like
+// # --- DB --- (the key of the nested structure without the prefix).
+//
+// # Example
+//
+//	type Config struct {
+//		Host string `env:"HOST" desc:"server host"`
+//		DB   struct {
+//			Name string `env:"NAME" desc:"database name"`
+//		} `env:"DB"`
+//	}
+//
+//	env.SaveCommented("/tmp/.env", "APP_", config)
+//
+// The result in the file /tmp/.env
+//
+//	# server host
+//	APP_HOST=localhost
+//
+//	# --- DB ---
+//	# database name
+//	APP_DB_NAME=main
+func SaveCommented(filename, prefix string, obj interface{}) error {
+	var result bytes.Buffer
+
+	items, err := marshalEnv(prefix, obj, true) // don't change environment
+	if err != nil {
+		return err
+	}
+
+	// The descriptions and sections of the keys.
+	descs, sections := map[string]string{}, map[string]string{}
+	if t := reflect.TypeOf(obj); t != nil {
+		walkFields(prefix, t, func(fi fieldInfo) error {
+			section := strings.TrimSuffix(fi.tg.key,
+				newTagGroup(fi.field).key)
+			section = strings.TrimPrefix(section, prefix)
+			descs[fi.tg.key] = fi.tg.desc
+			sections[fi.tg.key] = strings.TrimSuffix(section, "_")
+			return nil
+		})
+	}
+
+	section := ""
+	for _, item := range items {
+		key, _, _ := strings.Cut(item, "=")
+		if s, ok := sections[key]; ok && s != section {
+			section = s
+			if result.Len() != 0 {
+				result.WriteString("\n")
+			}
+
+			if s != "" {
+				result.WriteString("# --- " + s + " ---\n")
+			}
+		}
+
+		if desc := descs[key]; desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				result.WriteString(strings.TrimSpace("# " + line))
+				result.WriteString("\n")
+			}
+		}
+
+		result.WriteString(quoteItem(item))
 		result.WriteString("\n")
 	}
 
 	return os.WriteFile(filename, result.Bytes(), 0o644)
 }
 
+// SaveMerge saves the object to the existing file without changing the
+// environment, like Save, but only the keys of the object are written:
+// the lines with these keys are updated in place (the export prefix,
+// the inline comment and the line ending are kept), the missing keys
+// are appended to the end of the file, and all other lines (unrelated
+// keys, comments and empty lines) stay intact.
+// The file is created if it doesn't exist.
+//
+// It's useful for applications that own a subset of the keys
+// of the shared configuration file.
+//
+// # Example
+//
+// The file /tmp/.env contains:
+//
+//	# shared settings
+//	DEBUG=true
+//	export HOST=0.0.0.0
+//
+// Save the configuration with the HOST and PORT keys:
+//
+//	env.SaveMerge("/tmp/.env", "", config)
+//
+// The result in the file /tmp/.env
+//
+//	# shared settings
+//	DEBUG=true
+//	export HOST=localhost
+//	PORT=8080
+func SaveMerge(filename, prefix string, obj interface{}) error {
+	items, err := marshalEnv(prefix, obj, true) // don't change environment
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0o644)
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		data = nil
+	} else if err != nil {
+		return err
+	} else if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode()
+	}
+
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		key, value, _ := strings.Cut(item, "=")
+		values[key] = Quote(value, QuoteAuto)
+	}
+
+	var lines []string
+	if text := strings.TrimSuffix(string(data), "\n"); text != "" {
+		lines = strings.Split(text, "\n")
+	}
+
+	// The line ending of the file is kept for the new lines too.
+	eol := ""
+	if len(lines) != 0 && strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+
+	// Update the existing keys.
+	written := make(map[string]bool, len(items))
+	result := make([]string, 0, len(lines)+len(items))
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		tmp := keyRgx.FindStringSubmatch(line)
+		value, ok := "", false
+		if !isEmpty(line) && len(tmp) > 1 {
+			value, ok = values[tmp[1]]
+		}
+
+		if !ok {
+			result = append(result, lines[i])
+			continue
+		}
+
+		// Skip the lines of the line continuation too,
+		// the inline comment is taken from the whole expression.
+		start, exp := i, line
+		continued := isUnquotedExpression(line)
+		for continued && isContinued(line) && i+1 < len(lines) &&
+			isContinuation(strings.TrimSuffix(lines[i+1], "\r")) {
+			i++
+			line = strings.TrimSuffix(lines[i], "\r")
+			exp = exp[:len(exp)-1] + strings.TrimLeft(line, " \t")
+		}
+
+		line = tmp[0] + value + inlineComment(exp)
+		if strings.HasSuffix(lines[start], "\r") {
+			line += "\r"
+		}
+
+		result = append(result, line)
+		written[tmp[1]] = true
+	}
+	lines = result
+
+	// Append the missing keys.
+	for _, item := range items {
+		if key, _, _ := strings.Cut(item, "="); !written[key] {
+			lines = append(lines, quoteItem(item)+eol)
+			written[key] = true
+		}
+	}
+
+	data = []byte(strings.Join(lines, "\n") + "\n")
+	return os.WriteFile(filename, data, mode)
+}
+
 // Exists returns true if all given keys exists in the environment.
 //
 // # Examples
@@ -350,13 +803,50 @@ func Save(filename, prefix string, obj interface{}) error {
 //	//  KEY_0 and KEY_1 is true
 func Exists(keys ...string) bool {
 	for _, key := range keys {
-		if _, ok := os.LookupEnv(key); !ok {
+		if _, ok := getenv(key); !ok {
 			return false
 		}
 	}
 	return true
 }
 
+// ExistsAny returns true if at least one of the specified keys exists
+// in the environment. Returns false if no keys are specified.
+//
+// # Examples
+//
+//	// Either REDIS_URL or REDIS_HOST is enough.
+//	if !env.ExistsAny("REDIS_URL", "REDIS_HOST") {
+//		log.Fatal("redis isn't configured")
+//	}
+func ExistsAny(keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := getenv(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExistsPrefix returns true if at least one key of the environment
+// starts with the specified prefix. The empty prefix matches any key.
+//
+// # Examples
+//
+//	// Any of REDIS_HOST, REDIS_PORT, ... is set.
+//	if env.ExistsPrefix("REDIS_") {
+//		// ...
+//	}
+func ExistsPrefix(prefix string) bool {
+	for _, pair := range environ() {
+		key, _, _ := strings.Cut(pair, "=")
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Unmarshal parses data from the environment and store result into
 // Go-structure that passed by pointer. If the obj isn't a pointer to
 // struct or has fields of unsupported types will be returned an error.
@@ -366,17 +856,29 @@ func Exists(keys ...string) bool {
 // struct, url.URL and pointers, array or slice from thous types (i.e. *int,
 // *uint, ..., []int, ..., []bool, ..., [2]*url.URL, etc.). The fields as
 // a struct or pointer on the struct will be processed recursively.
+// The fields of the types that implement encoding.TextUnmarshaler (like
+// netip.Addr, net.IP or uuid.UUID) are set by the UnmarshalText method,
+// the empty value gives the zero value.
 //
 // If the structure implements Unmarshaler interface -
-// the custom UnmarshalEnv method will be called.
+// the custom UnmarshalEnv method will be called instead. If it
+// implements PartialUnmarshaler interface - the UnmarshalEnvPartial
+// method will be called after the fields are unmarshaled.
 //
 // Use the following tags in the fields of structure to
 // set the unmarshing parameters:
 //
-//	env  matches the name of the key in the environment;
+//	env  matches the name of the key in the environment, the fallback
+//	     names can follow the key separated by |, like NEW|OLD;
 //	def  default value (if empty, sets the default value
 //	     for the field type of structure);
-//	sep  sets the separator for lists/arrays (default ` ` - space).
+//	sep  sets the separator for lists/arrays (default ` ` - space);
+//	required  if true, the key must be set in the environment or
+//	     have a default value, otherwise ErrRequired is returned.
+//	secret  if true, the value is masked when the configuration
+//	     is displayed (see Redacted, DebugHandler).
+//	desc  description of the key for generated documentation
+//	     and shell completion (see Completion).
 //
 // # Examples
 //
@@ -433,6 +935,58 @@ func Unmarshal(prefix string, obj interface{}) error {
 	return unmarshalEnv(prefix, obj)
 }
 
+// UnmarshalAll discovers all prefixes in the environment that match the
+// pattern and unmarshals one object per discovered prefix. The pattern
+// must contain exactly one asterisk (*) that matches the name of the
+// instance (letters and digits, without underscores), like SERVICE_*_.
+//
+// The newT function must return a new pointer to the structure for each
+// instance. The result is a map from the instance name to the object.
+//
+// # Examples
+//
+// Some keys was set into environment as:
+//
+//	$ export SERVICE_A_HOST=127.0.0.1
+//	$ export SERVICE_A_PORT=8081
+//	$ export SERVICE_B_HOST=localhost
+//	$ export SERVICE_B_PORT=8082
+//
+// Unmarshal all services:
+//
+//	services, err := env.UnmarshalAll("SERVICE_*_", func() interface{} {
+//		return &Service{}
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	fmt.Println(services["A"].(*Service).Port)
+//	fmt.Println(services["B"].(*Service).Port)
+//	// Output:
+//	//  8081
+//	//  8082
+func UnmarshalAll(pattern string,
+	newT func() interface{}) (map[string]interface{}, error) {
+	names, err := findPrefixNames(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		prefix := strings.Replace(pattern, "*", name, 1)
+		obj := newT()
+		if err := unmarshalEnv(prefix, obj); err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix, err)
+		}
+
+		result[name] = obj
+	}
+
+	return result, nil
+}
+
 // Marshal converts the structure in to key/value and put it into environment
 // with update old values. As the first value returns a list of keys that
 // were correctly sets in the environment and nil or error information
@@ -446,9 +1000,14 @@ func Unmarshal(prefix string, obj interface{}) error {
 // struct, url.URL and pointers, array or slice from thous types (i.e. *int,
 // *uint, ..., []int, ..., []bool, ..., [2]*url.URL, etc.). The fields as
 // a struct or pointer on the struct will be processed recursively.
+// The fields of the types that implement encoding.TextMarshaler (like
+// netip.Addr, net.IP or uuid.UUID) are converted by the MarshalText
+// method, so the types that implement encoding.TextUnmarshaler too
+// can be loaded back.
 //
 // If the structure implements Marshaler interface - the custom MarshalEnv
-// method will be called.
+// method will be called. The method can be declared on the value or
+// pointer receiver, the same is true for the fields of the structure.
 //
 // Use the following tags in the fields of structure to
 // set the marshing parameters:
@@ -1,8 +1,11 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -101,6 +104,107 @@ func TestUpdateSafe(t *testing.T) {
 	}
 }
 
+// TestLoadDefaults tests the default values of the variables in
+// the env-file, like ${VAR:-default}.
+func TestLoadDefaults(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("EMPTY", "")
+	data := "HOST=${HOST:-localhost}\nPORT=${EMPTY:-8080}\n" +
+		"ADDR=\"${HOST}:${PORT}\"\nDB=${DB_HOST:-${HOST}}/${DB_NAME-app}\n"
+	if err := LoadBytes([]byte(data), false); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"HOST": "localhost",
+		"PORT": "8080",
+		"ADDR": "localhost:8080",
+		"DB":   "localhost/app",
+	}
+	for key, value := range tests {
+		if v := Get(key); v != value {
+			t.Errorf("expected `%s` but `%s`", value, v)
+		}
+	}
+
+	// The same for the Parse function.
+	values, err := ParseReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := values["DB"]; v != "localhost/app" {
+		t.Errorf("expected `localhost/app` but `%s`", v)
+	}
+}
+
+// TestLoadMultiple tests Load and Update functions with several files.
+func TestLoadMultiple(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, ".env")
+	second := filepath.Join(dir, ".env.local")
+	os.WriteFile(first, []byte("HOST=localhost\nPORT=80\n"), 0o600)
+	os.WriteFile(second, []byte("PORT=8080\nADDR=${HOST}:${PORT}\n"),
+		0o600)
+
+	// The first file wins.
+	os.Clearenv()
+	if err := Load(first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("PORT"); v != "80" {
+		t.Errorf("expected `80` but `%s`", v)
+	}
+
+	if v := Get("ADDR"); v != "localhost:80" {
+		t.Errorf("expected `localhost:80` but `%s`", v)
+	}
+
+	// The last file wins.
+	os.Clearenv()
+	if err := Update(first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("ADDR"); v != "localhost:8080" {
+		t.Errorf("expected `localhost:8080` but `%s`", v)
+	}
+
+	// The loading stops on the missing file.
+	os.Clearenv()
+	if err := Update(first, filepath.Join(dir, "missing"), second); err == nil {
+		t.Error("an error is expected for the missing file")
+	}
+
+	if v := Get("HOST"); v != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", v)
+	}
+
+	if v, ok := Lookup("ADDR"); ok {
+		t.Errorf("expected unset ADDR but `%s`", v)
+	}
+
+	// The safe functions load several files too.
+	os.Clearenv()
+	if err := UpdateSafe(first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("ADDR"); v != "${HOST}:${PORT}" {
+		t.Errorf("expected `${HOST}:${PORT}` but `%s`", v)
+	}
+
+	// The file names are required.
+	for _, fn := range []func(...string) error{
+		Load, LoadSafe, Update, UpdateSafe,
+	} {
+		if err := fn(); !errors.Is(err, ErrNoFiles) {
+			t.Errorf("expected ErrNoFiles but `%v`", err)
+		}
+	}
+}
+
 // TestExists tests Exists function.
 func TestExist(t *testing.T) {
 	tests := [][]string{
@@ -159,3 +263,414 @@ func TestSave(t *testing.T) {
 		t.Errorf("expected `%d` but `%s`", data.Port, fmt.Sprint(data.Port))
 	}
 }
+
+// TestSaveCommented tests SaveCommented function.
+func TestSaveCommented(t *testing.T) {
+	type pool struct {
+		Size int `env:"SIZE" desc:"pool size"`
+	}
+
+	data := struct {
+		Host string `env:"HOST" desc:"server host"`
+		Port int    `env:"PORT"`
+		DB   struct {
+			Name string `env:"NAME" desc:"database name\nor path"`
+			Pool pool   `env:"POOL"`
+		} `env:"DB"`
+		Debug bool `env:"DEBUG" desc:"debug mode"`
+	}{Host: "localhost", Port: 8080}
+	data.DB.Name = "main"
+	data.DB.Pool.Size = 4
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := SaveCommented(filename, "APP_", data); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# server host\nAPP_HOST=localhost\nAPP_PORT=8080\n\n" +
+		"# --- DB ---\n# database name\n# or path\nAPP_DB_NAME=main\n\n" +
+		"# --- DB_POOL ---\n# pool size\nAPP_DB_POOL_SIZE=4\n\n" +
+		"# debug mode\nAPP_DEBUG=false\n"
+	if v := string(content); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// The file can be loaded.
+	os.Clearenv()
+	if err := Load(filename); err != nil {
+		t.Error(err)
+	}
+
+	if v := Get("APP_DB_POOL_SIZE"); v != "4" {
+		t.Errorf("expected `4` but `%s`", v)
+	}
+}
+
+// TestSaveMerge tests SaveMerge function.
+func TestSaveMerge(t *testing.T) {
+	data := struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}{Host: "localhost", Port: 8080}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := "# shared settings\nDEBUG=true\n\nexport APP_HOST=0.0.0.0 " +
+		"# host\nAPP_HOST=127.0.0.1\nAPP_HOSTS=a\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	if err := SaveMerge(filename, "APP_", data); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("APP_HOST"); v != "" {
+		t.Errorf("doesn't have to change the environment: `%s`", v)
+	}
+
+	result, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# shared settings\nDEBUG=true\n\nexport APP_HOST=localhost" +
+		" # host\nAPP_HOST=localhost\nAPP_HOSTS=a\nAPP_PORT=8080\n"
+	if v := string(result); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	if info, err := os.Stat(filename); err != nil ||
+		info.Mode().Perm() != 0o600 {
+		t.Errorf("the file mode is changed: %v", info.Mode())
+	}
+
+	// The line endings and the inline comments are kept.
+	content = "HOST='old' # expires=2025-01-01\r\nDEBUG=true\r\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveMerge(filename, "", data); err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ = os.ReadFile(filename)
+	expected = "HOST=localhost # expires=2025-01-01\r\nDEBUG=true\r\n" +
+		"PORT=8080\r\n"
+	if v := string(result); v != expected {
+		t.Errorf("expected %q but %q", expected, v)
+	}
+
+	// The new file.
+	filename = filepath.Join(t.TempDir(), ".env")
+	if err := SaveMerge(filename, "", data); err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ = os.ReadFile(filename)
+	if v := string(result); v != "HOST=localhost\nPORT=8080\n" {
+		t.Errorf("expected `HOST=localhost\\nPORT=8080\\n` but `%s`", v)
+	}
+}
+
+// TestSaveMergeContinuation tests SaveMerge function
+// with the continued values.
+func TestSaveMergeContinuation(t *testing.T) {
+	data := struct {
+		A string `env:"A"`
+	}{A: "new"}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := "A=one \\\n  two # comment\nB=x \\\n  y\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveMerge(filename, "", data); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "A=new # comment\nB=x \\\n  y\n"
+	if v := string(result); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	pairs, err := Parse(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pairs["A"] != "new" || pairs["B"] != "x y" {
+		t.Errorf("unexpected values: %v", pairs)
+	}
+}
+
+// TestLoadReader tests LoadReader and LoadBytes functions.
+func TestLoadReader(t *testing.T) {
+	data := "HOST=localhost\nPORT=8080\nADDR=${HOST}:${PORT}\n"
+
+	// Don't update existing keys.
+	os.Clearenv()
+	os.Setenv("PORT", "80")
+	if err := LoadReader(strings.NewReader(data), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("PORT"); v != "80" {
+		t.Errorf("expected `80` but `%s`", v)
+	}
+
+	if v := Get("ADDR"); v != "localhost:80" {
+		t.Errorf("expected `localhost:80` but `%s`", v)
+	}
+
+	// Update existing keys.
+	if err := LoadBytes([]byte(data), true); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("ADDR"); v != "localhost:8080" {
+		t.Errorf("expected `localhost:8080` but `%s`", v)
+	}
+
+	// Incorrect content.
+	if err := LoadBytes([]byte("HOST\n"), true); err == nil {
+		t.Error("an error is expected for the incorrect expression")
+	}
+}
+
+// TestParse tests Parse and ParseReader functions.
+func TestParse(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "0.0.0.0")
+	os.Setenv("USER", "goloop")
+
+	data := "export PORT=80\nPORT='8080' # comment\n" +
+		"ADDR=${HOST}:${PORT}\nHOME=/home/${USER}\n"
+	values, err := ParseReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"PORT": "8080",
+		"ADDR": "0.0.0.0:8080",
+		"HOME": "/home/goloop",
+	}
+	if len(values) != len(expected) {
+		t.Errorf("expected %d keys but %d", len(expected), len(values))
+	}
+
+	for key, value := range expected {
+		if v := values[key]; v != value {
+			t.Errorf("expected `%s` but `%s`", value, v)
+		}
+	}
+
+	// The environment isn't changed.
+	if v, ok := Lookup("PORT"); ok {
+		t.Errorf("expected unset PORT but `%s`", v)
+	}
+
+	// From the file.
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err = Parse(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := values["ADDR"]; v != "0.0.0.0:8080" {
+		t.Errorf("expected `0.0.0.0:8080` but `%s`", v)
+	}
+
+	// Incorrect content and missing file.
+	if _, err := ParseReader(strings.NewReader("PORT\n")); err == nil {
+		t.Error("an error is expected for the incorrect expression")
+	}
+
+	if _, err := Parse(filename + ".missing"); err == nil {
+		t.Error("an error is expected for the missing file")
+	}
+}
+
+// TestApplyMap tests ApplyMap function.
+func TestApplyMap(t *testing.T) {
+	values := map[string]string{
+		"KEY_0": "value_0",
+		"KEY_1": "${KEY_0}",
+	}
+
+	// Don't update existing keys.
+	os.Clearenv()
+	os.Setenv("KEY_0", "default")
+	if err := ApplyMap(values, false); err != nil {
+		t.Error(err)
+	}
+
+	if v := Get("KEY_0"); v != "default" {
+		t.Errorf("expected `default` but `%s`", v)
+	}
+
+	if v := Get("KEY_1"); v != "${KEY_0}" {
+		t.Errorf("expected `${KEY_0}` but `%s`", v)
+	}
+
+	// Update existing keys.
+	if err := ApplyMap(values, true); err != nil {
+		t.Error(err)
+	}
+
+	if v := Get("KEY_0"); v != "value_0" {
+		t.Errorf("expected `value_0` but `%s`", v)
+	}
+
+	// Incorrect key.
+	if err := ApplyMap(map[string]string{"": "value"}, true); err == nil {
+		t.Error("an error is expected for the empty key")
+	}
+}
+
+// TestUnmarshalAll tests UnmarshalAll function.
+func TestUnmarshalAll(t *testing.T) {
+	type server struct {
+		Name string `env:"NAME"`
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	os.Clearenv()
+	os.Setenv("SERVICE_PORT", "80") // doesn't match the pattern
+	if err := Load("./fixtures/multiservice.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	newT := func() interface{} { return &server{} }
+	services, err := UnmarshalAll("SERVICE_*_", newT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services but %d", len(services))
+	}
+
+	for name, port := range map[string]int{"A": 8081, "B": 8082} {
+		s, ok := services[name].(*server)
+		if !ok {
+			t.Errorf("the %s service isn't found", name)
+			continue
+		}
+
+		if s.Name != name || s.Port != port {
+			t.Errorf("incorrect %s service: %v", name, s)
+		}
+	}
+
+	// Incorrect pattern.
+	if _, err := UnmarshalAll("SERVICE_", newT); err == nil {
+		t.Error("an error is expected for the pattern without asterisk")
+	}
+
+	// Incorrect value.
+	os.Setenv("SERVICE_C_PORT", "abc")
+	if _, err := UnmarshalAll("SERVICE_*_", newT); err == nil {
+		t.Error("an error is expected for incorrect value")
+	}
+}
+
+// TestSetMap tests SetMap function.
+func TestSetMap(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("KEY_0", "default")
+
+	// Correct values.
+	err := SetMap(map[string]string{"KEY_0": "a", "KEY_1": "b"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if Get("KEY_0") != "a" || Get("KEY_1") != "b" {
+		t.Error("the values weren't set")
+	}
+
+	// Validation errors for each key.
+	err = SetMap(map[string]string{
+		"KEY_0":  "c",
+		"1_KEY":  "d",
+		"KEY-2":  "e",
+		"KEY_3":  "f\x00",
+		"_KEY_4": "g",
+	}, true)
+
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("expected KeyError but %v", err)
+	}
+
+	for _, key := range []string{"1_KEY", "KEY-2", "KEY_3"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("the error for %s key is expected: %v", key, err)
+		}
+	}
+
+	if Get("KEY_0") != "a" || Exists("_KEY_4") {
+		t.Error("the environment shouldn't be changed")
+	}
+
+	// Without validation, the changes are rolled back.
+	err = SetMap(map[string]string{"A": "1", "B=": "2", "KEY_0": "3"}, false)
+	if err == nil {
+		t.Fatal("an error is expected for incorrect key")
+	}
+
+	if Exists("A") || Get("KEY_0") != "a" {
+		t.Error("the changes should be rolled back")
+	}
+}
+
+// TestExistsAny tests ExistsAny function.
+func TestExistsAny(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("REDIS_HOST", "localhost")
+
+	if !ExistsAny("REDIS_URL", "REDIS_HOST") {
+		t.Error("expected true but false")
+	}
+
+	if ExistsAny("REDIS_URL", "REDIS_PORT") {
+		t.Error("expected false but true")
+	}
+
+	if ExistsAny() {
+		t.Error("expected false for empty keys")
+	}
+}
+
+// TestExistsPrefix tests ExistsPrefix function.
+func TestExistsPrefix(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("REDIS_HOST", "localhost")
+
+	if !ExistsPrefix("REDIS_") {
+		t.Error("expected true but false")
+	}
+
+	if ExistsPrefix("MYSQL_") || ExistsPrefix("HOST") {
+		t.Error("expected false but true")
+	}
+}
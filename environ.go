@@ -0,0 +1,98 @@
+package env
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Filter selects and transforms the items of the environment for the
+// EnvironSorted function. It returns the value of the key to display
+// (the changed value, like the masked secret, or the same value) and
+// false to skip the key.
+type Filter func(key, value string) (string, bool)
+
+// FilterPrefix returns the filter that keeps the keys with the prefix.
+func FilterPrefix(prefix string) Filter {
+	return func(key, value string) (string, bool) {
+		return value, strings.HasPrefix(key, prefix)
+	}
+}
+
+// FilterRegexp returns the filter that keeps the keys
+// that match the regular expression.
+func FilterRegexp(re *regexp.Regexp) Filter {
+	return func(key, value string) (string, bool) {
+		return value, re.MatchString(key)
+	}
+}
+
+// FilterMask returns the filter that replaces the values of the keys
+// that match the patterns with the mask, like ******. The patterns use
+// the path.Match syntax and are case-insensitive, the default patterns
+// (keys containing PASSWORD, SECRET, TOKEN etc.) are used if none are
+// specified.
+func FilterMask(patterns ...string) Filter {
+	if len(patterns) == 0 {
+		patterns = defMaskPatterns
+	}
+
+	return func(key, value string) (string, bool) {
+		if isMasked(key, patterns) {
+			return maskValue, true
+		}
+
+		return value, true
+	}
+}
+
+// EnvironSorted works like Environ, but returns the items of the
+// environment sorted by the keys, without duplicates (the first
+// item of the key is kept, as Get does) and passed through the
+// filters in the order they are given, so the debug printers
+// don't have to do it by themselves.
+//
+// # Examples
+//
+//	items := env.EnvironSorted(
+//		env.FilterPrefix("APP_"),
+//		env.FilterMask(),
+//	)
+//
+//	for _, item := range items {
+//		fmt.Println(item) // APP_DB_PASSWORD=******
+//	}
+func EnvironSorted(filters ...Filter) []string {
+	var (
+		items  = environ()
+		result = make([]string, 0, len(items))
+		seen   = make(map[string]bool, len(items))
+	)
+
+	for _, item := range items {
+		key, value, _ := strings.Cut(item, "=")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ok := true
+		for _, filter := range filters {
+			if value, ok = filter(key, value); !ok {
+				break
+			}
+		}
+
+		if ok {
+			result = append(result, key+"="+value)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, _, _ := strings.Cut(result[i], "=")
+		b, _, _ := strings.Cut(result[j], "=")
+		return a < b
+	})
+
+	return result
+}
@@ -0,0 +1,46 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+)
+
+// TestEnvironSorted tests EnvironSorted function.
+func TestEnvironSorted(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_DB_PASSWORD", "secret")
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("APP_B", "2")
+	os.Setenv("APP_A_B", "1") // sorted by the key, not by the item
+	os.Setenv("HOME", "/root")
+
+	expected := "[APP_A_B=1 APP_B=2 APP_DB_PASSWORD=secret " +
+		"APP_HOST=localhost APP_PORT=8080 HOME=/root]"
+	if v := fmt.Sprint(EnvironSorted()); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// By prefix with the masked secrets.
+	expected = "[APP_A_B=1 APP_B=2 APP_DB_PASSWORD=****** " +
+		"APP_HOST=localhost APP_PORT=8080]"
+	items := EnvironSorted(FilterPrefix("APP_"), FilterMask())
+	if v := fmt.Sprint(items); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// By regular expression with the custom patterns.
+	expected = "[APP_HOST=****** APP_PORT=8080]"
+	items = EnvironSorted(FilterRegexp(regexp.MustCompile(`_(HOST|PORT)$`)),
+		FilterMask("*host"))
+	if v := fmt.Sprint(items); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// Nothing matches.
+	if items := EnvironSorted(FilterPrefix("NONE_")); len(items) != 0 {
+		t.Errorf("expected empty list but `%v`", items)
+	}
+}
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRequired is returned when a required key
+	// is missing from the environment.
+	ErrRequired = errors.New("required key is missing")
+
+	// ErrFrozen is returned when the environment is changed
+	// after it has been frozen by the Freeze function.
+	ErrFrozen = errors.New("environment is frozen")
+
+	// ErrSourceUnavailable is returned when the source of the
+	// configuration can't be reached after all retries.
+	ErrSourceUnavailable = errors.New("source is unavailable")
+
+	// ErrUndefined is returned when the expanded value refers
+	// to a variable that is not defined and has no default value.
+	ErrUndefined = errors.New("undefined variable")
+
+	// ErrNotDir is returned when the path of the field
+	// with the path:"dir" tag isn't a directory.
+	ErrNotDir = errors.New("not a directory")
+
+	// ErrNotFile is returned when the path of the field
+	// with the path:"file" tag isn't a file.
+	ErrNotFile = errors.New("not a file")
+
+	// ErrNotInSource is returned when the field is pinned to the source
+	// by the source tag, but the source doesn't provide its key.
+	ErrNotInSource = errors.New("key is not provided by the source")
+
+	// ErrIncompatibleKey is returned when the key of the marshaled
+	// object isn't valid on the target platform (see SetTarget).
+	ErrIncompatibleKey = errors.New("incompatible key")
+
+	// ErrAmbiguousUnmarshaler is returned when the object implements
+	// both the Unmarshaler and the PartialUnmarshaler interfaces.
+	ErrAmbiguousUnmarshaler = errors.New(
+		"both UnmarshalEnv and UnmarshalEnvPartial are implemented")
+
+	// ErrNotifyUnsupported is returned by the FileWatcher in the
+	// WatchNotify mode if the file change notifications aren't
+	// supported on the platform.
+	ErrNotifyUnsupported = errors.New("file notifications are unsupported")
+
+	// ErrNoFiles is returned when the function that loads
+	// the env-files is called without file names.
+	ErrNoFiles = errors.New("no env-files given")
+
+	// ErrValueTooLong is returned when the value exceeds the limit
+	// of the length with the SizeReject policy (see SetValueLimit).
+	ErrValueTooLong = errors.New("value is too long")
+)
+
+// KeyError is the error related to the specific key.
+type KeyError struct {
+	Key string // key name
+	Err error  // the cause of the error
+}
+
+// Error returns the error message with the key name.
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,102 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// InitFromExample creates the env-file by the example file (like
+// .env.example): the comments and keys are copied with their values
+// as defaults. If interactive is true, the value of each key with the
+// empty value in the example is requested from the user (the standard
+// input is read, the prompts are written to the standard error), the
+// empty answer keeps the key empty. The empty values without quotes
+// (like KEY=) are written as "" to be parsed by Load.
+//
+// The existing target isn't overwritten if force is false, in this
+// case the error wrapping os.ErrExist is returned. The target is
+// created with the 0600 permissions, as it can contain secrets.
+//
+// # Examples
+//
+//	err := env.InitFromExample(".env.example", ".env", true, false)
+//	if errors.Is(err, os.ErrExist) {
+//		log.Println(".env already exists")
+//	}
+func InitFromExample(examplePath, targetPath string,
+	interactive, force bool) error {
+	var in io.Reader
+	if interactive {
+		in = os.Stdin
+	}
+
+	return initFromExample(examplePath, targetPath, in, os.Stderr, force)
+}
+
+// The initFromExample works like InitFromExample, the values
+// of the empty keys are read from the in if it isn't nil.
+func initFromExample(examplePath, targetPath string, in io.Reader,
+	out io.Writer, force bool) error {
+	if _, err := os.Stat(targetPath); err == nil && !force {
+		return fmt.Errorf("%w: %s", os.ErrExist, targetPath)
+	}
+
+	data, err := os.ReadFile(examplePath)
+	if err != nil {
+		return err
+	}
+
+	var reader *bufio.Reader
+	if in != nil {
+		reader = bufio.NewReader(in)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i, line := range lines {
+		tmp := keyRgx.FindStringSubmatch(line)
+		if isEmpty(line) || len(tmp) < 2 {
+			continue
+		}
+
+		// Skip the keys with values. The value is empty for the lines
+		// like KEY='', KEY= (isn't parsed) or KEY= # comment.
+		rest := strings.TrimSpace(line[len(tmp[0]):])
+		_, value, err := parseExpression(line)
+		if (err == nil && value != "") ||
+			(err != nil && rest != "" && rest[0] != '#') {
+			continue
+		} else if err != nil {
+			value = ""
+		}
+
+		// The inline comment after the empty value.
+		comment := rest
+		if rest != "" && rest[0] != '#' {
+			comment = strings.TrimSpace(rest[2:]) // after '' or ""
+		}
+
+		if reader != nil {
+			fmt.Fprintf(out, "%s: ", tmp[1])
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			value = strings.TrimRight(answer, "\r\n")
+		}
+
+		// The line is rewritten if it can't be parsed
+		// or the value has been entered.
+		if err != nil || value != "" {
+			if comment != "" {
+				comment = " " + comment
+			}
+			lines[i] = tmp[0] + Quote(value, QuoteAuto) + comment
+		}
+	}
+
+	data = []byte(strings.Join(lines, "\n") + "\n")
+	return os.WriteFile(targetPath, data, 0o600)
+}
@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInitFromExample tests InitFromExample function.
+func TestInitFromExample(t *testing.T) {
+	const example = "# Server.\n" +
+		"HOST=localhost\n" +
+		"PORT=\n" +
+		"export NAME=''\n" +
+		"\n" +
+		"PASSWORD= # secret\n"
+
+	dir := t.TempDir()
+	examplePath := filepath.Join(dir, ".env.example")
+	targetPath := filepath.Join(dir, ".env")
+	err := os.WriteFile(examplePath, []byte(example), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-interactive mode copies the example.
+	if err := InitFromExample(examplePath, targetPath, false,
+		false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Server.\n" +
+		"HOST=localhost\n" +
+		"PORT=\"\"\n" +
+		"export NAME=''\n" +
+		"\n" +
+		"PASSWORD=\"\" # secret\n"
+
+	data, _ := os.ReadFile(targetPath)
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, data)
+	}
+
+	// The existing file isn't overwritten.
+	err = InitFromExample(examplePath, targetPath, false, false)
+	if !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected os.ErrExist but %v", err)
+	}
+
+	// Interactive mode.
+	var out strings.Builder
+	in := strings.NewReader("8080\nmy app\n")
+	err = initFromExample(examplePath, targetPath, in, &out, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "# Server.\n" +
+		"HOST=localhost\n" +
+		"PORT=8080\n" +
+		"export NAME=\"my app\"\n" +
+		"\n" +
+		"PASSWORD=\"\" # secret\n"
+
+	data, _ = os.ReadFile(targetPath)
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, data)
+	}
+
+	if out.String() != "PORT: NAME: PASSWORD: " {
+		t.Errorf("incorrect prompts: %s", out.String())
+	}
+}
@@ -0,0 +1,254 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// The expandOnMarshal is true if the values of the fields are expanded
+// by the values of the previous fields on marshal (see ExpandOnMarshal).
+var expandOnMarshal atomic.Bool
+
+// ExpandOnMarshal sets whether the Marshal function (and the functions
+// based on it, like Save and Encoder) resolves the ${VAR} and $VAR
+// references between the fields of the same structure. The fields are
+// resolved in the declaration order, so the field can refer to the
+// fields declared before it by the key with or without the prefix.
+// The references to other variables are kept as is (to be expanded
+// when the file is loaded). Returns the previous mode.
+//
+// The mode is disabled by default.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST"`
+//		Port int    `env:"PORT"`
+//		URL  string `env:"URL"`
+//	}
+//
+//	env.ExpandOnMarshal(true)
+//	config := Config{"localhost", 8080, "http://${HOST}:${PORT}/$PATH"}
+//	env.Save(".env", "", config) // URL=http://localhost:8080/$PATH
+func ExpandOnMarshal(enabled bool) bool {
+	return expandOnMarshal.Swap(enabled)
+}
+
+// ExpandWith replaces ${var} or $var in the string according to the
+// values returned by the mapping function, the mapping reports whether
+// the variable is defined. If mapping is nil, the current environment
+// is used.
+//
+// In addition to os.Expand syntax, the POSIX parameter expansion
+// operators are supported:
+//
+//   - ${VAR:-default} - default if VAR is undefined or empty;
+//   - ${VAR-default} - default if VAR is undefined only;
+//   - ${VAR:?message} - error with the message if VAR is undefined
+//     or empty;
+//   - ${VAR?message} - error with the message if VAR is undefined only;
+//   - ${VAR:+alt} - alt if VAR is defined and not empty, otherwise
+//     the empty string;
+//   - ${VAR+alt} - alt if VAR is defined, otherwise the empty string.
+//
+// The default, message and alt values can contain references
+// to other variables.
+//
+// Unlike Expand, undefined variables without defaults are not silently
+// replaced by an empty string: the function returns the expanded value
+// (where such variables are empty) and an error that contains
+// a *KeyError wrapping ErrUndefined for each undefined variable
+// (with the message of the ? operator, if any).
+//
+// # Examples
+//
+//	os.Setenv("HOST", "localhost")
+//	value, err := env.ExpandWith("${HOST}:${PORT:-8080}/$DB", nil)
+//	fmt.Println(value) // localhost:8080/
+//	fmt.Println(err)   // DB: undefined variable
+func ExpandWith(
+	value string,
+	mapping func(string) (string, bool),
+) (string, error) {
+	if mapping == nil {
+		mapping = getenv
+	}
+
+	e := expander{mapping: mapping}
+	result := e.expand(value)
+	if len(e.missing) == 0 && len(e.errs) == 0 {
+		return result, nil
+	}
+
+	errs := make([]error, 0, len(e.missing)+len(e.errs))
+	for _, key := range e.missing {
+		errs = append(errs, &KeyError{Key: key, Err: ErrUndefined})
+	}
+
+	return result, errors.Join(append(errs, e.errs...)...)
+}
+
+// The expandValue replaces ${var} or $var in the value by the mapping
+// like os.Expand does, but supports the operators like ExpandWith.
+// The undefined variables are replaced by the empty string, the error
+// is returned for the ${VAR:?message} of the undefined variable only.
+func expandValue(value string,
+	mapping func(string) (string, bool)) (string, error) {
+	e := expander{mapping: mapping}
+	result := e.expand(value)
+	return result, errors.Join(e.errs...)
+}
+
+// The expander replaces variables in strings and collects
+// the names of undefined variables and the errors of the
+// ${VAR:?message} expressions. If keep is true, the
+// undefined variables are kept in the string as is.
+type expander struct {
+	mapping func(string) (string, bool)
+	missing []string
+	errs    []error
+	keep    bool
+}
+
+// The expand replaces all variables in the str.
+func (e *expander) expand(str string) string {
+	var sb strings.Builder
+
+	i := 0
+	for j := 0; j < len(str); j++ {
+		if str[j] != '$' || j+1 >= len(str) {
+			continue
+		}
+
+		sb.WriteString(str[i:j])
+		missing := len(e.missing)
+		value, width := e.variable(str[j+1:])
+		if width == 0 {
+			// Not a variable, keep the dollar sign as is.
+			sb.WriteByte('$')
+		} else if e.keep && len(e.missing) > missing {
+			value = str[j : j+width+1]
+		}
+
+		sb.WriteString(value)
+		j += width
+		i = j + 1
+	}
+
+	if i == 0 {
+		return str
+	}
+
+	sb.WriteString(str[i:])
+	return sb.String()
+}
+
+// The variable returns the value of the variable at the beginning
+// of the str (after the dollar sign) and the number of bytes it takes.
+// Returns zero width if the str doesn't start with a variable.
+func (e *expander) variable(str string) (string, int) {
+	// The $VAR form.
+	if str[0] != '{' {
+		n := nameLen(str)
+		if n == 0 {
+			return "", 0
+		}
+
+		return e.lookup(str[:n]), n
+	}
+
+	// The ${VAR} form, find the closing brace
+	// considering nested braces in the default.
+	end, depth := -1, 0
+	for i := 1; i < len(str) && end < 0; i++ {
+		switch str[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				end = i
+			}
+			depth--
+		}
+	}
+
+	if end < 0 {
+		return "", 0 // there is no closing brace
+	}
+
+	body := str[1:end]
+	n := nameLen(body)
+	if n == 0 {
+		return "", 0
+	}
+
+	name, rest := body[:n], body[n:]
+	if _, ok := e.mapping(name); !ok && e.keep && rest != "" {
+		e.missing = append(e.missing, name) // the default isn't applied
+		return "", end + 1
+	}
+
+	switch {
+	case rest == "":
+		return e.lookup(name), end + 1
+	case strings.HasPrefix(rest, ":-"):
+		if value, ok := e.mapping(name); ok && value != "" {
+			return value, end + 1
+		}
+		return e.expand(rest[2:]), end + 1
+	case strings.HasPrefix(rest, "-"):
+		if value, ok := e.mapping(name); ok {
+			return value, end + 1
+		}
+		return e.expand(rest[1:]), end + 1
+	case strings.HasPrefix(rest, ":?"), strings.HasPrefix(rest, "?"):
+		value, ok := e.mapping(name)
+		colon := rest[0] == ':'
+		if ok && (value != "" || !colon) {
+			return value, end + 1
+		}
+
+		err := ErrUndefined
+		if msg := e.expand(strings.TrimPrefix(rest, ":")[1:]); msg != "" {
+			err = fmt.Errorf("%w: %s", ErrUndefined, msg)
+		}
+		e.errs = append(e.errs, &KeyError{Key: name, Err: err})
+		return "", end + 1
+	case strings.HasPrefix(rest, ":+"), strings.HasPrefix(rest, "+"):
+		value, ok := e.mapping(name)
+		colon := rest[0] == ':'
+		if ok && (value != "" || !colon) {
+			return e.expand(strings.TrimPrefix(rest, ":")[1:]), end + 1
+		}
+		return "", end + 1
+	}
+
+	return "", 0 // unsupported expression
+}
+
+// The lookup returns value of the variable
+// and registers it as missing if it's undefined.
+func (e *expander) lookup(name string) string {
+	value, ok := e.mapping(name)
+	if !ok {
+		e.missing = append(e.missing, name)
+	}
+
+	return value
+}
+
+// The nameLen returns the length of the variable name
+// at the beginning of the str.
+func nameLen(str string) int {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c != '_' && !('0' <= c && c <= '9') &&
+			!('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') {
+			return i
+		}
+	}
+
+	return len(str)
+}
@@ -0,0 +1,154 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestExpandWith tests ExpandWith function.
+func TestExpandWith(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("EMPTY", "")
+
+	tests := []struct {
+		value    string
+		expected string
+		missing  bool
+	}{
+		{"plain text", "plain text", false},
+		{"$HOST:80", "localhost:80", false},
+		{"${HOST}:80", "localhost:80", false},
+		{"${PORT:-8080}", "8080", false},
+		{"${HOST:-example.com}", "localhost", false},
+		{"${EMPTY:-value}", "value", false},
+		{"${EMPTY-value}", "", false},
+		{"${PORT-value}", "value", false},
+		{"${URL:-http://${HOST}:${PORT:-80}}", "http://localhost:80", false},
+		{"$PORT", "", true},
+		{"${URL:-$PORT}", "", true},
+		{"price: 5$", "price: 5$", false},
+		{"$ $! ${", "$ $! ${", false},
+		{"${HOST:?no host}", "localhost", false},
+		{"${EMPTY?no value}", "", false},
+		{"${EMPTY:?no value}", "", true},
+		{"${PORT?}", "", true},
+		{"${HOST:+http://$HOST}", "http://localhost", false},
+		{"${EMPTY:+value}", "", false},
+		{"${EMPTY+value}", "value", false},
+		{"${PORT+value}", "", false},
+	}
+
+	for _, test := range tests {
+		value, err := ExpandWith(test.value, nil)
+		if value != test.expected {
+			t.Errorf("expected `%s` but `%s`", test.expected, value)
+		}
+
+		if test.missing != errors.Is(err, ErrUndefined) {
+			t.Errorf("unexpected error for `%s`: %v", test.value, err)
+		}
+	}
+}
+
+// TestExpandWithMessage tests the error message of the ? operator.
+func TestExpandWithMessage(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("NAME", "DSN")
+
+	_, err := ExpandWith("${DSN:?$NAME is required}", nil)
+	expected := "DSN: undefined variable: DSN is required"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected `%s` but `%v`", expected, err)
+	}
+
+	// The env-file with the undefined variable can't be loaded.
+	err = LoadBytes([]byte("URL=${DSN:?DSN is required}\n"), true)
+	if !errors.Is(err, ErrUndefined) {
+		t.Errorf("expected ErrUndefined but `%v`", err)
+	}
+
+	if _, err := ParseReader(strings.NewReader("URL=${DSN?}\n")); err == nil {
+		t.Error("an error is expected for the undefined variable")
+	}
+
+	if v := Expand("${DSN:?DSN is required}"); v != "" {
+		t.Errorf("expected empty string but `%s`", v)
+	}
+}
+
+// TestExpandWithMapping tests ExpandWith function with custom mapping.
+func TestExpandWithMapping(t *testing.T) {
+	mapping := func(key string) (string, bool) {
+		value, ok := map[string]string{"A": "1", "B": "2"}[key]
+		return value, ok
+	}
+
+	value, err := ExpandWith("$A-$B-$C-${D}", mapping)
+	if value != "1-2--" {
+		t.Errorf("expected `1-2--` but `%s`", value)
+	}
+
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) || keyErr.Key != "C" {
+		t.Errorf("expected KeyError for C but %v", err)
+	}
+
+	if err.Error() != "C: undefined variable\nD: undefined variable" {
+		t.Errorf("incorrect error message: %v", err)
+	}
+}
+
+// TestExpandOnMarshal tests the expansion of the references
+// between the fields on marshal.
+func TestExpandOnMarshal(t *testing.T) {
+	type db struct {
+		Name string `env:"NAME"`
+		DSN  string `env:"DSN"`
+	}
+
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		URL  string `env:"URL"`
+		Next string `env:"NEXT"`
+		Late string `env:"LATE"`
+		DB   db     `env:"DB"`
+	}
+
+	data := config{
+		Host: "localhost",
+		Port: 8080,
+		URL:  "http://${HOST}:${APP_PORT}/$PATH${ROOT:-/}",
+		Next: "${URL}?a=${MISSING-x}",
+		Late: "${DB_NAME}",
+		DB:   db{"main", "${HOST}/${NAME}"},
+	}
+
+	defer ExpandOnMarshal(ExpandOnMarshal(true))
+	items, err := marshalEnv("APP_", data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"APP_HOST=localhost",
+		"APP_PORT=8080",
+		"APP_URL=http://localhost:8080/$PATH${ROOT:-/}",
+		"APP_NEXT=http://localhost:8080/$PATH${ROOT:-/}?a=${MISSING-x}",
+		"APP_LATE=${DB_NAME}",
+		"APP_DB_NAME=main",
+		"APP_DB_DSN=${HOST}/main",
+	}
+	if v := strings.Join(items, "\n"); v != strings.Join(expected, "\n") {
+		t.Errorf("expected `%v` but `%v`", expected, items)
+	}
+
+	ExpandOnMarshal(false)
+	items, _ = marshalEnv("", data, true)
+	if items[2] != "URL="+data.URL {
+		t.Errorf("expected `URL=%s` but `%s`", data.URL, items[2])
+	}
+}
@@ -0,0 +1,114 @@
+package env
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// The expiries contains the expiration time of the keys set by
+	// SetWithTTL or loaded with the expires comment.
+	expiries = make(map[string]time.Time)
+
+	// The expiriesMu protects the expiries.
+	expiriesMu sync.RWMutex
+
+	// The now returns the current time, it's replaced in tests.
+	now = time.Now
+
+	// The expiresRgx matches the expiration metadata
+	// in the comment, like # expires=2025-01-01.
+	expiresRgx = regexp.MustCompile(`#.*\bexpires=(\S+)`)
+)
+
+// SetWithTTL sets the value of the environment variable named by the key
+// that expires after the ttl: the value isn't removed, but the key is
+// reported by Expired, Check and the Reloader.NextExpiry method, so the
+// credential can be refreshed in time. The expiration is reset when the
+// key is changed again.
+//
+// The expiration can also be set in the env-file by the comment:
+//
+//	API_TOKEN=abc # expires=2025-01-01
+//
+// The date (2006-01-02) and RFC 3339 formats are supported.
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
+func SetWithTTL(key, value string, ttl time.Duration) error {
+	if err := setenv(key, value); err != nil {
+		return err
+	}
+
+	setExpiry(key, now().Add(ttl))
+	return nil
+}
+
+// Expiry returns the expiration time of the key. The boolean
+// is false if the key has no expiration.
+func Expiry(key string) (time.Time, bool) {
+	expiriesMu.RLock()
+	defer expiriesMu.RUnlock()
+
+	t, ok := expiries[key]
+	return t, ok
+}
+
+// Expired returns the sorted list of the keys that have expired.
+func Expired() []string {
+	expiriesMu.RLock()
+	defer expiriesMu.RUnlock()
+
+	var keys []string
+	current := now()
+	for key, t := range expiries {
+		if !current.Before(t) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// The setExpiry sets the expiration time of the key.
+func setExpiry(key string, t time.Time) {
+	expiriesMu.Lock()
+	defer expiriesMu.Unlock()
+	expiries[key] = t
+}
+
+// The clearExpiry removes the expiration time of the keys,
+// all keys are cleared if no keys are given.
+func clearExpiry(keys ...string) {
+	expiriesMu.Lock()
+	defer expiriesMu.Unlock()
+
+	if len(keys) == 0 {
+		expiries = make(map[string]time.Time)
+		return
+	}
+
+	for _, key := range keys {
+		delete(expiries, key)
+	}
+}
+
+// The parseExpiry returns the expiration time from the inline
+// comment of the line of the env-file, like # expires=2025-01-01.
+// The text inside the quoted value isn't taken into account.
+func parseExpiry(line string) (time.Time, bool) {
+	tmp := expiresRgx.FindStringSubmatch(inlineComment(line))
+	if len(tmp) < 2 {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, tmp[1]); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSetWithTTL tests SetWithTTL, Expiry and Expired functions.
+func TestSetWithTTL(t *testing.T) {
+	current := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	Clear()
+	if err := SetWithTTL("TOKEN", "abc", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetWithTTL("KEY", "abc", 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, ok := Expiry("TOKEN"); !ok || !exp.Equal(current.Add(
+		time.Hour)) {
+		t.Errorf("incorrect expiration time: %v", exp)
+	}
+
+	if keys := Expired(); len(keys) != 0 {
+		t.Errorf("expected no expired keys but %v", keys)
+	}
+
+	current = current.Add(time.Hour)
+	if keys := Expired(); len(keys) != 1 || keys[0] != "TOKEN" {
+		t.Errorf("expected [TOKEN] but %v", keys)
+	}
+
+	// The expiration is reset by the change of the key.
+	Set("TOKEN", "def")
+	if _, ok := Expiry("TOKEN"); ok {
+		t.Error("the expiration should be reset")
+	}
+
+	Clear()
+	if _, ok := Expiry("KEY"); ok {
+		t.Error("the expiration should be reset")
+	}
+}
+
+// TestLoadExpiry tests expiration comments in the env-file.
+func TestLoadExpiry(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	defer func() { now = time.Now }()
+
+	const text = "TOKEN=abc # expires=2024-12-31\n" +
+		"KEY=def # expires=2025-06-01T10:00:00Z\n" +
+		"HOST=localhost\n" +
+		"URL=\"note #1 expires=2020-01-01 soon\"\n"
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	Clear()
+	if err := Load(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys := Expired(); len(keys) != 1 || keys[0] != "TOKEN" {
+		t.Errorf("expected [TOKEN] but %v", keys)
+	}
+
+	var config struct {
+		Token string `env:"TOKEN"`
+		Key   string `env:"KEY"`
+		Host  string `env:"HOST"`
+	}
+
+	r, err := NewReloader("", &config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, exp, ok := r.NextExpiry()
+	if !ok || key != "TOKEN" || exp.Format(time.DateOnly) != "2024-12-31" {
+		t.Errorf("incorrect next expiry: %s %v", key, exp)
+	}
+
+	// Check reports the expired keys.
+	warnings, err := Check(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(warnings) != 3 || warnings[0].String() != "1 ExpiredKey: "+
+		"The TOKEN key expired on 2024-12-31" {
+		t.Errorf("incorrect warnings: %v", warnings)
+	}
+}
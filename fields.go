@@ -0,0 +1,131 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// The fieldInfo describes the field of the structure
+// that is mapped to a key in the environment.
+type fieldInfo struct {
+	field reflect.StructField // field description
+	path  []int               // index sequence of the field from the root
+	name  string              // Go-style path to the field, like DB.Host
+	tg    *tagGroup           // tags of the field, the key has a prefix
+}
+
+// The walkFields calls fn for each field of the structure type t (or
+// pointer to the structure) that is mapped to a key in the environment.
+//
+// The nested structures are processed recursively in the same way as
+// Unmarshal does: the key of the nested structure with the underscore
+// is used as the prefix for its fields, and fn is called for the fields
+// of the nested structure but not for the structure itself.
+//
+// Returns an error if the field has an invalid key name or if fn
+// returns an error.
+func walkFields(prefix string, t reflect.Type,
+	fn func(fi fieldInfo) error) error {
+	return walkFieldsPath(prefix, "", nil, t, fn)
+}
+
+// The walkFieldsPath is the recursive implementation of walkFields.
+func walkFieldsPath(prefix, name string, path []int, t reflect.Type,
+	fn func(fi fieldInfo) error) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("incorrect type: %s", t)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tg := newTagGroup(field)
+		tg.addPrefix(prefix)
+		if !tg.isValid() {
+			return fmt.Errorf(
+				"the %s field does not have a valid key name value: %s",
+				field.Name,
+				tg.key,
+			)
+		}
+
+		fi := fieldInfo{
+			field: field,
+			path:  append(append([]int{}, path...), i),
+			name:  name + field.Name,
+			tg:    tg,
+		}
+
+		if isNestedStruct(field.Type) {
+			err := walkFieldsPath(tg.key+"_", fi.name+".", fi.path,
+				field.Type, fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(fi); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The isExportedPath returns true if all fields on the path from the
+// root structure type t (or pointer to the structure) are exported,
+// so the field can be set by the reflection.
+func isExportedPath(t reflect.Type, path []int) bool {
+	for _, index := range path {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		field := t.Field(index)
+		if !field.IsExported() {
+			return false
+		}
+		t = field.Type
+	}
+
+	return true
+}
+
+// The fieldValue returns the value of the field from the root structure
+// (or pointer to the structure) by the path. The boolean is false if the
+// field can't be reached because of a nil pointer on the path.
+func fieldValue(root reflect.Value, path []int) (reflect.Value, bool) {
+	root = reflect.Indirect(root)
+	if !root.IsValid() {
+		return reflect.Value{}, false
+	}
+
+	v, err := root.FieldByIndexErr(path)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+// The allocFieldValue works like fieldValue but allocates
+// the nil pointers to nested structures on the path.
+func allocFieldValue(root reflect.Value, path []int) reflect.Value {
+	v := reflect.Indirect(root)
+	for i, index := range path {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+
+	return v
+}
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestWalkFields tests walkFields function.
+func TestWalkFields(t *testing.T) {
+	type nested struct {
+		Label string `env:"LABEL"`
+	}
+
+	type config struct {
+		Host   string  `env:"HOST"`
+		API    url.URL `env:"API_URL"`
+		Nested nested  `env:"NESTED"`
+		Ptr    *nested `env:"PTR"`
+		Port   int
+	}
+
+	var keys, names []string
+	fn := func(fi fieldInfo) error {
+		keys = append(keys, fi.tg.key)
+		names = append(names, fi.name)
+		return nil
+	}
+
+	err := walkFields("APP_", reflect.TypeOf(&config{}), fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"APP_HOST",
+		"APP_API_URL",
+		"APP_NESTED_LABEL",
+		"APP_PTR_LABEL",
+		"APP_Port",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v but %v", expected, keys)
+	}
+
+	expected = []string{"Host", "API", "Nested.Label", "Ptr.Label", "Port"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v but %v", expected, names)
+	}
+
+	// Field values.
+	cfg := config{Nested: nested{Label: "a"}}
+	if v, ok := fieldValue(reflect.ValueOf(&cfg), []int{2, 0}); !ok ||
+		v.String() != "a" {
+		t.Errorf("expected `a` but `%v`", v)
+	}
+
+	if _, ok := fieldValue(reflect.ValueOf(&cfg), []int{3, 0}); ok {
+		t.Error("the field behind the nil pointer shouldn't be reached")
+	}
+
+	// Invalid key.
+	err = walkFields("", reflect.TypeOf(struct {
+		Host string `env:"HO$T"`
+	}{}), func(fieldInfo) error { return nil })
+	if err == nil {
+		t.Error("there must be an error for the invalid key name")
+	}
+}
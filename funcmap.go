@@ -0,0 +1,52 @@
+package env
+
+import "text/template"
+
+// FuncMap returns the functions to get environment variables in the
+// text/template and html/template templates:
+//
+//   - env KEY - value of the variable or empty string;
+//   - envOr KEY DEFAULT - value of the variable or the DEFAULT value
+//     if the variable isn't present;
+//   - envRequired KEY - value of the variable, template execution stops
+//     with an error if the variable isn't present;
+//   - envList KEY [SEP] - value of the variable split into list, uses
+//     the same rules as Unmarshal for slices (SEP is space by default).
+//
+// # Examples
+//
+//	tpl := template.Must(template.New("nginx").Funcs(env.FuncMap()).Parse(
+//		`listen {{ envOr "PORT" "80" }};` +
+//			`server_name {{ range envList "HOSTS" "," }}{{ . }} {{ end }};`,
+//	))
+//	err := tpl.Execute(os.Stdout, nil)
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"env":         getenvValue,
+		"envOr":       envOr,
+		"envRequired": GetRequired,
+		"envList":     envList,
+	}
+}
+
+// The envOr returns value of the variable or def
+// if the variable isn't present.
+func envOr(key, def string) string {
+	if value, ok := getenv(key); ok {
+		return value
+	}
+
+	return def
+}
+
+// The envList returns value of the variable split by separator.
+// Only the first element of the sep is used, the defValueSep is
+// used if sep isn't specified.
+func envList(key string, sep ...string) []string {
+	s := defValueSep
+	if len(sep) != 0 && sep[0] != "" {
+		s = sep[0]
+	}
+
+	return splitList(getenvValue(key), s)
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// TestFuncMap tests FuncMap function.
+func TestFuncMap(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("HOSTS", "a.com,b.com")
+	os.Setenv("NAMES", "x y")
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`{{ env "HOST" }}`, "localhost"},
+		{`{{ env "PORT" }}`, ""},
+		{`{{ envOr "PORT" "80" }}`, "80"},
+		{`{{ envOr "HOST" "example.com" }}`, "localhost"},
+		{`{{ envRequired "HOST" }}`, "localhost"},
+		{`{{ range envList "HOSTS" "," }}[{{ . }}]{{ end }}`, "[a.com][b.com]"},
+		{`{{ range envList "NAMES" }}[{{ . }}]{{ end }}`, "[x][y]"},
+		{`{{ len (envList "EMPTY") }}`, "0"},
+	}
+
+	for _, test := range tests {
+		tpl, err := template.New("").Funcs(FuncMap()).Parse(test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sb strings.Builder
+		if err := tpl.Execute(&sb, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if sb.String() != test.expected {
+			t.Errorf("expected `%s` but `%s`", test.expected, sb.String())
+		}
+	}
+
+	// Required variable is missing.
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{ envRequired "PORT" }}`,
+	))
+
+	err := tpl.Execute(&strings.Builder{}, nil)
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("expected ErrRequired but %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// The gcpMetadataHost is the default host of the metadata server.
+	gcpMetadataHost = "metadata.google.internal"
+
+	// The gcpMetadataHostEnv is the name of the variable that overrides
+	// the host of the metadata server (as in the Google Cloud libraries).
+	gcpMetadataHostEnv = "GCE_METADATA_HOST"
+
+	// The gcpMetadataTimeout is the timeout of the request
+	// to the metadata server.
+	gcpMetadataTimeout = time.Second
+
+	// The gcpMetadataRetry is the interval after which the unreachable
+	// metadata server is requested again.
+	gcpMetadataRetry = time.Minute
+)
+
+// The gcpSource is the Source of the GCP metadata server.
+type gcpSource struct {
+	host   string
+	client *http.Client
+	retry  time.Duration
+
+	// The unavailable is the time (in Unix nanoseconds) until which
+	// the metadata server is considered unreachable after the connection
+	// error, so the lookups go to the environment without waiting.
+	unavailable atomic.Int64
+}
+
+// GCPMetadataSource returns the Source that resolves the keys from the
+// custom metadata of the GCE instance or Cloud Run service (instance
+// attributes first, then project attributes). If the key isn't found
+// in the metadata or the metadata server isn't available (the program
+// runs outside of GCP), the value is taken from the environment.
+//
+// The requests have a short timeout. If the metadata server can't be
+// reached, it isn't requested for a minute and the values are taken
+// from the environment. The other failed requests (like the 503 status)
+// take the value from the environment for the current lookup only.
+// If the ttl is positive, the values are cached (see Cached).
+//
+// The host of the metadata server can be overridden by the
+// GCE_METADATA_HOST environment variable.
+//
+// # Examples
+//
+//	src := env.GCPMetadataSource(5 * time.Minute)
+//	if err := env.UnmarshalSource(ctx, src, "", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func GCPMetadataSource(ttl time.Duration) Source {
+	host := os.Getenv(gcpMetadataHostEnv)
+	if host == "" {
+		host = gcpMetadataHost
+	}
+
+	var src Source = &gcpSource{
+		host:   host,
+		client: &http.Client{Timeout: gcpMetadataTimeout},
+		retry:  gcpMetadataRetry,
+	}
+
+	if ttl > 0 {
+		src = Cached(src, ttl)
+	}
+
+	return src
+}
+
+// Lookup returns the value of the key from the metadata server
+// or from the environment.
+func (s *gcpSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	if now := time.Now().UnixNano(); now >= s.unavailable.Load() {
+		for _, path := range []string{"instance", "project"} {
+			value, ok, err := s.get(ctx, path+"/attributes/"+key)
+			if err != nil {
+				if ctx.Err() != nil {
+					return "", false, ctx.Err()
+				}
+
+				// The server can't be reached (the connection, DNS
+				// or timeout error of the client).
+				var urlErr *url.Error
+				if errors.As(err, &urlErr) {
+					s.unavailable.Store(now + int64(s.retry))
+				}
+				break
+			}
+
+			if ok {
+				return value, true, nil
+			}
+		}
+	}
+
+	value, ok := lookupenv(key)
+	return value, ok, nil
+}
+
+// The get requests the value from the metadata server by the path.
+// The boolean is false if the value isn't found.
+func (s *gcpSource) get(ctx context.Context,
+	path string) (string, bool, error) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   s.host,
+		Path:   "/computeMetadata/v1/" + path,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		u.String(), nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", false, nil
+	case resp.StatusCode != http.StatusOK ||
+		resp.Header.Get("Metadata-Flavor") != "Google":
+		return "", false, fmt.Errorf("metadata server: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(data), true, nil
+}
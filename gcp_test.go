@@ -0,0 +1,142 @@
+package env
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGCPMetadataSource tests GCPMetadataSource function.
+func TestGCPMetadataSource(t *testing.T) {
+	attributes := map[string]string{
+		"/computeMetadata/v1/instance/attributes/HOST": "instance",
+		"/computeMetadata/v1/project/attributes/HOST":  "project",
+		"/computeMetadata/v1/project/attributes/PORT":  "8080",
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Metadata-Flavor", "Google")
+			value, ok := attributes[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(value))
+		},
+	))
+	defer server.Close()
+
+	os.Clearenv()
+	os.Setenv(gcpMetadataHostEnv, strings.TrimPrefix(server.URL, "http://"))
+	os.Setenv("USER", "admin")
+
+	ctx := context.Background()
+	src := GCPMetadataSource(0)
+
+	tests := []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{"HOST", "instance", true},
+		{"PORT", "8080", true},
+		{"USER", "admin", true},
+		{"PASSWORD", "", false},
+	}
+
+	for _, test := range tests {
+		value, ok, err := src.Lookup(ctx, test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value != test.value || ok != test.ok {
+			t.Errorf("expected `%s` but `%s`", test.value, value)
+		}
+	}
+
+	// The values set in the store mode.
+	prev, _ := StoreMode(true)
+	defer StoreMode(prev)
+	Set("STORED", "overlay")
+	if value, ok, _ := src.Lookup(ctx, "STORED"); value != "overlay" || !ok {
+		t.Errorf("expected `overlay` but `%s`", value)
+	}
+	StoreMode(false)
+
+	// Cached values.
+	requests = 0
+	src = GCPMetadataSource(time.Minute)
+	for i := 0; i < 3; i++ {
+		if value, _, _ := src.Lookup(ctx, "PORT"); value != "8080" {
+			t.Errorf("expected `8080` but `%s`", value)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests but %d", requests)
+	}
+
+	// The transient error of the metadata server doesn't
+	// stop the next requests.
+	status := http.StatusServiceUnavailable
+	flaky := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Metadata-Flavor", "Google")
+			w.WriteHeader(status)
+			w.Write([]byte("metadata"))
+		},
+	))
+	defer flaky.Close()
+
+	os.Setenv(gcpMetadataHostEnv, strings.TrimPrefix(flaky.URL, "http://"))
+	os.Setenv("NAME", "env")
+	src = GCPMetadataSource(0)
+	for _, expected := range []string{"env", "metadata"} {
+		if value, _, _ := src.Lookup(ctx, "NAME"); value != expected {
+			t.Errorf("expected `%s` but `%s`", expected, value)
+		}
+		status = http.StatusOK
+	}
+
+	// The metadata server is unavailable.
+	os.Setenv(gcpMetadataHostEnv, strings.TrimPrefix(server.URL, "http://"))
+	server.Close()
+	src = GCPMetadataSource(0)
+	for i := 0; i < 2; i++ {
+		value, ok, err := src.Lookup(ctx, "USER")
+		if err != nil || !ok || value != "admin" {
+			t.Errorf("expected `admin` but `%s` (%v)", value, err)
+		}
+	}
+
+	// The unreachable metadata server is requested again
+	// after the interval.
+	gcp := &gcpSource{
+		host:   strings.TrimPrefix(server.URL, "http://"),
+		client: http.DefaultClient,
+		retry:  time.Millisecond,
+	}
+	gcp.Lookup(ctx, "USER")
+	if gcp.unavailable.Load() == 0 {
+		t.Error("the metadata server is expected to be unavailable")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	gcp.host = strings.TrimPrefix(flaky.URL, "http://")
+	if value, _, _ := gcp.Lookup(ctx, "USER"); value != "metadata" {
+		t.Errorf("expected `metadata` but `%s`", value)
+	}
+}
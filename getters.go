@@ -0,0 +1,169 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// GetRequired retrieves the value of the environment variable named by
+// the key. Unlike Get, it returns an error wrapping ErrRequired if the
+// variable is not present (an empty value is considered as present).
+//
+// # Examples
+//
+//	dsn, err := env.GetRequired("DATABASE_URL")
+//	if err != nil {
+//		log.Fatal(err) // required key is missing: DATABASE_URL
+//	}
+func GetRequired(key string) (string, error) {
+	value, ok := lookupenv(key)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRequired, key)
+	}
+
+	return value, nil
+}
+
+// LookupAll retrieves the values of the environment variables named by
+// the keys. It returns the map of found keys and their values, and the
+// list of missing keys in the order in which they were passed.
+//
+// It allows to check all required variables at once and report about
+// all absent ones in a single message.
+//
+// # Examples
+//
+//	values, missing := env.LookupAll("HOST", "PORT", "USER")
+//	if len(missing) != 0 {
+//		log.Fatalf("missing: %s", strings.Join(missing, ", "))
+//	}
+//	fmt.Println(values["HOST"], values["PORT"], values["USER"])
+func LookupAll(keys ...string) (map[string]string, []string) {
+	var missing []string
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := lookupenv(key); ok {
+			values[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+
+	return values, missing
+}
+
+// GetAs retrieves the value of the environment variable named by the key
+// converted to the type T. The types supported by Unmarshal are supported
+// (numbers, booleans, durations, url.URL, registered types etc.), the
+// items of slices and arrays are separated by space (see GetAsSep).
+//
+// If the variable isn't present, the first default value is returned (or
+// the zero value if there is no default). The conversion error is the
+// KeyError with the key name.
+//
+// # Examples
+//
+//	port, err := env.GetAs[int]("PORT", 8080)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	timeout, _ := env.GetAs[time.Duration]("TIMEOUT", 30*time.Second)
+func GetAs[T any](key string, def ...T) (T, error) {
+	return GetAsSep(key, defValueSep, def...)
+}
+
+// GetAsSep works like GetAs, but the items of slices
+// and arrays are separated by the sep.
+//
+// # Examples
+//
+//	hosts, err := env.GetAsSep[[]string]("HOSTS", ",")
+func GetAsSep[T any](key, sep string, def ...T) (T, error) {
+	var result T
+
+	value, ok := lookupenv(key)
+	if !ok {
+		if len(def) != 0 {
+			result = def[0]
+		}
+		return result, nil
+	}
+
+	tg := &tagGroup{key: key, value: value, sep: sep, kvsep: defKVSep}
+	item := reflect.ValueOf(&result).Elem()
+	if err := setFieldValue(&item, tg, nil, nil); err != nil {
+		var zero T
+		return zero, &KeyError{Key: key, Err: err}
+	}
+
+	return result, nil
+}
+
+// GetInt returns the integer value of the environment variable named by
+// the key, or the def if the variable is missing or empty. The value
+// is parsed in the same way as Unmarshal does it (see SetNumberFormat).
+//
+// Returns the KeyError if the value is malformed.
+//
+// # Examples
+//
+//	workers, err := env.GetInt("WORKERS", 4)
+func GetInt(key string, def int64) (int64, error) {
+	return getTyped(key, defValueSep, def)
+}
+
+// GetBool returns the boolean value of the environment variable named by
+// the key, or the def if the variable is missing or empty. The value is
+// parsed in the same way as Unmarshal does it (see StrictBool and
+// RegisterBoolStrings).
+//
+// Returns the KeyError if the value is malformed.
+func GetBool(key string, def bool) (bool, error) {
+	return getTyped(key, defValueSep, def)
+}
+
+// GetFloat returns the float value of the environment variable named by
+// the key, or the def if the variable is missing or empty.
+//
+// Returns the KeyError if the value is malformed.
+func GetFloat(key string, def float64) (float64, error) {
+	return getTyped(key, defValueSep, def)
+}
+
+// GetDuration returns the time.Duration value of the environment variable
+// named by the key, or the def if the variable is missing or empty. The
+// values like 1m30s and the number of nanoseconds are accepted.
+//
+// Returns the KeyError if the value is malformed.
+func GetDuration(key string, def time.Duration) (time.Duration, error) {
+	return getTyped(key, defValueSep, def)
+}
+
+// GetSlice returns the items of the environment variable named by the key
+// separated by the sep, or the def if the variable is missing or empty.
+// If sep is empty the default separator is used (the same as for the
+// sep tag), the items can be escaped or quoted as in the sep tag.
+//
+// # Examples
+//
+//	hosts, err := env.GetSlice("HOSTS", ",", []string{"localhost"})
+func GetSlice(key, sep string, def []string) ([]string, error) {
+	if sep == "" {
+		sep = defValueSep
+	}
+
+	return getTyped(key, sep, def)
+}
+
+// The getTyped works like GetAsSep,
+// but the empty value is treated as missing.
+func getTyped[T any](key, sep string, def T) (T, error) {
+	if value, ok := lookupenv(key); !ok || value == "" {
+		return def, nil
+	}
+
+	return GetAsSep[T](key, sep)
+}
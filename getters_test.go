@@ -0,0 +1,175 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestGetRequired tests GetRequired function.
+func TestGetRequired(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("KEY_0", "value")
+	os.Setenv("KEY_1", "")
+
+	if v, err := GetRequired("KEY_0"); err != nil || v != "value" {
+		t.Errorf("expected `value` but `%s` (%v)", v, err)
+	}
+
+	if _, err := GetRequired("KEY_1"); err != nil {
+		t.Errorf("empty value should be present: %v", err)
+	}
+
+	if _, err := GetRequired("KEY_2"); !errors.Is(err, ErrRequired) {
+		t.Errorf("expected ErrRequired but %v", err)
+	}
+}
+
+// TestLookupAll tests LookupAll function.
+func TestLookupAll(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("KEY_0", "a")
+	os.Setenv("KEY_2", "")
+
+	values, missing := LookupAll("KEY_0", "KEY_1", "KEY_2", "KEY_3")
+	if len(values) != 2 || values["KEY_0"] != "a" {
+		t.Errorf("incorrect values: %v", values)
+	}
+
+	if _, ok := values["KEY_2"]; !ok {
+		t.Error("empty value should be present")
+	}
+
+	if len(missing) != 2 || missing[0] != "KEY_1" || missing[1] != "KEY_3" {
+		t.Errorf("expected `[KEY_1 KEY_3]` but `%v`", missing)
+	}
+}
+
+// TestGetAs tests GetAs and GetAsSep functions.
+func TestGetAs(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	os.Setenv("DEBUG", "true")
+	os.Setenv("TIMEOUT", "1m30s")
+	os.Setenv("CODES", "1 2 3")
+	os.Setenv("HOSTS", "a,b")
+	os.Setenv("URL", "http://example.com")
+	os.Setenv("LABELS", "a=1 b=2")
+	os.Setenv("BAD", "port")
+
+	if v, err := GetAs[int]("PORT"); err != nil || v != 8080 {
+		t.Errorf("expected `8080` but `%d` (%v)", v, err)
+	}
+
+	if v, err := GetAs[bool]("DEBUG"); err != nil || !v {
+		t.Errorf("expected `true` but `%v` (%v)", v, err)
+	}
+
+	d, err := GetAs[time.Duration]("TIMEOUT")
+	if err != nil || d != 90*time.Second {
+		t.Errorf("expected `1m30s` but `%s` (%v)", d, err)
+	}
+
+	codes, err := GetAs[[]int]("CODES")
+	if err != nil || !reflect.DeepEqual(codes, []int{1, 2, 3}) {
+		t.Errorf("expected `[1 2 3]` but `%v` (%v)", codes, err)
+	}
+
+	hosts, err := GetAsSep[[2]string]("HOSTS", ",")
+	if err != nil || hosts != [2]string{"a", "b"} {
+		t.Errorf("expected `[a b]` but `%v` (%v)", hosts, err)
+	}
+
+	u, err := GetAs[*url.URL]("URL")
+	if err != nil || u.Host != "example.com" {
+		t.Errorf("expected `example.com` but `%v` (%v)", u, err)
+	}
+
+	labels, err := GetAs[map[string]string]("LABELS")
+	if err != nil || labels["b"] != "2" {
+		t.Errorf("expected `2` but `%v` (%v)", labels, err)
+	}
+
+	// Default values.
+	if v, err := GetAs("MISSING", 5.5); err != nil || v != 5.5 {
+		t.Errorf("expected `5.5` but `%v` (%v)", v, err)
+	}
+
+	if v, err := GetAs[uint]("MISSING"); err != nil || v != 0 {
+		t.Errorf("expected `0` but `%v` (%v)", v, err)
+	}
+
+	// Conversion error.
+	var keyErr *KeyError
+	v, err := GetAs("BAD", 80)
+	if !errors.As(err, &keyErr) || keyErr.Key != "BAD" || v != 0 {
+		t.Errorf("expected KeyError but `%d` (%v)", v, err)
+	}
+}
+
+// TestTypedGetters tests GetInt, GetBool, GetFloat, GetDuration
+// and GetSlice functions.
+func TestTypedGetters(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("INT", "-5")
+	os.Setenv("BOOL", "True")
+	os.Setenv("FLOAT", "3.14")
+	os.Setenv("DURATION", "2s")
+	os.Setenv("SLICE", `a,"b,c"`)
+	os.Setenv("EMPTY", "")
+	os.Setenv("BAD", "x")
+
+	if v, err := GetInt("INT", 1); err != nil || v != -5 {
+		t.Errorf("expected `-5` but `%d` (%v)", v, err)
+	}
+
+	if v, err := GetBool("BOOL", false); err != nil || !v {
+		t.Errorf("expected `true` but `%v` (%v)", v, err)
+	}
+
+	if v, err := GetFloat("FLOAT", 0); err != nil || v != 3.14 {
+		t.Errorf("expected `3.14` but `%v` (%v)", v, err)
+	}
+
+	if v, err := GetDuration("DURATION", 0); err != nil ||
+		v != 2*time.Second {
+		t.Errorf("expected `2s` but `%v` (%v)", v, err)
+	}
+
+	v, err := GetSlice("SLICE", ",", nil)
+	if err != nil || !reflect.DeepEqual(v, []string{"a", "b,c"}) {
+		t.Errorf("expected `[a b,c]` but `%v` (%v)", v, err)
+	}
+
+	// Missing and empty values.
+	if v, err := GetInt("EMPTY", 7); err != nil || v != 7 {
+		t.Errorf("expected `7` but `%d` (%v)", v, err)
+	}
+
+	if v, err := GetDuration("MISSING", time.Minute); err != nil ||
+		v != time.Minute {
+		t.Errorf("expected `1m0s` but `%v` (%v)", v, err)
+	}
+
+	def := []string{"localhost"}
+	if v, err := GetSlice("MISSING", "", def); err != nil ||
+		!reflect.DeepEqual(v, def) {
+		t.Errorf("expected `%v` but `%v` (%v)", def, v, err)
+	}
+
+	// Malformed values.
+	for _, fn := range []func() error{
+		func() error { _, err := GetInt("BAD", 0); return err },
+		func() error { _, err := GetBool("BAD", false); return err },
+		func() error { _, err := GetFloat("BAD", 0); return err },
+		func() error { _, err := GetDuration("BAD", 0); return err },
+	} {
+		var keyErr *KeyError
+		if err := fn(); !errors.As(err, &keyErr) {
+			t.Errorf("expected KeyError but %v", err)
+		}
+	}
+}
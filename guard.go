@@ -0,0 +1,130 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// KeyChange describes the change of the key in the process environment
+// found by the Guardian.
+type KeyChange struct {
+	Key      string // key name in the environment
+	Old      string // value at the time of the snapshot
+	New      string // current value
+	OldExist bool   // true if the key was set at the time of the snapshot
+	NewExist bool   // true if the key is set now
+}
+
+var (
+	// The guardEnabled is true if the changes made through
+	// the package are recorded (see Guard).
+	guardEnabled atomic.Bool
+
+	// The guardWrites is the last values of the keys
+	// written through the package.
+	guardWrites = make(map[string]rawValue)
+
+	// The guardMu protects the guardWrites.
+	guardMu sync.Mutex
+)
+
+// Guardian is the snapshot of the process environment to find the
+// changes made around this package (see Guard).
+type Guardian struct {
+	snapshot map[string]string
+}
+
+// Guard takes the snapshot of the process environment, the changes made
+// after it by the third-party code (directly by the os package, by cgo
+// code etc.) are reported by the Check method. It helps to find who
+// mutates the environment in the large binaries.
+//
+// The changes made through this package (Set, Load, Sync etc.) are
+// recorded after the first call of Guard and aren't reported.
+//
+// # Examples
+//
+//	guard := env.Guard()
+//	thirdparty.Init()
+//	for _, c := range guard.Check() {
+//		log.Printf("%s changed: %q -> %q", c.Key, c.Old, c.New)
+//	}
+func Guard() *Guardian {
+	guardEnabled.Store(true)
+	return &Guardian{snapshot: environMap(os.Environ())}
+}
+
+// Check returns the keys of the process environment changed by the
+// third-party code since the snapshot, sorted by the keys. The key is
+// reported if its current value differs both from the snapshot and
+// from the value written through this package last.
+func (g *Guardian) Check() []KeyChange {
+	current := environMap(os.Environ())
+
+	guardMu.Lock()
+	defer guardMu.Unlock()
+
+	var changes []KeyChange
+	check := func(key string) {
+		old, oldOk := g.snapshot[key]
+		value, ok := current[key]
+		if old == value && oldOk == ok {
+			return
+		}
+
+		if w, found := guardWrites[key]; found &&
+			w.value == value && w.ok == ok {
+			return // changed through the package
+		}
+
+		changes = append(changes, KeyChange{
+			Key:      key,
+			Old:      old,
+			New:      value,
+			OldExist: oldOk,
+			NewExist: ok,
+		})
+	}
+
+	for key := range g.snapshot {
+		check(key)
+	}
+
+	for key := range current {
+		if _, ok := g.snapshot[key]; !ok {
+			check(key)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// The guardWrite records the value of the key written through
+// the package if the guard is enabled.
+func guardWrite(key, value string, ok bool) {
+	if !guardEnabled.Load() {
+		return
+	}
+
+	guardMu.Lock()
+	defer guardMu.Unlock()
+	guardWrites[key] = rawValue{value: value, ok: ok}
+}
+
+// The environMap converts the items in the form "key=value" to the map.
+func environMap(items []string) map[string]string {
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		key, value, _ := strings.Cut(item, "=")
+		result[key] = value
+	}
+
+	return result
+}
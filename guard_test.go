@@ -0,0 +1,46 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestGuard tests Guard function and Guardian.Check method.
+func TestGuard(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("PORT", "80")
+	os.Setenv("USER", "goloop")
+
+	guard := Guard()
+	if v := guard.Check(); len(v) != 0 {
+		t.Errorf("expected no changes but `%v`", v)
+	}
+
+	// The changes through the package aren't reported.
+	Set("PORT", "8080")
+	Set("DEBUG", "true")
+	Unset("USER")
+
+	// The third-party changes.
+	os.Setenv("HOST", "0.0.0.0")
+	os.Setenv("TZ", "UTC")
+	os.Setenv("DEBUG", "false")
+
+	expected := "[{DEBUG  false false true} " +
+		"{HOST localhost 0.0.0.0 true true} {TZ  UTC false true}]"
+	if v := fmt.Sprint(guard.Check()); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// The cleared environment.
+	guard = Guard()
+	Clear()
+	os.Setenv("PORT", "80")
+
+	expected = "[{PORT 8080 80 true true}]"
+	if v := fmt.Sprint(guard.Check()); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+}
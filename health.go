@@ -0,0 +1,272 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The fileKeySuffix is the suffix of the keys that contain the path to
+// the file with the secret value (like the Docker secrets convention).
+const fileKeySuffix = "_FILE"
+
+// HealthChecker re-validates the configuration from the environment and
+// reports degraded status when it becomes invalid, for example, when
+// a required key is removed, a value can't be parsed or a mounted
+// secret file referenced by the *_FILE key is rotated away.
+//
+// The checker implements http.Handler, so it can be used as
+// a health-check endpoint directly.
+type HealthChecker struct {
+	prefix string
+	typ    reflect.Type
+
+	mu      sync.RWMutex
+	err     error
+	checked time.Time
+}
+
+// NewHealthChecker returns a new health checker for the configuration
+// with the given prefix. The obj is a pointer to the configuration
+// structure and is used to determine its type only, it isn't changed
+// during the checks.
+//
+// # Examples
+//
+//	hc, err := env.NewHealthChecker("APP_", &cfg)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	go hc.Run(ctx, time.Minute)
+//	http.Handle("/healthz", hc)
+func NewHealthChecker(prefix string, obj interface{}) (*HealthChecker, error) {
+	_, rv, err := validateStruct(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthChecker{prefix: prefix, typ: rv.Type().Elem()}, nil
+}
+
+// Check validates the configuration now and returns nil
+// if it is correct, otherwise returns the problem.
+//
+// The check validates the values of the fields of the configuration type
+// as Unmarshal would decode them (the required keys, the conversion of
+// the values and the paths), but nothing is changed: the directories
+// of the path tags with the create option aren't created and the custom
+// UnmarshalEnv methods aren't called. It also checks that the files
+// referenced by the keys with the prefix and the _FILE suffix are
+// readable. The error contains a *KeyError for each invalid key.
+func (h *HealthChecker) Check(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		err = h.check()
+	}
+
+	h.mu.Lock()
+	h.err, h.checked = err, time.Now()
+	h.mu.Unlock()
+
+	return err
+}
+
+// The check performs the validation of the configuration.
+func (h *HealthChecker) check() error {
+	errs := validateEnv(h.prefix, h.typ)
+	for _, item := range environ() {
+		key, value, _ := strings.Cut(item, "=")
+		if !strings.HasPrefix(key, h.prefix) ||
+			!strings.HasSuffix(key, fileKeySuffix) || value == "" {
+			continue
+		}
+
+		file, err := os.Open(value)
+		if err != nil {
+			errs = append(errs, &KeyError{Key: key, Err: err})
+			continue
+		}
+		file.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
+// The validateEnv validates the values of the keys of the fields of the
+// structure type t with the prefix (see Check of the HealthChecker) and
+// returns the errors of the invalid keys. The entries of the maps of
+// structures are validated too, the types that implement the Unmarshaler
+// interface read the environment by themselves and aren't validated.
+func validateEnv(prefix string, t reflect.Type) []error {
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var errs []error
+	err := walkFields(prefix, t, func(fi fieldInfo) error {
+		if hasCustomUnmarshaler(t, fi.path) {
+			return nil
+		}
+
+		if ft := fi.field.Type; isStructMap(ft) {
+			names, err := mapNames(fi.tg.key+"_", ft, environKeys)
+			if err != nil {
+				errs = append(errs, &KeyError{Key: fi.tg.key, Err: err})
+			}
+
+			elem := ft.Elem()
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+
+			for _, name := range names {
+				errs = append(errs,
+					validateEnv(fi.tg.key+"_"+name+"_", elem)...)
+			}
+			return nil
+		}
+
+		tg := *fi.tg
+		value, ok, err := tg.lookup(lookupEnv)
+		switch {
+		case err != nil:
+			errs = append(errs, &KeyError{Key: tg.key, Err: err})
+			return nil
+		case ok:
+			tg.value = value
+		case tg.required && tg.value == "":
+			errs = append(errs, &KeyError{Key: tg.key, Err: ErrRequired})
+			return nil
+		case tg.value == "":
+			return nil // there is no default value
+		}
+
+		// The paths are checked, but the directories aren't created.
+		if tg.path != "" && isStringType(fi.field.Type) {
+			if tg.value, err = resolvePaths(fi.field.Type, &tg,
+				true); err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+		}
+
+		item := reflect.New(fi.field.Type).Elem()
+		if err := setFieldValue(&item, &tg, nil, nil); err != nil {
+			var keyErr *KeyError
+			if !errors.As(err, &keyErr) {
+				err = &KeyError{Key: tg.key, Err: err}
+			}
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// Run checks the configuration periodically with the given interval
+// until the context is canceled. The first check is performed at once.
+func (h *HealthChecker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Err returns the result of the last check. The result is nil
+// if the configuration was correct or hasn't been checked yet.
+func (h *HealthChecker) Err() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.err
+}
+
+// Checked returns the time of the last check.
+func (h *HealthChecker) Checked() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.checked
+}
+
+// The hasCustomUnmarshaler returns true if the field of the structure
+// type t by the path or one of the nested structures on the path
+// implements the Unmarshaler interface.
+func hasCustomUnmarshaler(t reflect.Type, path []int) bool {
+	for _, index := range path {
+		t = t.Field(index).Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if reflect.PointerTo(t).Implements(unmarshalerType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServeHTTP responds with the status 200 if the last check was
+// successful or 503 otherwise. The response of the failed check names
+// the invalid keys only, as the errors can contain the secret values.
+func (h *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.Err(); err != nil {
+		msg := "degraded"
+		if keys := errorKeys(err); len(keys) != 0 {
+			msg += ": invalid " + strings.Join(keys, ", ")
+		}
+
+		http.Error(w, msg, http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Write([]byte("ok\n"))
+}
+
+// The errorKeys returns the sorted keys of the *KeyError
+// and *PathError errors in the tree of the err.
+func errorKeys(err error) []string {
+	seen := make(map[string]bool)
+
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *KeyError:
+			seen[e.Key] = true
+		case *PathError:
+			seen[e.Key] = true
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,122 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestHealthChecker tests HealthChecker.
+func TestHealthChecker(t *testing.T) {
+	cfg := struct {
+		Host string `env:"HOST" required:"true"`
+		Port int    `env:"PORT" def:"80"`
+	}{}
+
+	secret := t.TempDir() + "/secret"
+	if err := os.WriteFile(secret, []byte("value"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	Set("APP_HOST", "localhost")
+	Set("APP_PASSWORD_FILE", secret)
+
+	hc, err := NewHealthChecker("APP_", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := hc.Check(ctx); err != nil {
+		t.Error(err)
+	}
+
+	rec := httptest.NewRecorder()
+	hc.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Errorf("expected 200 but %d", rec.Code)
+	}
+
+	// Incorrect value.
+	Set("APP_PORT", "abc")
+	if err := hc.Check(ctx); err == nil {
+		t.Error("an error is expected for incorrect value")
+	}
+	Unset("APP_PORT")
+
+	// The secret file was removed.
+	os.Remove(secret)
+	if err := hc.Check(ctx); err == nil {
+		t.Error("an error is expected for missing file")
+	}
+	Unset("APP_PASSWORD_FILE")
+
+	// The required key was removed.
+	Unset("APP_HOST")
+	if err := hc.Check(ctx); !errors.Is(err, ErrRequired) {
+		t.Errorf("expected ErrRequired but %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	hc.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 {
+		t.Errorf("expected 503 but %d", rec.Code)
+	}
+
+	// The object isn't changed.
+	if cfg.Host != "" || cfg.Port != 0 {
+		t.Errorf("the object has been changed: %v", cfg)
+	}
+
+	// Incorrect object.
+	if _, err := NewHealthChecker("", cfg); err == nil {
+		t.Error("an error is expected for no-pointer object")
+	}
+}
+
+// TestHealthCheckerIdle tests that the check has no side effects
+// and the response doesn't contain the values.
+func TestHealthCheckerIdle(t *testing.T) {
+	type db struct {
+		Port int `env:"PORT"`
+	}
+
+	cfg := struct {
+		Cache string        `env:"CACHE" path:"dir,create"`
+		Token int           `env:"TOKEN"`
+		DB    map[string]db `env:"DB"`
+	}{}
+
+	cache := filepath.Join(t.TempDir(), "cache")
+
+	os.Clearenv()
+	Set("APP_CACHE", cache)
+	Set("APP_TOKEN", "s3cr3t")
+	Set("APP_DB_MAIN_PORT", "p4ss")
+
+	hc, err := NewHealthChecker("APP_", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = hc.Check(context.Background())
+	if err == nil {
+		t.Fatal("an error is expected for incorrect values")
+	}
+
+	if _, err := os.Stat(cache); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the directory is created: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	hc.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	expected := "degraded: invalid APP_DB_MAIN_PORT, APP_TOKEN\n"
+	if v := rec.Body.String(); rec.Code != 503 || v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+}
@@ -0,0 +1,92 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// HelmValues writes the fragment of the Helm values.yaml file with the
+// values of the object fields under the env section. The name of the
+// value is the key (with prefix) in camel case, like APP_DB_HOST is
+// appDbHost. The values are formatted as Marshal does and quoted,
+// because the environment variables are strings.
+//
+// Use HelmEnv to generate the container env block that references
+// these values, so the chart and code never drift on variable names.
+//
+// The obj can be a structure or a pointer to a structure.
+//
+// # Examples
+//
+//	config := Config{Host: "localhost", Port: 80}
+//	env.HelmValues(os.Stdout, "APP_", config)
+//
+//	// Output:
+//	// env:
+//	//   appHost: "localhost"
+//	//   appPort: "80"
+func HelmValues(w io.Writer, prefix string, obj interface{}) error {
+	pairs, err := marshalEnv(prefix, obj, true)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("env:\n")
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		fmt.Fprintf(&sb, "  %s: %s\n", helmName(key), strconv.Quote(value))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// HelmEnv writes the env block of the container for the Helm template
+// that sets the environment variables from the values created by the
+// HelmValues function.
+//
+// # Examples
+//
+//	env.HelmEnv(os.Stdout, "APP_", Config{})
+//
+//	// Output:
+//	// env:
+//	//   - name: APP_HOST
+//	//     value: {{ .Values.env.appHost | quote }}
+//	//   - name: APP_PORT
+//	//     value: {{ .Values.env.appPort | quote }}
+func HelmEnv(w io.Writer, prefix string, obj interface{}) error {
+	pairs, err := marshalEnv(prefix, obj, true)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("env:\n")
+	for _, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		fmt.Fprintf(&sb, "  - name: %s\n", key)
+		fmt.Fprintf(&sb, "    value: {{ .Values.env.%s | quote }}\n",
+			helmName(key))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// The helmName converts the key to the name of the Helm value
+// in camel case, like APP_DB_HOST to appDbHost.
+func helmName(key string) string {
+	var sb strings.Builder
+	for i, word := range strings.Split(strings.ToLower(key), "_") {
+		if i > 0 && word != "" {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		sb.WriteString(word)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestHelm tests HelmValues and HelmEnv functions.
+func TestHelm(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+	}
+
+	type Config struct {
+		Host  string   `env:"HOST"`
+		Port  int      `env:"PORT"`
+		Hosts []string `env:"HOSTS" sep:","`
+		DB    DB       `env:"DB"`
+	}
+
+	config := Config{
+		Host:  "localhost",
+		Port:  80,
+		Hosts: []string{"a", "b"},
+		DB:    DB{Host: `"db"`},
+	}
+
+	var sb strings.Builder
+	if err := HelmValues(&sb, "APP_", config); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "env:\n" +
+		"  appHost: \"localhost\"\n" +
+		"  appPort: \"80\"\n" +
+		"  appHosts: \"a,b\"\n" +
+		"  appDbHost: \"\\\"db\\\"\"\n"
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, sb.String())
+	}
+
+	sb.Reset()
+	if err := HelmEnv(&sb, "APP_", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "env:\n" +
+		"  - name: APP_HOST\n" +
+		"    value: {{ .Values.env.appHost | quote }}\n" +
+		"  - name: APP_PORT\n" +
+		"    value: {{ .Values.env.appPort | quote }}\n" +
+		"  - name: APP_HOSTS\n" +
+		"    value: {{ .Values.env.appHosts | quote }}\n" +
+		"  - name: APP_DB_HOST\n" +
+		"    value: {{ .Values.env.appDbHost | quote }}\n"
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, sb.String())
+	}
+}
@@ -0,0 +1,209 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WatchOptions are the options of the Watch function.
+type WatchOptions struct {
+	Prefix   string        // prefix of the keys of the configuration
+	Mode     WatchMode     // how the changes of the file are found
+	Interval time.Duration // interval of the polling, one second if zero
+
+	// The reload is performed after the Debounce period without new
+	// changes of the file, but not earlier than MinInterval after the
+	// previous reload (see SetRateLimit of the Reloader). The zero
+	// values disable the limits.
+	Debounce    time.Duration
+	MinInterval time.Duration
+
+	// The OnChange is called after each reload that changed
+	// at least one field, with the list of the changes.
+	OnChange func([]FieldChange)
+
+	// The OnError is called with the error of reading or parsing the
+	// changed file or of the reload, the configuration keeps its
+	// previous values (see SetPolicy of the Reloader).
+	OnError func(error)
+}
+
+// Watcher keeps the configuration structure in sync with the env-file,
+// see Watch. It's the Reloader of the structure, so the fields are
+// changed under the lock: use RLock and RUnlock to read them.
+type Watcher struct {
+	*Reloader
+
+	filename string
+	onError  func(error)
+	keys     map[string]bool     // keys set by the file
+	orig     map[string]rawValue // values of the keys before the file
+
+	mu      sync.Mutex // serializes the reloads and Stop
+	stopped bool       // true if the watching is stopped
+
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the watching stops
+	err    error         // error of the FileWatcher, valid after done
+}
+
+// Watch loads the env-file, unmarshals the environment into obj (a
+// pointer to the structure) and watches the file in the background:
+// when the file changes, it's parsed again and applied to the
+// environment (the existing keys are updated, the keys removed from
+// the file get back the values they had before the file set them, or
+// are unset), and the changed fields are decoded again under the lock
+// of the Reloader, so the long-running service reloads its
+// configuration without restart. The changes are passed through the
+// Trigger of the Reloader, so the series of changes within the
+// Debounce period cause one reload. Call Stop to stop watching.
+//
+// Returns an error if the file can't be loaded, the object can't be
+// unmarshaled, or the notifications aren't supported in the
+// WatchNotify mode.
+//
+// # Examples
+//
+//	var cfg Config
+//	w, err := env.Watch(".env", &cfg, env.WatchOptions{
+//		Prefix:   "APP_",
+//		Debounce: 100 * time.Millisecond,
+//		OnChange: func(c []env.FieldChange) {
+//			log.Printf("configuration changed: %v", c)
+//		},
+//		OnError: func(err error) { log.Println(err) },
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer w.Stop()
+//	...
+//	w.RLock()
+//	host := cfg.Host
+//	w.RUnlock()
+func Watch(filename string, obj interface{},
+	opts WatchOptions) (*Watcher, error) {
+	if opts.Mode == WatchNotify {
+		n, err := newNotifier(filename)
+		if err != nil {
+			return nil, err
+		}
+		n.close()
+	}
+
+	w := &Watcher{
+		filename: filename,
+		onError:  opts.OnError,
+		keys:     make(map[string]bool),
+		orig:     make(map[string]rawValue),
+	}
+	if err := w.apply(); err != nil {
+		return nil, err
+	}
+
+	r, err := NewReloader(opts.Prefix, obj, opts.OnChange)
+	if err != nil {
+		return nil, err
+	}
+	r.SetRateLimit(opts.Debounce, opts.MinInterval)
+	r.run = w.reload
+	w.Reloader = r
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel, w.done = cancel, make(chan struct{})
+
+	fw := NewFileWatcher(filename, opts.Mode, opts.Interval, r.Trigger)
+	go func() {
+		defer close(w.done)
+		w.err = fw.Run(ctx)
+	}()
+
+	return w, nil
+}
+
+// Stop stops watching the file and waits for the running reload to
+// finish, the pending reload is dropped. Returns the error that
+// stopped the watching earlier, if any.
+func (w *Watcher) Stop() error {
+	w.cancel()
+	<-w.done
+
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+
+	if errors.Is(w.err, context.Canceled) {
+		return nil
+	}
+
+	return w.err
+}
+
+// The reload applies the changed file and reloads the configuration,
+// it's called by the Trigger of the Reloader.
+func (w *Watcher) reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+
+	err := w.apply()
+	if err == nil {
+		_, err = w.Reload()
+	} else {
+		w.limit.done(err)
+	}
+
+	if err != nil && w.onError != nil {
+		w.onError(err)
+	}
+}
+
+// The apply parses the file and applies it to the environment: the keys
+// are updated, the keys removed from the file since the last apply get
+// back the values they had before the file set them, or are unset if
+// they had none. The environment isn't changed if the file can't be
+// parsed.
+func (w *Watcher) apply() error {
+	values, err := Parse(w.filename)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for key := range w.keys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+
+		orig := w.orig[key]
+		if orig.ok {
+			err = Set(key, orig.value)
+		} else {
+			err = Unset(key)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+		delete(w.keys, key)
+		delete(w.orig, key)
+	}
+
+	for key := range values {
+		if !w.keys[key] {
+			value, ok := lookupenv(key)
+			w.keys[key] = true
+			w.orig[key] = rawValue{value: value, ok: ok}
+		}
+	}
+
+	if err := ApplyMap(values, true); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
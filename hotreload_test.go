@@ -0,0 +1,167 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWatch tests Watch function.
+func TestWatch(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST" def:"localhost"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	write := func(content string) {
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Clearenv()
+	write("APP_HOST=0.0.0.0\nAPP_PORT=8080\nAPP_DEBUG=true\n")
+
+	var (
+		c       config
+		changes = make(chan []FieldChange, 10)
+		errs    = make(chan error, 10)
+	)
+
+	w, err := Watch(filename, &c, WatchOptions{
+		Prefix:   "APP_",
+		Mode:     WatchPoll,
+		Interval: 10 * time.Millisecond,
+		OnChange: func(c []FieldChange) { changes <- c },
+		OnError:  func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Host != "0.0.0.0" || c.Port != 8080 || !c.Debug {
+		t.Errorf("expected `{0.0.0.0 8080 true}` but `%v`", c)
+	}
+
+	// The changed and removed keys.
+	time.Sleep(20 * time.Millisecond) // the watcher is started
+	write("APP_HOST=127.0.0.1\nAPP_PORT=9090\n")
+	select {
+	case list := <-changes:
+		if len(list) != 3 {
+			t.Errorf("expected 3 changes but `%v`", list)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected change but none")
+	}
+
+	w.RLock()
+	if c.Host != "127.0.0.1" || c.Port != 9090 || c.Debug {
+		t.Errorf("expected `{127.0.0.1 9090 false}` but `%v`", c)
+	}
+	w.RUnlock()
+
+	if Exists("APP_DEBUG") {
+		t.Error("the removed key should be unset")
+	}
+
+	// The incorrect file keeps the configuration.
+	write("APP_HOST=\n")
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected error but nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected error but none")
+	}
+
+	w.RLock()
+	if c.Host != "127.0.0.1" {
+		t.Errorf("expected `127.0.0.1` but `%s`", c.Host)
+	}
+	w.RUnlock()
+
+	if err := w.Stop(); err != nil {
+		t.Error(err)
+	}
+
+	// The missing file.
+	if _, err := Watch(filename+".missing", &c, WatchOptions{}); err == nil {
+		t.Error("an error is expected for the missing file")
+	}
+}
+
+// TestWatchDebounce tests the debounce of the changes and
+// the keys that were set before the file.
+func TestWatchDebounce(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Mode string `env:"MODE"`
+	}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	write := func(content string) {
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_MODE", "prod")
+	os.Setenv("APP_EXTRA", "1")
+	write("APP_HOST=0.0.0.0\nAPP_MODE=dev\n")
+
+	var (
+		c       config
+		changes = make(chan []FieldChange, 10)
+	)
+
+	w, err := Watch(filename, &c, WatchOptions{
+		Prefix:   "APP_",
+		Mode:     WatchPoll,
+		Interval: 10 * time.Millisecond,
+		Debounce: 200 * time.Millisecond,
+		OnChange: func(c []FieldChange) { changes <- c },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if c.Mode != "dev" {
+		t.Errorf("expected `dev` but `%s`", c.Mode)
+	}
+
+	// The series of changes causes one reload.
+	time.Sleep(20 * time.Millisecond) // the watcher is started
+	for _, host := range []string{"1.1.1.1", "2.2.2.2", "127.0.0.1"} {
+		write("APP_HOST=" + host + "\n")
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected change but none")
+	}
+
+	if s := w.Stats(); s.Reloads != 1 || s.Suppressed == 0 {
+		t.Errorf("expected one reload but %+v", s)
+	}
+
+	// The key removed from the file gets back its previous value,
+	// the keys that weren't set by the file are kept.
+	w.RLock()
+	if c.Host != "127.0.0.1" || c.Mode != "prod" {
+		t.Errorf("expected `{127.0.0.1 prod}` but `%v`", c)
+	}
+	w.RUnlock()
+
+	if v := Get("APP_EXTRA"); v != "1" {
+		t.Errorf("expected `1` but `%s`", v)
+	}
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// SizePolicy is the action for the value that exceeds
+// the limit of the length (see SetValueLimit).
+type SizePolicy uint32
+
+const (
+	// SizeReject rejects the value, the error wrapping
+	// ErrValueTooLong is returned for the key.
+	SizeReject SizePolicy = iota
+
+	// SizeTruncate truncates the value to the limit
+	// (without splitting the UTF-8 characters).
+	SizeTruncate
+)
+
+// String returns the name of the policy.
+func (p SizePolicy) String() string {
+	switch p {
+	case SizeReject:
+		return "reject"
+	case SizeTruncate:
+		return "truncate"
+	}
+
+	return fmt.Sprintf("SizePolicy(%d)", uint32(p))
+}
+
+// SizeWarning describes the value that exceeds the limit of the length.
+type SizeWarning struct {
+	Key       string // key name
+	Size      int    // length of the value in bytes
+	Limit     int    // limit of the length in bytes
+	Truncated bool   // true if the value is truncated, false if rejected
+}
+
+// String returns the warning as a single line for logging.
+func (w SizeWarning) String() string {
+	action := "rejected"
+	if w.Truncated {
+		action = "truncated"
+	}
+
+	return fmt.Sprintf("%s: value of %d bytes exceeds the limit of %d "+
+		"bytes, %s", w.Key, w.Size, w.Limit, action)
+}
+
+// ValueLimit is the limit of the length of the values
+// set into environment (see SetValueLimit).
+type ValueLimit struct {
+	Max    int               // maximum length in bytes, 0 is unlimited
+	Policy SizePolicy        // action for the longer values
+	Warn   func(SizeWarning) // called for each longer value, if any
+}
+
+// The valueLimit is the current limit of the length of the values.
+var valueLimit atomic.Pointer[ValueLimit]
+
+// SetValueLimit sets the limit of the length of the values set into
+// environment by Set, Load, Update, Marshal and other functions. Some
+// platforms cap the combined size of the environment, and the exec of
+// the child process fails later with E2BIG, so the longer values are
+// truncated or rejected by the policy when they are set, and the Warn
+// function (if any) names the offending keys. The zero limit disables
+// the check (it's the default). Returns the previous limit.
+//
+// # Examples
+//
+//	env.SetValueLimit(env.ValueLimit{
+//		Max:    4096,
+//		Policy: env.SizeTruncate,
+//		Warn:   func(w env.SizeWarning) { log.Println(w) },
+//	})
+func SetValueLimit(limit ValueLimit) ValueLimit {
+	var prev *ValueLimit
+	if limit.Max <= 0 {
+		prev = valueLimit.Swap(nil)
+	} else {
+		prev = valueLimit.Swap(&limit)
+	}
+
+	if prev == nil {
+		return ValueLimit{}
+	}
+
+	return *prev
+}
+
+// The limitValue checks the length of the value of the key by the
+// current limit, returns the value truncated by the SizeTruncate
+// policy or an error for the SizeReject policy.
+func limitValue(key, value string) (string, error) {
+	limit := valueLimit.Load()
+	if limit == nil || len(value) <= limit.Max {
+		return value, nil
+	}
+
+	w := SizeWarning{
+		Key:       key,
+		Size:      len(value),
+		Limit:     limit.Max,
+		Truncated: limit.Policy == SizeTruncate,
+	}
+	if limit.Warn != nil {
+		limit.Warn(w)
+	}
+
+	if !w.Truncated {
+		return "", &KeyError{Key: key, Err: fmt.Errorf(
+			"%w: %d bytes, limit %d", ErrValueTooLong, w.Size, w.Limit)}
+	}
+
+	// Don't split the last character.
+	end := limit.Max
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+
+	return value[:end], nil
+}
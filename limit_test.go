@@ -0,0 +1,61 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestSetValueLimit tests SetValueLimit function.
+func TestSetValueLimit(t *testing.T) {
+	var warnings []string
+	defer SetValueLimit(SetValueLimit(ValueLimit{
+		Max:    4,
+		Policy: SizeTruncate,
+		Warn:   func(w SizeWarning) { warnings = append(warnings, w.String()) },
+	}))
+
+	os.Clearenv()
+	Set("SHORT", "abcd")
+	Set("LONG", "abcdef")
+	Set("UTF", "ab€") // the euro sign is 3 bytes
+
+	tests := map[string]string{"SHORT": "abcd", "LONG": "abcd", "UTF": "ab"}
+	for key, value := range tests {
+		if v := Get(key); v != value {
+			t.Errorf("expected `%s` but `%s`", value, v)
+		}
+	}
+
+	expected := "LONG: value of 6 bytes exceeds the limit of 4 bytes, " +
+		"truncated"
+	if len(warnings) != 2 || warnings[0] != expected {
+		t.Errorf("expected `%s` but `%v`", expected, warnings)
+	}
+
+	// Reject the longer values.
+	SetValueLimit(ValueLimit{Max: 4, Policy: SizeReject})
+	err := LoadBytes([]byte("A=1\nB=12345\nC=3\n"), true)
+	if !errors.Is(err, ErrValueTooLong) {
+		t.Errorf("expected ErrValueTooLong but `%v`", err)
+	}
+
+	if v := Get("A"); v != "1" {
+		t.Errorf("expected `1` but `%s`", v)
+	}
+
+	if v, ok := Lookup("B"); ok {
+		t.Errorf("expected unset B but `%s`", v)
+	}
+
+	// The limit is disabled.
+	if v := SetValueLimit(ValueLimit{}); v.Policy != SizeReject {
+		t.Errorf("expected `reject` but `%s`", v.Policy)
+	}
+
+	value := strings.Repeat("a", 100)
+	if err := Set("LONG", value); err != nil || Get("LONG") != value {
+		t.Errorf("expected the value of 100 bytes but `%v`", err)
+	}
+}
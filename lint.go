@@ -0,0 +1,213 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Problem describes the problem with the tags of the structure field
+// found by ValidateStructTags.
+type Problem struct {
+	Field   string // Go-style path to the field, like DB.Host
+	Key     string // key name with prefixes of nested structures
+	Tag     string // name of the tag with the problem
+	Message string // description of the problem
+}
+
+// String returns the problem as a string.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s (%s): %s tag: %s",
+		p.Field, p.Key, p.Tag, p.Message)
+}
+
+// TagNames returns the names of the struct field tags used by the
+// package, like env, def and sep. It allows the tools that check the
+// structures statically (like the envtags analyzer) to find the
+// structures configured by the tags without copying the names.
+func TagNames() []string {
+	return []string{
+		tagNameKey, tagNameValue, tagNameSep, tagNameKVSep,
+		tagNameRequired, tagNameSecret, tagNameDesc, tagNameDecimal,
+		tagNamePath, tagNameDelims, tagNameSource,
+	}
+}
+
+// ValidateStructTags checks the tags of the structure recursively and
+// returns the list of found problems:
+//
+//   - invalid key names;
+//   - duplicate keys and aliases (after adding prefixes of nested
+//     structures);
+//   - def values that can't be parsed into the field type;
+//   - sep tags on fields that aren't arrays, slices or maps;
+//   - kvsep tags on fields that aren't maps or with empty separator;
+//   - decimal tags on fields that aren't floats or with
+//     separators other than comma and dot;
+//   - path tags on fields that aren't strings or with invalid options;
+//   - delims tags on fields that aren't templates or with invalid format;
+//   - required and secret tags with non-boolean values.
+//
+// It allows to find bugs of the configuration at the start of the
+// program or in unit tests. Returns an error if the obj isn't
+// a structure or pointer to a structure.
+//
+// # Examples
+//
+//	func TestConfigTags(t *testing.T) {
+//		problems, err := env.ValidateStructTags(Config{})
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//
+//		for _, p := range problems {
+//			t.Error(p)
+//		}
+//	}
+func ValidateStructTags(obj interface{}) ([]Problem, error) {
+	t := reflect.TypeOf(obj)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("obj should be a struct or pointer to struct")
+	}
+
+	l := linter{keys: make(map[string]string)}
+	l.lint("", "", t)
+	return l.problems, nil
+}
+
+// The linter collects problems of the structure tags.
+type linter struct {
+	keys     map[string]string // key name => field name
+	problems []Problem
+}
+
+// The lint checks fields of the structure type t recursively.
+func (l *linter) lint(prefix, name string, t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tg := newTagGroup(field)
+		tg.addPrefix(prefix)
+
+		add := func(tag, format string, a ...interface{}) {
+			l.problems = append(l.problems, Problem{
+				Field:   name + field.Name,
+				Key:     tg.key,
+				Tag:     tag,
+				Message: fmt.Sprintf(format, a...),
+			})
+		}
+
+		if !tg.isValid() {
+			add(tagNameKey, "invalid key name")
+		} else {
+			// The aliases are looked up as the keys,
+			// so they can't be shared with other fields too.
+			for i, key := range append([]string{tg.key}, tg.alias...) {
+				other, ok := l.keys[key]
+				switch {
+				case ok && i == 0:
+					add(tagNameKey, "duplicate key, also used by %s", other)
+				case ok:
+					add(tagNameKey, "duplicate alias %s, also used by %s",
+						key, other)
+				default:
+					l.keys[key] = name + field.Name
+				}
+			}
+		}
+
+		for _, tag := range []string{tagNameRequired, tagNameSecret} {
+			value, ok := field.Tag.Lookup(tag)
+			if _, err := parseBool(value); ok && err != nil {
+				add(tag, "invalid boolean value: %s", value)
+			}
+		}
+
+		if value, ok := field.Tag.Lookup(tagNameSource); ok {
+			if isNestedStruct(field.Type) {
+				add(tagNameSource, "field is a nested structure")
+			} else if strings.TrimSpace(value) == "" {
+				add(tagNameSource, "empty source name")
+			}
+		}
+
+		// The entries of the maps of structures are checked by the
+		// fields of the structure, the keys have the placeholder of
+		// the entry name (like DB_<NAME>_HOST).
+		if isStructMap(field.Type) {
+			entry := linter{keys: make(map[string]string)}
+			entry.lint("", name+field.Name+"["+mapNamePlaceholder+"].",
+				field.Type.Elem())
+			for _, p := range entry.problems {
+				p.Key = tg.key + "_" + mapNamePlaceholder + "_" + p.Key
+				l.problems = append(l.problems, p)
+			}
+			continue
+		}
+
+		// Nested structures are checked by their own fields.
+		if isNestedStruct(field.Type) {
+			l.lint(tg.key+"_", name+field.Name+".", field.Type)
+			continue
+		}
+
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = field.Type.Elem().Kind()
+		}
+
+		if _, ok := field.Tag.Lookup(tagNameSep); ok &&
+			kind != reflect.Array && kind != reflect.Slice &&
+			kind != reflect.Map {
+			add(tagNameSep, "field is not an array, slice or map")
+		}
+
+		if value, ok := field.Tag.Lookup(tagNameKVSep); ok {
+			if kind != reflect.Map {
+				add(tagNameKVSep, "field is not a map")
+			} else if value == "" {
+				add(tagNameKVSep, "empty separator")
+			}
+		}
+
+		if value, ok := field.Tag.Lookup(tagNameDecimal); ok {
+			if !isFloatType(field.Type) {
+				add(tagNameDecimal, "field is not a float")
+			} else if value != "," && value != "." {
+				add(tagNameDecimal, "invalid decimal separator: %q", value)
+			}
+		}
+
+		if value, ok := field.Tag.Lookup(tagNamePath); ok {
+			if !isStringType(field.Type) {
+				add(tagNamePath, "field is not a string")
+			} else if _, err := parsePathTag(value); err != nil {
+				add(tagNamePath, "%v", err)
+			}
+		}
+
+		if value, ok := field.Tag.Lookup(tagNameDelims); ok {
+			if !isTemplateType(field.Type) {
+				add(tagNameDelims, "field is not a template")
+			} else if _, _, err := parseDelims(value); err != nil {
+				add(tagNameDelims, "%v", err)
+			}
+		}
+
+		if _, ok := field.Tag.Lookup(tagNameValue); ok {
+			item := reflect.New(field.Type).Elem()
+			if err := setFieldValue(&item, tg, nil, nil); err != nil {
+				add(tagNameValue, "%v", err)
+			}
+		}
+	}
+}
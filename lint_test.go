@@ -0,0 +1,104 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateStructTags tests ValidateStructTags function.
+func TestValidateStructTags(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT" def:"port"`
+	}
+
+	type Config struct {
+		Host    string   `env:"HOST" def:"localhost"`
+		Port    int      `env:"PORT" def:"8080"`
+		Hosts   []string `env:"HOSTS" sep:","`
+		Name    string   `env:"NAME" sep:","`
+		Address string   `env:"HOST"`
+		Wrong   string   `env:"1_KEY"`
+		Debug   bool     `env:"DEBUG" required:"yes"`
+		Codes   [2]int   `env:"CODES" def:"1 2 3"`
+		DB      DB       `env:"DB"`
+		DBHost  string   `env:"DB_HOST"`
+		Rate    float64  `env:"RATE" decimal:";"`
+		Label   string   `env:"LABEL" decimal:","`
+		Pass    string   `env:"PASS" source:" "`
+		Vault   struct {
+			Token string `env:"TOKEN"`
+		} `env:"VAULT" source:"vault"`
+		Labels map[string]string `env:"LABELS" sep:"," kvsep:":"`
+		Tags   []string          `env:"TAGS" kvsep:":"`
+		Bad    map[string]string `env:"BAD" def:"a"`
+		Pools  map[string]DB     `env:"POOL"`
+		Server string            `env:"SERVER|HOST"`
+		Old    string            `env:"OLD|LEGACY"`
+		Legacy string            `env:"LEGACY"`
+	}
+
+	problems, err := ValidateStructTags(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"Name (NAME): sep tag: field is not an array, slice or map",
+		"Address (HOST): env tag: duplicate key, also used by Host",
+		"Wrong (1_KEY): env tag: invalid key name",
+		"Debug (DEBUG): required tag: invalid boolean value: yes",
+		"Codes (CODES): def tag: 3 overflows the [2]array",
+		"DB.Port (DB_PORT): def tag: ",
+		"DBHost (DB_HOST): env tag: duplicate key, also used by DB.Host",
+		`Rate (RATE): decimal tag: invalid decimal separator: ";"`,
+		"Label (LABEL): decimal tag: field is not a float",
+		"Pass (PASS): source tag: empty source name",
+		"Vault (VAULT): source tag: field is a nested structure",
+		"Tags (TAGS): kvsep tag: field is not a map",
+		`Bad (BAD): def tag: invalid map item: "a"`,
+		"Pools[<NAME>].Port (POOL_<NAME>_PORT): def tag: ",
+		"Server (SERVER): env tag: duplicate alias HOST, also used by Host",
+		"Legacy (LEGACY): env tag: duplicate key, also used by Old",
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems but %d: %v",
+			len(expected), len(problems), problems)
+	}
+
+	for i, p := range problems {
+		if !strings.HasPrefix(p.String(), expected[i]) {
+			t.Errorf("expected `%s` but `%s`", expected[i], p)
+		}
+	}
+
+	// Nested structure as root.
+	problems, err = ValidateStructTags(DB{})
+	if err != nil || len(problems) != 1 {
+		t.Errorf("expected one problem but %v (%v)", problems, err)
+	}
+
+	// Incorrect object.
+	if _, err := ValidateStructTags("struct"); err == nil {
+		t.Error("an error is expected for non-struct object")
+	}
+}
+
+// TestTagNames tests TagNames function.
+func TestTagNames(t *testing.T) {
+	names := TagNames()
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("duplicate tag name `%s`", name)
+		}
+		seen[name] = true
+	}
+
+	for _, name := range []string{"env", "def", "sep", "required"} {
+		if !seen[name] {
+			t.Errorf("expected `%s` in `%v`", name, names)
+		}
+	}
+}
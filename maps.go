@@ -0,0 +1,265 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The isStringMap returns true if the type is a map with string keys
+// and string values, like map[string]string.
+func isStringMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String &&
+		t.Elem().Kind() == reflect.String
+}
+
+// The setMap sets the items of the value like a=1,b=2 into the map,
+// the items are separated by the sep and the keys are separated from
+// the values by the kvsep (the first one, so the values can contain
+// it). The nil map is initialized, the existing items are kept.
+func setMap(item *reflect.Value, value, sep, kvsep string) error {
+	t := item.Type()
+	if !isStringMap(t) {
+		return fmt.Errorf("incorrect type: %s", t)
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	if item.IsNil() {
+		item.Set(reflect.MakeMap(t))
+	}
+
+	for _, pair := range splitList(value, sep) {
+		k, v, ok := strings.Cut(pair, kvsep)
+		if !ok || k == "" {
+			return fmt.Errorf("invalid map item: %q", pair)
+		}
+
+		item.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()),
+			reflect.ValueOf(v).Convert(t.Elem()))
+	}
+
+	return nil
+}
+
+// The getMap returns the items of the map as string, sorted by keys.
+// The items are escaped by the escapeItem to be unmarshaled back as is.
+func getMap(item reflect.Value, sep, kvsep string) (string, error) {
+	if !isStringMap(item.Type()) {
+		return "", fmt.Errorf("incorrect type: %s", item.Type())
+	}
+
+	keys := make([]string, 0, item.Len())
+	for _, k := range item.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		v := item.MapIndex(reflect.ValueOf(k).Convert(item.Type().Key()))
+		items[i] = escapeItem(k+kvsep+v.String(), sep)
+	}
+
+	return strings.Join(items, sep), nil
+}
+
+// The isStructMap returns true if the type is a map with string keys and
+// structures (or pointers to structures) as values, like map[string]DB.
+func isStructMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String &&
+		isNestedStruct(t.Elem())
+}
+
+// The mapNamePlaceholder is the placeholder of the entry name in the
+// keys of the maps of structures in the documentation, like
+// DB_<NAME>_HOST.
+const mapNamePlaceholder = "<NAME>"
+
+// The walkMapFields calls fn for each field of the entries of the map of
+// structures of the field fi (see walkFields). The keys of the fields
+// have the placeholder of the entry name (see mapNamePlaceholder) and
+// the path of the fields is relative to the entry.
+func walkMapFields(fi fieldInfo, fn func(fi fieldInfo) error) error {
+	prefix := fi.tg.key + "_" + mapNamePlaceholder + "_"
+	name := fi.name + "[" + mapNamePlaceholder + "]."
+	return walkFields("", fi.field.Type.Elem(), func(entry fieldInfo) error {
+		entry.tg.key = prefix + entry.tg.key
+		for i, alias := range entry.tg.alias {
+			entry.tg.alias[i] = prefix + alias
+		}
+		entry.name = name + entry.name
+
+		if isStructMap(entry.field.Type) {
+			return walkMapFields(entry, fn)
+		}
+		return fn(entry)
+	})
+}
+
+// The keysFunc returns the keys of the store, they are used to find the
+// entries of the maps of structures (see mapNames). The nil keysFunc
+// finds no entries.
+type keysFunc func() ([]string, error)
+
+// The environKeys is the keysFunc for the process environment.
+func environKeys() ([]string, error) {
+	items := environ()
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i], _, _ = strings.Cut(item, "=")
+	}
+
+	return keys, nil
+}
+
+// The mapNames returns the sorted names of the entries of the map of
+// structures t found in the keys of the store: the key like
+// PREFIX<NAME>_KEY gives the NAME if the KEY is the key of a field
+// of the structure, so the names can contain underscores.
+func mapNames(prefix string, t reflect.Type, keys keysFunc) ([]string, error) {
+	if keys == nil {
+		return nil, nil
+	}
+
+	fields, err := mapFieldKeys(t)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := keys()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range list {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+
+		for _, k := range fields {
+			name, ok := strings.CutSuffix(rest, "_"+k)
+			if ok && name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// The mapFieldKeys returns the keys (with the fallback names) of the
+// fields of the structure of the map of structures t, without prefix.
+func mapFieldKeys(t reflect.Type) ([]string, error) {
+	var keys []string
+	err := walkFields("", t.Elem(), func(fi fieldInfo) error {
+		keys = append(append(keys, fi.tg.key), fi.tg.alias...)
+		return nil
+	})
+
+	return keys, err
+}
+
+// The mapItems returns the sorted KEY=VALUE items of the environment
+// that belong to the entries of the map of structures t with the prefix,
+// like DB_MAIN_HOST=localhost for the prefix DB_.
+func mapItems(prefix string, t reflect.Type) ([]string, error) {
+	names, err := mapNames(prefix, t, environKeys)
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+
+	fields, err := mapFieldKeys(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for _, name := range names {
+		for _, k := range fields {
+			key := prefix + name + "_" + k
+			if value, ok := lookupenv(key); ok {
+				items = append(items, key+"="+value)
+			}
+		}
+	}
+	sort.Strings(items)
+
+	return items, nil
+}
+
+// The setStructMap unmarshals the entries of the map of structures by
+// the keys with the prefix, like DB_MAIN_HOST and DB_REPLICA_HOST give
+// the MAIN and REPLICA entries for the prefix DB_. The nil map is
+// initialized, the existing entries with other names are kept.
+func setStructMap(item *reflect.Value, prefix string,
+	lookup lookupFunc, keys keysFunc) error {
+	t := item.Type()
+	names, err := mapNames(prefix, t, keys)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+
+	if item.IsNil() {
+		item.Set(reflect.MakeMap(t))
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	for _, name := range names {
+		tmp := reflect.New(elem)
+		if err := unmarshalWith(lookup, keys, prefix+name+"_",
+			tmp.Interface()); err != nil {
+			return err
+		}
+
+		if t.Elem().Kind() != reflect.Ptr {
+			tmp = tmp.Elem()
+		}
+		item.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), tmp)
+	}
+
+	return nil
+}
+
+// The appendStructMap appends the entries of the map of structures
+// sorted by names, the keys of the entry have the prefix with the name
+// of the entry, like DB_MAIN_HOST for the prefix DB_ and MAIN entry.
+func appendStructMap(dst []string, prefix string, item reflect.Value,
+	set setFunc) ([]string, error) {
+	keys := item.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	result := dst
+	for _, k := range keys {
+		v := item.MapIndex(k)
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+
+		value, err := appendEnv(result, prefix+k.String()+"_",
+			v.Interface(), set)
+		if err != nil {
+			return value, err
+		}
+		result = value
+	}
+
+	return result, nil
+}
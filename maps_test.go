@@ -0,0 +1,162 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestUnmarshalEnvMap tests the map[string]string fields.
+func TestUnmarshalEnvMap(t *testing.T) {
+	type labels map[string]string
+
+	type data struct {
+		Labels  map[string]string `env:"LABELS" sep:","`
+		Headers labels            `env:"HEADERS" sep:";" kvsep:":"`
+		Empty   map[string]string `env:"EMPTY"`
+		Def     map[string]string `env:"DEF" def:"a=1 b=2"`
+	}
+
+	os.Clearenv()
+	Set("LABELS", `app=web,tier=front\,back,query=a=b`)
+	Set("HEADERS", "Accept:*/*;X-Id:1")
+
+	var d data
+	if err := unmarshalEnv("", &d); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{d.Labels, map[string]string{
+			"app": "web", "tier": "front,back", "query": "a=b"}},
+		{d.Headers, labels{"Accept": "*/*", "X-Id": "1"}},
+		{d.Def, map[string]string{"a": "1", "b": "2"}},
+	}
+
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.value, test.expected) {
+			t.Errorf("expected `%v` but `%v`", test.expected, test.value)
+		}
+	}
+
+	if d.Empty != nil {
+		t.Errorf("expected nil map but `%v`", d.Empty)
+	}
+
+	Set("LABELS", "app=web,tier")
+	if err := unmarshalEnv("", &data{}); err == nil {
+		t.Error("expected an error for the item without value")
+	}
+
+	// Unsupported map type.
+	var m struct {
+		Codes map[string]int `env:"CODES"`
+	}
+
+	Set("CODES", "a=1")
+	if err := unmarshalEnv("", &m); err == nil {
+		t.Error("expected an error for map[string]int")
+	}
+}
+
+// TestMarshalEnvMap tests marshaling of the map[string]string fields.
+func TestMarshalEnvMap(t *testing.T) {
+	type data struct {
+		Labels map[string]string `env:"LABELS" sep:","`
+		Nil    map[string]string `env:"NIL" kvsep:":"`
+	}
+
+	d := data{Labels: map[string]string{
+		"tier": "front,back", "app": "web"}}
+
+	os.Clearenv()
+	if _, err := marshalEnv("", d, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("LABELS"); v != `app=web,tier=front\,back` {
+		t.Errorf("expected `app=web,tier=front\\,back` but `%s`", v)
+	}
+
+	if v, ok := os.LookupEnv("NIL"); !ok || v != "" {
+		t.Errorf("expected empty value but `%s`", v)
+	}
+
+	// The values are unmarshaled back.
+	var r data
+	if err := unmarshalEnv("", &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(r.Labels, d.Labels) {
+		t.Errorf("expected `%v` but `%v`", d.Labels, r.Labels)
+	}
+}
+
+// TestUnmarshalEnvStructMap tests the maps of structures.
+func TestUnmarshalEnvStructMap(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST" def:"localhost"`
+		Port int    `env:"PORT"`
+	}
+
+	type data struct {
+		Databases map[string]db     `env:"DB" required:"true"`
+		Caches    map[string]*db    `env:"CACHE"`
+		Brokers   map[string]db     `env:"BROKER"`
+		Labels    map[string]string `env:"LABELS"`
+	}
+
+	os.Clearenv()
+	Set("APP_DB_MAIN_HOST", "db1")
+	Set("APP_DB_MAIN_PORT", "5432")
+	Set("APP_DB_READ_REPLICA_PORT", "5433")
+	Set("APP_DB_PORT", "1") // isn't an entry
+	Set("APP_CACHE_REDIS_HOST", "cache")
+
+	var d data
+	if err := unmarshalEnv("APP_", &d); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]db{
+		"MAIN":         {Host: "db1", Port: 5432},
+		"READ_REPLICA": {Host: "localhost", Port: 5433},
+	}
+	if !reflect.DeepEqual(d.Databases, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, d.Databases)
+	}
+
+	if c := d.Caches["REDIS"]; len(d.Caches) != 1 || c == nil ||
+		c.Host != "cache" {
+		t.Errorf("incorrect caches: %v", d.Caches)
+	}
+
+	if d.Brokers != nil {
+		t.Errorf("expected nil map but `%v`", d.Brokers)
+	}
+
+	Set("APP_DB_MAIN_PORT", "port")
+	if err := unmarshalEnv("APP_", &data{}); err == nil {
+		t.Error("expected an error for invalid port")
+	}
+
+	// Marshaling back.
+	os.Clearenv()
+	if _, err := marshalEnv("APP_", d, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{
+		"APP_DB_MAIN_HOST":         "db1",
+		"APP_DB_READ_REPLICA_PORT": "5433",
+		"APP_CACHE_REDIS_HOST":     "cache",
+	} {
+		if v := Get(key); v != value {
+			t.Errorf("expected `%s` but `%s` for %s", value, v, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DocMarkdown writes the reference of the configuration as a Markdown
+// table with the columns: Variable, Type, Default, Required and
+// Description. The table is built from the tags of the structure
+// (env, def, required, secret and desc), the nested structures are
+// processed recursively. The default values of secret fields are masked.
+// The fields of the entries of the maps of structures are described by
+// the keys with the placeholder of the entry name, like `DB_<NAME>_HOST`.
+//
+// The obj can be a structure or a pointer to a structure.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST" def:"localhost" desc:"server host"`
+//		Port int    `env:"PORT" required:"true" desc:"server port"`
+//	}
+//
+//	env.DocMarkdown(os.Stdout, "APP_", Config{})
+//
+//	// Output:
+//	// | Variable | Type | Default | Required | Description |
+//	// |---|---|---|---|---|
+//	// | `APP_HOST` | `string` | `localhost` | no | server host |
+//	// | `APP_PORT` | `int` |  | yes | server port |
+func DocMarkdown(w io.Writer, prefix string, obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return fmt.Errorf("incorrect type: %v", t)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Variable | Type | Default | Required | Description |\n")
+	sb.WriteString("|---|---|---|---|---|\n")
+
+	row := func(fi fieldInfo) error {
+		def := fi.tg.value
+		if fi.tg.secret && def != "" {
+			def = maskValue
+		}
+
+		if def != "" {
+			def = "`" + mdEscape(def) + "`"
+		}
+
+		required := "no"
+		if fi.tg.required {
+			required = "yes"
+		}
+
+		fmt.Fprintf(&sb, "| `%s` | `%s` | %s | %s | %s |\n",
+			fi.tg.key,
+			mdEscape(fi.field.Type.String()),
+			def,
+			required,
+			mdEscape(fi.tg.desc),
+		)
+		return nil
+	}
+
+	// The entries of the maps of structures are described
+	// by the pattern of their keys, like DB_<NAME>_HOST.
+	err := walkFields(prefix, t, func(fi fieldInfo) error {
+		if isStructMap(fi.field.Type) {
+			return walkMapFields(fi, row)
+		}
+		return row(fi)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// The mdEscape escapes the characters that break the Markdown table.
+func mdEscape(str string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "`", "'").Replace(str)
+}
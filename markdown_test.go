@@ -0,0 +1,43 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDocMarkdown tests DocMarkdown function.
+func TestDocMarkdown(t *testing.T) {
+	type DB struct {
+		Password string `env:"PASSWORD" def:"qwerty" secret:"true"`
+	}
+
+	type Config struct {
+		Host  string        `env:"HOST" def:"localhost" desc:"server host"`
+		Port  int           `env:"PORT" required:"true" desc:"a | b"`
+		Hosts []string      `env:"HOSTS"`
+		DB    *DB           `env:"DB"`
+		Pools map[string]DB `env:"POOL"`
+	}
+
+	var sb strings.Builder
+	if err := DocMarkdown(&sb, "APP_", &Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		"| Variable | Type | Default | Required | Description |\n" +
+		"|---|---|---|---|---|\n" +
+		"| `APP_HOST` | `string` | `localhost` | no | server host |\n" +
+		"| `APP_PORT` | `int` |  | yes | a \\| b |\n" +
+		"| `APP_HOSTS` | `[]string` |  | no |  |\n" +
+		"| `APP_DB_PASSWORD` | `string` | `******` | no |  |\n" +
+		"| `APP_POOL_<NAME>_PASSWORD` | `string` | `******` | no |  |\n"
+
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, sb.String())
+	}
+
+	if err := DocMarkdown(&sb, "", 1); err == nil {
+		t.Error("an error is expected for non-struct object")
+	}
+}
@@ -0,0 +1,112 @@
+package env
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+// The maskValue is the value displayed instead of the masked secrets.
+const maskValue = "******"
+
+// The defMaskPatterns is the list of key patterns whose
+// values are masked if no patterns are specified.
+var defMaskPatterns = []string{
+	"*PASSWORD*",
+	"*PASSWD*",
+	"*SECRET*",
+	"*TOKEN*",
+	"*CREDENTIAL*",
+	"*PRIVATE*",
+	"*_KEY",
+}
+
+// The isMasked returns true if the key matches one of the patterns.
+// Patterns use the path.Match syntax and are case-insensitive.
+func isMasked(key string, patterns []string) bool {
+	key = strings.ToUpper(key)
+	for _, pattern := range patterns {
+		ok, err := path.Match(strings.ToUpper(pattern), key)
+		if err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The effectiveConfig returns key/value pairs of the object (as Marshal
+// would set them into environment) with the values of the keys that
+// match the patterns replaced by the mask. The environment isn't changed.
+//
+// The values of the fields marked by the secret tag are always masked.
+// If patterns is nil, the default patterns are used
+// (keys containing PASSWORD, SECRET, TOKEN etc.).
+func effectiveConfig(prefix string, obj interface{},
+	patterns []string) ([]pair, error) {
+	if patterns == nil {
+		patterns = defMaskPatterns
+	}
+
+	items, err := marshalEnv(prefix, obj, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fields marked by the secret tag and
+	// the SecretString fields are masked too.
+	secrets := make(map[string]bool)
+	secretKeys(prefix, reflect.ValueOf(obj), secrets)
+
+	result := make([]pair, 0, len(items))
+	for _, item := range items {
+		// The custom marshalers can return keys only.
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			value = Get(key)
+		}
+
+		if secrets[key] || isMasked(key, patterns) {
+			value = maskValue
+		}
+
+		result = append(result, pair{key: key, value: value})
+	}
+
+	return result, nil
+}
+
+// The secretKeys adds the keys of the fields of the object v marked by
+// the secret tag and of the SecretString fields to the secrets, the keys
+// of the entries of the maps of structures are added by their names,
+// like DB_MAIN_PASSWORD.
+func secretKeys(prefix string, v reflect.Value,
+	secrets map[string]bool) {
+	walkFields(prefix, v.Type(), func(fi fieldInfo) error {
+		if isStructMap(fi.field.Type) {
+			item, ok := fieldValue(v, fi.path)
+			if !ok {
+				return nil
+			}
+
+			for _, k := range item.MapKeys() {
+				entry := item.MapIndex(k)
+				if entry.Kind() == reflect.Ptr && entry.IsNil() {
+					continue
+				}
+				secretKeys(fi.tg.key+"_"+k.String()+"_", entry, secrets)
+			}
+			return nil
+		}
+
+		t := fi.field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if fi.tg.secret || t == secretStringType {
+			secrets[fi.tg.key] = true
+		}
+		return nil
+	})
+}
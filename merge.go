@@ -0,0 +1,151 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// MergeConfigs merges fields of the src structure into the dst structure.
+// The fields are matched by their keys in the environment (taking into
+// account the env tags and nested structures), so the dst and src can
+// be different types with the same keys.
+//
+// The zero fields of the src are considered as not set and are skipped.
+// If overwriteZeroOnly is true, only the zero fields of the dst are
+// filled, otherwise the fields of the dst are overwritten by the src.
+// The values are deep copied: slices, maps, pointers and exported fields
+// of the structures of the dst don't share memory with the src (the
+// unexported fields of the structures are copied shallowly, except
+// for SecretString that gets its own copy of the secret). The fields
+// of the unexported nested structures are skipped.
+//
+// The dst must be a non-nil pointer to a structure, the src must be
+// a structure or a pointer to a structure. Returns an error if the
+// fields with the same key have incompatible types.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST"`
+//		Port int    `env:"PORT"`
+//	}
+//
+//	defaults := Config{Host: "localhost", Port: 8080}
+//	var config Config
+//	env.Unmarshal("", &config)               // PORT=80 only
+//	env.MergeConfigs(&config, defaults, true) // {localhost 80}
+func MergeConfigs(dst, src interface{}, overwriteZeroOnly bool) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() ||
+		dv.Elem().Kind() != reflect.Struct {
+		return errors.New("dst should be a non-nil pointer to a struct")
+	}
+
+	sv := reflect.Indirect(reflect.ValueOf(src))
+	if sv.Kind() != reflect.Struct {
+		return errors.New("src should be an initialized struct")
+	}
+
+	// Collect the fields of the source by the keys.
+	fields := make(map[string]fieldInfo)
+	err := walkFields("", sv.Type(), func(fi fieldInfo) error {
+		fields[fi.tg.key] = fi
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return walkFields("", dv.Type(), func(fi fieldInfo) error {
+		sfi, ok := fields[fi.tg.key]
+		if !ok || !isExportedPath(dv.Type(), fi.path) ||
+			!isExportedPath(sv.Type(), sfi.path) {
+			return nil
+		}
+
+		if !sfi.field.Type.AssignableTo(fi.field.Type) {
+			return fmt.Errorf(
+				"the %s field has incompatible type: %s instead of %s",
+				fi.name,
+				sfi.field.Type,
+				fi.field.Type,
+			)
+		}
+
+		s, ok := fieldValue(sv, sfi.path)
+		if !ok || s.IsZero() {
+			return nil
+		}
+
+		if d, ok := fieldValue(dv, fi.path); overwriteZeroOnly && ok &&
+			!d.IsZero() {
+			return nil
+		}
+
+		allocFieldValue(dv, fi.path).Set(deepCopy(s))
+		return nil
+	})
+}
+
+// The deepCopy returns a copy of the value that doesn't share memory
+// of slices, maps, pointers and exported fields of the structures with
+// the original value. The unexported fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	// The secret is copied into its own memory.
+	if v.Type() == secretStringType {
+		if secret := v.Interface().(SecretString); !secret.IsZero() {
+			return reflect.ValueOf(NewSecretString(secret.Value()))
+		}
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+
+	return v
+}
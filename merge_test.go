@@ -0,0 +1,134 @@
+package env
+
+import (
+	"net/url"
+	"testing"
+)
+
+// TestMergeConfigs tests MergeConfigs function.
+func TestMergeConfigs(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	type Config struct {
+		Name  string   `env:"NAME"`
+		Hosts []string `env:"HOSTS"`
+		DB    *DB      `env:"DB"`
+	}
+
+	defaults := Config{
+		Name:  "app",
+		Hosts: []string{"a", "b"},
+		DB:    &DB{Host: "localhost", Port: 5432},
+	}
+
+	// Fill zero fields only.
+	config := Config{Name: "custom", DB: &DB{Port: 3306}}
+	if err := MergeConfigs(&config, defaults, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Name != "custom" || config.DB.Port != 3306 ||
+		config.DB.Host != "localhost" || len(config.Hosts) != 2 {
+		t.Errorf("incorrect result: %v %v", config, config.DB)
+	}
+
+	// Deep copy.
+	config.Hosts[0] = "c"
+	if defaults.Hosts[0] != "a" {
+		t.Error("the slice shouldn't be shared")
+	}
+
+	// Overwrite by non-zero fields, the nil pointer is allocated.
+	config = Config{Name: "custom"}
+	override := Config{DB: &DB{Host: "example.com"}}
+	if err := MergeConfigs(&config, &override, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Name != "custom" || config.DB == nil ||
+		config.DB.Host != "example.com" || config.DB == override.DB {
+		t.Errorf("incorrect result: %v %v", config, config.DB)
+	}
+}
+
+// TestMergeConfigsTypes tests MergeConfigs function with different types.
+func TestMergeConfigsTypes(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	type Override struct {
+		Address string `env:"HOST"`
+		Debug   bool   `env:"DEBUG"`
+	}
+
+	config := Config{Host: "localhost", Port: 80}
+	if err := MergeConfigs(&config, Override{"example.com", true},
+		false); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Host != "example.com" || config.Port != 80 {
+		t.Errorf("incorrect result: %v", config)
+	}
+
+	// Incompatible types.
+	type Wrong struct {
+		Port string `env:"PORT"`
+	}
+
+	if err := MergeConfigs(&config, Wrong{"80"}, false); err == nil {
+		t.Error("an error is expected for incompatible types")
+	}
+
+	// Incorrect arguments.
+	if err := MergeConfigs(config, Wrong{}, false); err == nil {
+		t.Error("an error is expected for non-pointer dst")
+	}
+}
+
+// TestMergeConfigsUnexported tests MergeConfigs function with
+// the unexported nested structures and the value structures.
+func TestMergeConfigsUnexported(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST"`
+	}
+
+	type Config struct {
+		Name   string       `env:"NAME"`
+		db     db           `env:"DB"`
+		URL    *url.URL     `env:"URL"`
+		Secret SecretString `env:"SECRET"`
+	}
+
+	src := Config{
+		Name:   "app",
+		db:     db{Host: "localhost"},
+		URL:    &url.URL{Scheme: "https", User: url.User("admin")},
+		Secret: NewSecretString("secret"),
+	}
+
+	var dst Config
+	if err := MergeConfigs(&dst, src, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Name != "app" || dst.db.Host != "" {
+		t.Errorf("incorrect result: %+v", dst)
+	}
+
+	// The values don't share memory.
+	if dst.URL == src.URL || dst.URL.User == src.URL.User ||
+		dst.URL.User.Username() != "admin" {
+		t.Errorf("the url is shared: %v", dst.URL)
+	}
+
+	src.Secret.Destroy()
+	if v := dst.Secret.Value(); v != "secret" {
+		t.Errorf("expected `secret` but `%s`", v)
+	}
+}
@@ -0,0 +1,49 @@
+package env
+
+import "fmt"
+
+// MustGet works like GetRequired, but panics if the variable named
+// by the key is not present. It's intended for the configuration in
+// the main or init functions, where the error is unrecoverable anyway.
+//
+// # Examples
+//
+//	dsn := env.MustGet("DATABASE_URL")
+func MustGet(key string) string {
+	value, err := GetRequired(key)
+	if err != nil {
+		panic(fmt.Sprintf("env: %v", err))
+	}
+
+	return value
+}
+
+// MustGetAs works like GetAs, but panics if the value can't be converted
+// to the type T. The missing variable without default value is the zero
+// value, use MustGet to require it.
+//
+// # Examples
+//
+//	port := env.MustGetAs[int]("PORT", 8080)
+func MustGetAs[T any](key string, def ...T) T {
+	value, err := GetAs(key, def...)
+	if err != nil {
+		panic(fmt.Sprintf("env: get %s as %T: %v", key, value, err))
+	}
+
+	return value
+}
+
+// MustUnmarshal works like Unmarshal, but panics if the environment
+// can't be unmarshaled into the obj.
+//
+// # Examples
+//
+//	var cfg Config
+//	env.MustUnmarshal("APP_", &cfg)
+func MustUnmarshal(prefix string, obj interface{}) {
+	if err := Unmarshal(prefix, obj); err != nil {
+		panic(fmt.Sprintf("env: unmarshal %T with prefix %q: %v",
+			obj, prefix, err))
+	}
+}
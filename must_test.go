@@ -0,0 +1,79 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// The panicMessage returns the message of the panic of the fn,
+// the empty string if the fn doesn't panic.
+func panicMessage(fn func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = r.(string)
+		}
+	}()
+
+	fn()
+	return ""
+}
+
+// TestMustGet tests MustGet and MustGetAs functions.
+func TestMustGet(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("PORT", "port")
+
+	if v := MustGet("HOST"); v != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", v)
+	}
+
+	if v := MustGetAs("TIMEOUT", 5); v != 5 {
+		t.Errorf("expected `5` but `%d`", v)
+	}
+
+	tests := []struct {
+		fn       func()
+		expected string
+	}{
+		{func() { MustGet("USER") },
+			"env: required key is missing: USER"},
+		{func() { MustGetAs[int]("PORT") },
+			"env: get PORT as int: PORT: "},
+	}
+
+	for _, test := range tests {
+		msg := panicMessage(test.fn)
+		if !strings.HasPrefix(msg, test.expected) {
+			t.Errorf("expected `%s` but `%s`", test.expected, msg)
+		}
+	}
+}
+
+// TestMustUnmarshal tests MustUnmarshal function.
+func TestMustUnmarshal(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_HOST", "localhost")
+
+	var cfg config
+	if msg := panicMessage(func() { MustUnmarshal("APP_", &cfg) }); msg != "" {
+		t.Errorf("unexpected panic: %s", msg)
+	}
+
+	if cfg.Host != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", cfg.Host)
+	}
+
+	os.Setenv("APP_PORT", "port")
+	msg := panicMessage(func() { MustUnmarshal("APP_", &cfg) })
+	expected := `env: unmarshal *env.config with prefix "APP_": `
+	if !strings.HasPrefix(msg, expected) {
+		t.Errorf("expected `%s` but `%s`", expected, msg)
+	}
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyFromFieldName converts the Go-style field name to the key name
+// in the environment style: words are written in upper case and
+// separated by the underscore.
+//
+// A new word starts with an upper case letter that follows a lower case
+// letter or a digit, and with the last upper case letter of an acronym
+// if a lower case letter follows it.
+//
+// # Examples
+//
+//	env.KeyFromFieldName("Host")         // "HOST"
+//	env.KeyFromFieldName("MaxIdleConns") // "MAX_IDLE_CONNS"
+//	env.KeyFromFieldName("APIKey")       // "API_KEY"
+//	env.KeyFromFieldName("HTTP2Server")  // "HTTP2_SERVER"
+//	env.KeyFromFieldName("KeyA")         // "KEY_A"
+func KeyFromFieldName(name string) string {
+	var (
+		sb    strings.Builder
+		runes = []rune(name)
+	)
+
+	sb.Grow(len(name) + 4)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := rune(0)
+			if i+1 < len(runes) {
+				next = runes[i+1]
+			}
+
+			// The beginning of a new word.
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && unicode.IsLower(next)) {
+				sb.WriteRune('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+
+	return sb.String()
+}
+
+// FieldNameFromKey converts the key name in the environment style
+// to the Go-style field name: the key is split into words by the
+// underscore, the first letter of each word is written in upper case
+// and the rest of the letters in lower case.
+//
+// # Examples
+//
+//	env.FieldNameFromKey("HOST")           // "Host"
+//	env.FieldNameFromKey("MAX_IDLE_CONNS") // "MaxIdleConns"
+//	env.FieldNameFromKey("KEY_A")          // "KeyA"
+//	env.FieldNameFromKey("key__b")         // "KeyB"
+func FieldNameFromKey(key string) string {
+	var sb strings.Builder
+
+	sb.Grow(len(key))
+	for _, word := range strings.Split(key, "_") {
+		if word == "" {
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(word)
+		sb.WriteRune(unicode.ToUpper(r))
+		sb.WriteString(strings.ToLower(word[size:]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package env
+
+import "testing"
+
+// TestKeyFromFieldName tests KeyFromFieldName function.
+func TestKeyFromFieldName(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"Host":         "HOST",
+		"host":         "HOST",
+		"KeyA":         "KEY_A",
+		"MaxIdleConns": "MAX_IDLE_CONNS",
+		"APIKey":       "API_KEY",
+		"ID":           "ID",
+		"UserID":       "USER_ID",
+		"HTTP2Server":  "HTTP2_SERVER",
+		"Port8080":     "PORT8080",
+		"Key_Name":     "KEY_NAME",
+	}
+
+	for name, key := range tests {
+		if v := KeyFromFieldName(name); v != key {
+			t.Errorf("for `%s` expected `%s` but `%s`", name, key, v)
+		}
+	}
+}
+
+// TestFieldNameFromKey tests FieldNameFromKey function.
+func TestFieldNameFromKey(t *testing.T) {
+	tests := map[string]string{
+		"":               "",
+		"HOST":           "Host",
+		"KEY_A":          "KeyA",
+		"MAX_IDLE_CONNS": "MaxIdleConns",
+		"key__b":         "KeyB",
+		"_KEY_":          "Key",
+		"PORT8080":       "Port8080",
+	}
+
+	for key, name := range tests {
+		if v := FieldNameFromKey(key); v != name {
+			t.Errorf("for `%s` expected `%s` but `%s`", key, name, v)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// NumberFormat is the set of the accepted forms of the values
+// of the integer fields (see SetNumberFormat).
+type NumberFormat uint32
+
+const (
+	// NumberSign accepts the leading plus sign, like +5.
+	NumberSign NumberFormat = 1 << iota
+
+	// NumberPrefix accepts the base prefixes: 0x1F (hexadecimal),
+	// 0o17 (octal) and 0b101 (binary), the prefixes are case-insensitive.
+	// The decimal numbers with leading zeros, like 017, are still decimal.
+	NumberPrefix
+
+	// NumberUnderscore accepts the underscores between
+	// digits to separate groups, like 1_000_000.
+	NumberUnderscore
+
+	// NumberStrict accepts the plain decimal numbers
+	// with the optional minus sign only.
+	NumberStrict NumberFormat = 0
+
+	// NumberDefault is the default format: the plain decimal
+	// numbers with the optional minus or plus sign.
+	NumberDefault = NumberSign
+
+	// NumberPermissive accepts all supported forms.
+	NumberPermissive = NumberSign | NumberPrefix | NumberUnderscore
+)
+
+// The numberFormat is the current format of the integer values.
+var numberFormat atomic.Uint32
+
+// Initializer.
+func init() {
+	numberFormat.Store(uint32(NumberDefault))
+}
+
+// SetNumberFormat sets the accepted forms of the values of the integer
+// fields (int, uint and their sized variants, including the items of
+// arrays and slices) for Unmarshal and other decoding functions. Returns
+// the previous format.
+//
+// The float fields aren't affected, they are parsed by strconv.ParseFloat.
+//
+// # Examples
+//
+//	// Accept the values like 0x1F and 1_000.
+//	env.SetNumberFormat(env.NumberPermissive)
+//
+//	// Accept the plain decimal numbers only, +5 is an error.
+//	env.SetNumberFormat(env.NumberStrict)
+func SetNumberFormat(format NumberFormat) NumberFormat {
+	return NumberFormat(numberFormat.Swap(uint32(format)))
+}
+
+// The normalizeNumber checks the integer value by the current number
+// format and returns the value without the plus sign, the base prefix
+// and the underscores, and the base of the value for strconv.
+func normalizeNumber(value string) (string, int, error) {
+	format := NumberFormat(numberFormat.Load())
+
+	sign, digits := "", value
+	if value != "" && (value[0] == '+' || value[0] == '-') {
+		sign, digits = value[:1], value[1:]
+		if sign == "+" {
+			if format&NumberSign == 0 {
+				return "", 0, fmt.Errorf(
+					"'%s' has the plus sign, not allowed", value)
+			}
+			sign = ""
+		}
+	}
+
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+
+		if base != 10 {
+			if format&NumberPrefix == 0 {
+				return "", 0, fmt.Errorf(
+					"'%s' has the base prefix, not allowed", value)
+			}
+			digits = digits[2:]
+		}
+	}
+
+	if strings.Contains(digits, "_") {
+		if format&NumberUnderscore == 0 {
+			return "", 0, fmt.Errorf(
+				"'%s' has underscores, not allowed", value)
+		}
+
+		// The underscore must separate digits.
+		if digits[0] == '_' || digits[len(digits)-1] == '_' ||
+			strings.Contains(digits, "__") {
+			return "", 0, fmt.Errorf(
+				"'%s' has misplaced underscores", value)
+		}
+		digits = strings.ReplaceAll(digits, "_", "")
+	}
+
+	return sign + digits, base, nil
+}
+
+// The isFloatType returns true if the type is float or a pointer,
+// array or slice of floats.
+func isFloatType(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Array, reflect.Slice:
+			t = t.Elem()
+		case reflect.Float32, reflect.Float64:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// The localizeFloats converts the float value (or the items of the
+// sequence value) of the field of the type t from the local format
+// set by the decimal tag to the format of strconv.ParseFloat.
+func localizeFloats(t reflect.Type, tg *tagGroup) (string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Array && t.Kind() != reflect.Slice {
+		return localizeFloat(tg.value, tg.decimal)
+	}
+
+	seq := splitList(tg.value, tg.sep)
+	for i, item := range seq {
+		value, err := localizeFloat(item, tg.decimal)
+		if err != nil {
+			return "", err
+		}
+		seq[i] = value
+	}
+
+	return joinList(seq, tg.sep), nil
+}
+
+// The localizeFloat converts the float value with the decimal separator
+// (comma or dot) and the optional thousands separators to the format of
+// strconv.ParseFloat. The thousands separator is a dot or a comma (the one
+// that isn't the decimal separator), a space or an apostrophe, it must
+// separate groups of three digits, like 1.234.567,89 or 1 234,5.
+func localizeFloat(value, decimal string) (string, error) {
+	if decimal != "," && decimal != "." {
+		return "", fmt.Errorf("invalid decimal separator: %q", decimal)
+	}
+
+	// The integer part is before the decimal separator
+	// (or before the exponent, if there is no fraction).
+	end := strings.Index(value, decimal)
+	if end < 0 {
+		end = strings.IndexAny(value, "eE")
+	}
+	if end < 0 {
+		end = len(value)
+	}
+
+	var sb strings.Builder
+	group, groups := 0, 0 // digits in the current group, separated groups
+	for _, r := range value[:end] {
+		switch {
+		case unicode.IsDigit(r):
+			sb.WriteRune(r)
+			group++
+		case r == ' ' || r == '\'' || r == '\u00a0' || r == '\u202f' ||
+			(r == '.' || r == ',') && string(r) != decimal:
+			if group == 0 || group > 3 || (groups > 0 && group != 3) {
+				return "", fmt.Errorf("'%s' has misplaced thousands "+
+					"separators", value)
+			}
+			group, groups = 0, groups+1
+		default:
+			sb.WriteRune(r) // sign or invalid character for ParseFloat
+		}
+	}
+
+	if groups > 0 && group != 3 {
+		return "", fmt.Errorf("'%s' has misplaced thousands separators",
+			value)
+	}
+
+	rest := value[end:]
+	if strings.HasPrefix(rest, decimal) {
+		rest = "." + rest[1:]
+	}
+
+	return sb.String() + rest, nil
+}
@@ -0,0 +1,139 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestSetNumberFormat tests the accepted forms of the integer values.
+func TestSetNumberFormat(t *testing.T) {
+	defer SetNumberFormat(NumberDefault)
+
+	tests := []struct {
+		format NumberFormat
+		value  string
+		want   int64
+		ok     bool
+	}{
+		{NumberDefault, "5", 5, true},
+		{NumberDefault, "-5", -5, true},
+		{NumberDefault, "+5", 5, true},
+		{NumberDefault, "017", 17, true},
+		{NumberDefault, "0x1F", 0, false},
+		{NumberDefault, "1_000", 0, false},
+		{NumberStrict, "+5", 0, false},
+		{NumberStrict, "-5", -5, true},
+		{NumberPermissive, "0x1F", 31, true},
+		{NumberPermissive, "-0X1f", -31, true},
+		{NumberPermissive, "0o17", 15, true},
+		{NumberPermissive, "0b101", 5, true},
+		{NumberPermissive, "017", 17, true},
+		{NumberPermissive, "+1_000", 1000, true},
+		{NumberPermissive, "1__000", 0, false},
+		{NumberPermissive, "_1000", 0, false},
+		{NumberPermissive, "0x", 0, false},
+		{NumberPrefix, "1_000", 0, false},
+		{NumberUnderscore, "0b1", 0, false},
+	}
+
+	for _, test := range tests {
+		SetNumberFormat(test.format)
+		r, err := strToIntKind(test.value, reflect.Int64)
+		if ok := err == nil; ok != test.ok || r != test.want {
+			t.Errorf("%d: %s: expected `%d, %t` but `%d, %v`",
+				test.format, test.value, test.want, test.ok, r, err)
+		}
+	}
+}
+
+// TestSetNumberFormatUint tests the unsigned integer values.
+func TestSetNumberFormatUint(t *testing.T) {
+	defer SetNumberFormat(NumberDefault)
+
+	if prev := SetNumberFormat(NumberPermissive); prev != NumberDefault {
+		t.Errorf("expected `%d` but `%d`", NumberDefault, prev)
+	}
+
+	if r, err := strToUintKind("0xFF", reflect.Uint8); err != nil ||
+		r != 255 {
+		t.Errorf("expected `255` but `%d, %v`", r, err)
+	}
+
+	if _, err := strToUintKind("0x100", reflect.Uint8); err == nil {
+		t.Error("expected an out of range error")
+	}
+
+	if _, err := strToUintKind("-1", reflect.Uint); err == nil {
+		t.Error("expected an error for negative value")
+	}
+}
+
+// TestLocalizeFloat tests the conversion of the local float values.
+func TestLocalizeFloat(t *testing.T) {
+	tests := []struct {
+		value   string
+		decimal string
+		want    string
+		ok      bool
+	}{
+		{"3,14", ",", "3.14", true},
+		{"-1.234.567,89", ",", "-1234567.89", true},
+		{"1 234,5", ",", "1234.5", true},
+		{"1'234", ",", "1234", true},
+		{"1,5e3", ",", "1.5e3", true},
+		{"1,234.5", ".", "1234.5", true},
+		{"12", ".", "12", true},
+		{"1.5", ",", "", false},
+		{"1234.567,8", ",", "", false},
+		{".234", ",", "", false},
+		{"1.23", ",", "", false},
+		{"1,5", ";", "", false},
+	}
+
+	for _, test := range tests {
+		r, err := localizeFloat(test.value, test.decimal)
+		if ok := err == nil; ok != test.ok || r != test.want {
+			t.Errorf("%s: expected `%s, %t` but `%s, %v`",
+				test.value, test.want, test.ok, r, err)
+		}
+	}
+}
+
+// TestUnmarshalDecimal tests the float fields with the decimal tag.
+func TestUnmarshalDecimal(t *testing.T) {
+	type config struct {
+		Rate   float64    `env:"RATE" decimal:","`
+		Ptr    *float32   `env:"PTR" decimal:","`
+		Prices []float64  `env:"PRICES" sep:";" decimal:","`
+		Limits [2]float64 `env:"LIMITS" decimal:"." def:"1,000.5 2"`
+	}
+
+	os.Clearenv()
+	Set("RATE", "3,14")
+	Set("PTR", "1.234,5")
+	Set("PRICES", "1,5;2.000,25")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Rate != 3.14 || data.Ptr == nil || *data.Ptr != 1234.5 {
+		t.Errorf("expected `3.14, 1234.5` but `%v, %v`", data.Rate, data.Ptr)
+	}
+
+	expected := []float64{1.5, 2000.25}
+	if !reflect.DeepEqual(data.Prices, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, data.Prices)
+	}
+
+	if data.Limits != [2]float64{1000.5, 2} {
+		t.Errorf("expected `[1000.5 2]` but `%v`", data.Limits)
+	}
+
+	Set("RATE", "3.14")
+	if err := Unmarshal("", &data); err == nil {
+		t.Error("expected an error for misplaced separator")
+	}
+}
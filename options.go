@@ -0,0 +1,68 @@
+package env
+
+// The loadOptions are the options of loading the env-file.
+type loadOptions struct {
+	expand bool   // true if the variables are expanded
+	update bool   // true if the existing keys are updated
+	forced bool   // true if the incorrect lines are ignored
+	prefix string // prefix of the keys to load, if any
+}
+
+// Option is the option of loading the env-file by the LoadWith function.
+type Option func(*loadOptions)
+
+// WithExpand sets whether the variables like ${var} or $var in the
+// values are expanded (true by default, like Load does).
+func WithExpand(enabled bool) Option {
+	return func(o *loadOptions) { o.expand = enabled }
+}
+
+// WithUpdate sets whether the keys that are already set in the
+// environment are updated (false by default, like Load does).
+func WithUpdate(enabled bool) Option {
+	return func(o *loadOptions) { o.update = enabled }
+}
+
+// WithForced sets whether the incorrect lines of the env-file are
+// ignored instead of returning an error (false by default).
+func WithForced(enabled bool) Option {
+	return func(o *loadOptions) { o.forced = enabled }
+}
+
+// WithPrefixFilter sets the prefix of the keys to load, the keys
+// without the prefix are skipped (all keys are loaded by default).
+// The skipped keys can still be used in the expansion if they're
+// already set in the environment.
+func WithPrefixFilter(prefix string) Option {
+	return func(o *loadOptions) { o.prefix = prefix }
+}
+
+// LoadWith loads the keys from the env-file into environment with the
+// options. Without options it works like Load: the new keys only are
+// loaded, the variables like ${var} or $var in the values are expanded
+// and an error is returned for the incorrect line. So the functions
+// like Update or LoadSafe are the shortcuts of the options.
+//
+// Returns an error if the env-file contains incorrect data,
+// file is damaged or missing.
+//
+// # Examples
+//
+//	// The same as Update, but for the keys of the application only
+//	// and ignoring the incorrect lines.
+//	err := env.LoadWith(".env",
+//		env.WithUpdate(true),
+//		env.WithForced(true),
+//		env.WithPrefixFilter("APP_"),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func LoadWith(filename string, opts ...Option) error {
+	o := loadOptions{expand: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return readParseStoreWith(filename, o)
+}
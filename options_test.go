@@ -0,0 +1,68 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadWith tests LoadWith function.
+func TestLoadWith(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	data := "APP_HOST=localhost\nAPP_PORT=8080\nAPP_ADDR=${APP_HOST}\n" +
+		"incorrect line\nDB_HOST=db\n"
+	if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The incorrect line.
+	os.Clearenv()
+	if err := LoadWith(filename); err == nil {
+		t.Error("an error is expected for the incorrect line")
+	}
+
+	// Without expansion and for the keys with prefix only.
+	os.Setenv("APP_PORT", "80")
+	err := LoadWith(filename,
+		WithForced(true),
+		WithExpand(false),
+		WithPrefixFilter("APP_"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("APP_ADDR"); v != "${APP_HOST}" {
+		t.Errorf("expected `${APP_HOST}` but `%s`", v)
+	}
+
+	if v := Get("APP_PORT"); v != "80" {
+		t.Errorf("expected `80` but `%s`", v)
+	}
+
+	if v, ok := Lookup("DB_HOST"); ok {
+		t.Errorf("expected unset DB_HOST but `%s`", v)
+	}
+
+	if v := LastLoadStats().Keys; v != 3 {
+		t.Errorf("expected 3 keys but %d", v)
+	}
+
+	// Update the existing keys with expansion.
+	err = LoadWith(filename, WithForced(true), WithUpdate(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("APP_ADDR"); v != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", v)
+	}
+
+	if v := Get("APP_PORT"); v != "8080" {
+		t.Errorf("expected `8080` but `%s`", v)
+	}
+
+	if v := Get("DB_HOST"); v != "db" {
+		t.Errorf("expected `db` but `%s`", v)
+	}
+}
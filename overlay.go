@@ -0,0 +1,240 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// The storeMode is true if the changes of the environment are kept
+// in the overlay until Sync is called (see StoreMode).
+var storeMode atomic.Bool
+
+// The overlayStore is the synchronized map of the changes of the
+// environment layered over the process environment.
+type overlayStore struct {
+	mu sync.RWMutex
+
+	values  map[string]string // keys set since the last sync
+	unset   map[string]bool   // keys unset since the last sync
+	cleared bool              // true if the environment is cleared
+}
+
+// The overlay keeps the changes of the environment in the store mode.
+var overlay = &overlayStore{
+	values: make(map[string]string),
+	unset:  make(map[string]bool),
+}
+
+// StoreMode sets whether the package keeps the changes of the environment
+// in its own synchronized map layered over the process environment: Set,
+// Unset, Clear, Load, Update, Marshal and other functions change the map
+// only, and Get, Lookup, Environ, Unmarshal and other functions read the
+// map first and the process environment after it. It makes the concurrent
+// changes and unmarshaling race-free and much faster than the system
+// calls. The changes are pushed to the process environment (visible to
+// the os package and the child processes) by the Sync function. Returns
+// the previous mode.
+//
+// The pending changes are pushed when the mode is disabled, the error of
+// pushing them is returned (the mode is disabled anyway). The mode is
+// switched under the lock of the map, so the changes made concurrently
+// with the switch aren't lost. The store mode is disabled by default.
+//
+// # Examples
+//
+//	env.StoreMode(true)
+//
+//	if err := env.Update(".env"); err != nil {
+//		log.Fatal(err)
+//	}
+//	...
+//	if err := env.Sync(); err != nil { // before exec.Command
+//		log.Fatal(err)
+//	}
+func StoreMode(enabled bool) (bool, error) {
+	overlay.mu.Lock()
+	defer overlay.mu.Unlock()
+
+	prev := storeMode.Swap(enabled)
+	if prev && !enabled {
+		return prev, overlay.flush()
+	}
+
+	return prev, nil
+}
+
+// Sync pushes the changes of the environment kept in the store mode
+// (see StoreMode) to the process environment. Does nothing if the
+// store mode is disabled.
+//
+// # Examples
+//
+//	env.Set("HOST", "localhost")
+//	env.Sync()
+//	fmt.Println(os.Getenv("HOST")) // localhost
+func Sync() error {
+	overlay.mu.Lock()
+	defer overlay.mu.Unlock()
+
+	if !storeMode.Load() {
+		return nil
+	}
+
+	return overlay.flush()
+}
+
+// The flush pushes the changes to the process environment
+// and clears the overlay, the lock must be held.
+func (o *overlayStore) flush() error {
+	if o.cleared {
+		os.Clearenv()
+	}
+
+	var errs []error
+	for key := range o.unset {
+		if err := os.Unsetenv(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for key, value := range o.values {
+		if err := os.Setenv(key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	o.values = make(map[string]string)
+	o.unset = make(map[string]bool)
+	o.cleared = false
+
+	return errors.Join(errs...)
+}
+
+// The setenv sets the value of the key in the overlay in the store mode
+// or in the process environment otherwise. The mode is checked under
+// the lock, so the value isn't lost if the mode is switched at the same
+// time. Returns an error for the key rejected by the os.Setenv.
+func (o *overlayStore) setenv(key, value string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !storeMode.Load() {
+		return os.Setenv(key, value)
+	}
+
+	if key == "" || strings.ContainsAny(key, "=\x00") ||
+		strings.ContainsRune(value, 0) {
+		return os.NewSyscallError("setenv", syscall.EINVAL)
+	}
+
+	o.values[key] = value
+	delete(o.unset, key)
+	return nil
+}
+
+// The unsetenv unsets the key in the overlay in the store mode
+// or in the process environment otherwise.
+func (o *overlayStore) unsetenv(key string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !storeMode.Load() {
+		return os.Unsetenv(key)
+	}
+
+	delete(o.values, key)
+	if !o.cleared {
+		o.unset[key] = true
+	}
+	return nil
+}
+
+// The clearenv deletes all keys in the store mode, the process
+// environment is hidden until the sync. Otherwise the process
+// environment is cleared.
+func (o *overlayStore) clearenv() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !storeMode.Load() {
+		os.Clearenv()
+		return
+	}
+
+	o.values = make(map[string]string)
+	o.unset = make(map[string]bool)
+	o.cleared = true
+}
+
+// The lookup returns the value of the key from the overlay
+// or from the process environment.
+func (o *overlayStore) lookup(key string) (string, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if !storeMode.Load() {
+		return os.LookupEnv(key)
+	} else if value, ok := o.values[key]; ok {
+		return value, true
+	} else if o.cleared || o.unset[key] {
+		return "", false
+	}
+
+	return os.LookupEnv(key)
+}
+
+// The environ returns the items of the process environment
+// with the changes of the overlay, in the form "key=value".
+func (o *overlayStore) environ() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if !storeMode.Load() {
+		return os.Environ()
+	}
+
+	var result []string
+	if !o.cleared {
+		for _, item := range os.Environ() {
+			key, _, _ := strings.Cut(item, "=")
+			if _, ok := o.values[key]; !ok && !o.unset[key] {
+				result = append(result, item)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(o.values))
+	for key := range o.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result = append(result, key+"="+o.values[key])
+	}
+
+	return result
+}
+
+// The getenv retrieves the value of the environment variable
+// taking into account the store mode, without tracing.
+func getenv(key string) (string, bool) {
+	return overlay.lookup(key)
+}
+
+// The environ returns a copy of the environment
+// taking into account the store mode.
+func environ() []string {
+	return overlay.environ()
+}
+
+// The getenvValue works like getenv, but returns the value only.
+func getenvValue(key string) string {
+	value, _ := getenv(key)
+	return value
+}
@@ -0,0 +1,170 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestStoreMode tests StoreMode and Sync functions.
+func TestStoreMode(t *testing.T) {
+	prev, _ := StoreMode(false)
+	defer StoreMode(prev)
+
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("USER", "goloop")
+
+	StoreMode(true)
+	Set("PORT", "8080")
+	Unset("USER")
+
+	// The changes are visible through the package only.
+	if v := Get("PORT"); v != "8080" {
+		t.Errorf("expected `8080` but `%s`", v)
+	}
+
+	if v, ok := Lookup("USER"); ok {
+		t.Errorf("expected unset USER but `%s`", v)
+	}
+
+	if v := Get("HOST"); v != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", v)
+	}
+
+	if v, ok := os.LookupEnv("PORT"); ok {
+		t.Errorf("expected unset PORT but `%s`", v)
+	}
+
+	if v := fmt.Sprint(Environ()); v != "[HOST=localhost PORT=8080]" {
+		t.Errorf("expected `[HOST=localhost PORT=8080]` but `%s`", v)
+	}
+
+	// Unmarshal reads the overlay.
+	var config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	if err := Unmarshal("", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Port != 8080 {
+		t.Errorf("expected `8080` but `%d`", config.Port)
+	}
+
+	// Push the changes.
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := os.Getenv("PORT"); v != "8080" {
+		t.Errorf("expected `8080` but `%s`", v)
+	}
+
+	if v, ok := os.LookupEnv("USER"); ok {
+		t.Errorf("expected unset USER but `%s`", v)
+	}
+
+	// The cleared environment is hidden until the sync.
+	Clear()
+	Set("KEY", "value")
+	if v, ok := Lookup("HOST"); ok {
+		t.Errorf("expected unset HOST but `%s`", v)
+	}
+
+	if v := fmt.Sprint(Environ()); v != "[KEY=value]" {
+		t.Errorf("expected `[KEY=value]` but `%s`", v)
+	}
+
+	// The incorrect key.
+	if err := Set("A=B", "value"); err == nil {
+		t.Error("an error is expected for the incorrect key")
+	}
+
+	// The changes are pushed when the mode is disabled.
+	if _, err := StoreMode(false); err != nil {
+		t.Fatal(err)
+	}
+	if v := fmt.Sprint(os.Environ()); v != "[KEY=value]" {
+		t.Errorf("expected `[KEY=value]` but `%s`", v)
+	}
+}
+
+// TestStoreModeConcurrent tests concurrent changes in the store mode.
+func TestStoreModeConcurrent(t *testing.T) {
+	prev, _ := StoreMode(true)
+	defer StoreMode(prev)
+
+	os.Clearenv()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("KEY_%d", i)
+			for j := 0; j < 100; j++ {
+				Set(key, fmt.Sprint(j))
+				Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := len(os.Environ()); v != 8 {
+		t.Errorf("expected 8 keys but %d", v)
+	}
+}
+
+// TestStoreModeSwitch tests that the changes made concurrently
+// with switching the mode aren't lost.
+func TestStoreModeSwitch(t *testing.T) {
+	prev, _ := StoreMode(false)
+	defer StoreMode(prev)
+
+	os.Clearenv()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Set(fmt.Sprintf("KEY_%d_%d", i, j), "value")
+			}
+		}(i)
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Switch the mode until all changes are made.
+	for enabled, running := true, true; running; enabled = !enabled {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		if _, err := StoreMode(enabled); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := StoreMode(false); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := len(os.Environ()); v != 800 {
+		t.Errorf("expected 800 keys but %d", v)
+	}
+}
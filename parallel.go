@@ -0,0 +1,81 @@
+package env
+
+import (
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// UnmarshalParallel works like Unmarshal but converts the top-level fields
+// of the structure concurrently: the fields are split into workers chunks
+// (the GOMAXPROCS value is used if workers is less than 1) converted by
+// their own goroutines. The nested structures are converted by the
+// goroutine of their top-level field.
+//
+// The values of the keys with the prefix are copied from the environment
+// into the snapshot before the conversion, so the goroutines don't access
+// the environment (the changes made during the conversion aren't seen).
+// If several fields have errors, the error of the first field is returned.
+//
+// It's the opt-in mode for very large structures: the goroutines have their
+// own cost and the snapshot copies the environment, so this mode is faster
+// only on several CPUs for structures with hundreds of fields or with
+// expensive conversions (see BenchmarkUnmarshalParallel), use Unmarshal
+// for ordinary configurations.
+//
+// # Examples
+//
+//	var config LargeConfig
+//	if err := env.UnmarshalParallel("APP_", &config, 0); err != nil {
+//		log.Fatal(err)
+//	}
+func UnmarshalParallel(prefix string, obj interface{}, workers int) error {
+	_, v, err := validateStruct(obj)
+	if err != nil {
+		return err
+	}
+
+	// The custom method reads the environment by itself.
+	unmarshaler, err := customUnmarshaler(obj)
+	if err != nil {
+		return err
+	} else if unmarshaler != nil {
+		return unmarshaler.UnmarshalEnv()
+	}
+
+	lookup, keys := snapshotLookup(prefix)
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// The fields are split into continuous chunks, one for each goroutine.
+	// Each goroutine writes only its own fields and its own cells of
+	// the errs, so no additional synchronization is required.
+	e := v.Elem()
+	errs := make([]error, e.NumField())
+	chunk := (e.NumField() + workers - 1) / workers
+
+	var eg errgroup.Group
+	for start := 0; start < e.NumField(); start += chunk {
+		start, end := start, start+chunk
+		if end > e.NumField() {
+			end = e.NumField()
+		}
+
+		eg.Go(func() error {
+			for i := start; i < end; i++ {
+				errs[i] = unmarshalField(lookup, keys, prefix, e, i)
+			}
+			return nil
+		})
+	}
+	eg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return refine(obj)
+}
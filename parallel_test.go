@@ -0,0 +1,84 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestUnmarshalParallel tests that the result is the same as of Unmarshal.
+func TestUnmarshalParallel(t *testing.T) {
+	type nested struct {
+		Name  string `env:"NAME"`
+		Items []int  `env:"ITEMS" sep:","`
+	}
+
+	type config struct {
+		Host   string   `env:"HOST" def:"localhost"`
+		Port   int      `env:"PORT"`
+		Rate   float64  `env:"RATE"`
+		Debug  bool     `env:"DEBUG"`
+		URL    url.URL  `env:"URL"`
+		Hosts  []string `env:"HOSTS" sep:","`
+		Nested nested   `env:"NESTED"`
+		Ptr    *nested  `env:"PTR"`
+	}
+
+	os.Clearenv()
+	Set("APP_PORT", "8080")
+	Set("APP_RATE", "0.5")
+	Set("APP_DEBUG", "true")
+	Set("APP_URL", "https://example.com/path")
+	Set("APP_HOSTS", "a,b,c")
+	Set("APP_NESTED_NAME", "nested")
+	Set("APP_NESTED_ITEMS", "1,2,3")
+	Set("APP_PTR_NAME", "ptr")
+
+	var expected, result config
+	if err := Unmarshal("APP_", &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 1, 3} {
+		result = config{}
+		if err := UnmarshalParallel("APP_", &result, workers); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("expected `%+v` but `%+v`", expected, result)
+		}
+	}
+}
+
+// TestUnmarshalParallelError tests that the error
+// of the first field is returned.
+func TestUnmarshalParallelError(t *testing.T) {
+	type config struct {
+		A int    `env:"A"`
+		B string `env:"B" required:"true"`
+		C int    `env:"C"`
+	}
+
+	os.Clearenv()
+	Set("A", "one")
+	Set("C", "three")
+
+	var data config
+	err := UnmarshalParallel("", &data, 4)
+	if err == nil || errors.Is(err, ErrRequired) {
+		t.Errorf("expected the error of the A field but `%v`", err)
+	}
+
+	Set("A", "1")
+	err = UnmarshalParallel("", &data, 4)
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("expected `%v` but `%v`", ErrRequired, err)
+	}
+
+	if err := UnmarshalParallel("", data, 4); err == nil {
+		t.Error("expected an error for not a pointer")
+	}
+}
@@ -0,0 +1,154 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// PathError is the error of the field with the path tag: the path
+// doesn't exist, has the wrong type or the directory can't be created.
+type PathError struct {
+	Key  string // key name
+	Path string // absolute path
+	Err  error  // os.ErrNotExist, ErrNotDir, ErrNotFile or other cause
+}
+
+// Error returns the error message with the key name and the path.
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Key, e.Path, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// The pathOptions are the options of the path tag.
+type pathOptions struct {
+	file   bool // the path must be a file (if it exists)
+	dir    bool // the path must be a directory (if it exists)
+	exists bool // the path must exist
+	create bool // the directory is created if it doesn't exist
+}
+
+// The parsePathTag parses the value of the path tag, the comma-separated
+// list of the options: file, dir or any, exists, create (for dir only).
+func parsePathTag(tag string) (pathOptions, error) {
+	var opts pathOptions
+	for _, item := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(item) {
+		case "", "any":
+			// The path of any type.
+		case "file":
+			opts.file = true
+		case "dir":
+			opts.dir = true
+		case "exists":
+			opts.exists = true
+		case "create":
+			opts.create = true
+		default:
+			return opts, fmt.Errorf("unknown option: %s", item)
+		}
+	}
+
+	if opts.file && opts.dir {
+		return opts, fmt.Errorf("file and dir options are exclusive")
+	} else if opts.create && !opts.dir {
+		return opts, fmt.Errorf("create option requires dir option")
+	}
+
+	return opts, nil
+}
+
+// The isStringType returns true if the type is string or a pointer,
+// array or slice of strings.
+func isStringType(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Array, reflect.Slice:
+			t = t.Elem()
+		case reflect.String:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// The resolvePaths resolves the path (or the items of the sequence value)
+// of the field of the type t by the options of the path tag. If idle is
+// true, the directories with the create option aren't created.
+func resolvePaths(t reflect.Type, tg *tagGroup, idle bool) (string, error) {
+	opts, err := parsePathTag(tg.path)
+	if err != nil {
+		return "", fmt.Errorf("%s: path tag: %w", tg.key, err)
+	} else if idle {
+		opts.create = false
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Array && t.Kind() != reflect.Slice {
+		return resolvePath(tg.key, tg.value, opts)
+	}
+
+	seq := splitList(tg.value, tg.sep)
+	for i, item := range seq {
+		path, err := resolvePath(tg.key, item, opts)
+		if err != nil {
+			return "", err
+		}
+		seq[i] = path
+	}
+
+	return joinList(seq, tg.sep), nil
+}
+
+// The resolvePath expands the leading ~ to the home directory, makes
+// the path absolute and checks it by the options. The empty path
+// isn't changed.
+func resolvePath(key, path string, opts pathOptions) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") ||
+		strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", &PathError{Key: key, Path: path, Err: err}
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", &PathError{Key: key, Path: path, Err: err}
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if opts.dir && !info.IsDir() {
+			return "", &PathError{Key: key, Path: path, Err: ErrNotDir}
+		} else if opts.file && info.IsDir() {
+			return "", &PathError{Key: key, Path: path, Err: ErrNotFile}
+		}
+	case os.IsNotExist(err) && opts.create:
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return "", &PathError{Key: key, Path: path, Err: err}
+		}
+	case os.IsNotExist(err) && !opts.exists:
+		// The path can be created later by the program.
+	default:
+		return "", &PathError{Key: key, Path: path, Err: err}
+	}
+
+	return path, nil
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestUnmarshalPath tests the fields with the path tag.
+func TestUnmarshalPath(t *testing.T) {
+	type config struct {
+		Config string   `env:"CONFIG" path:"file,exists"`
+		Cache  string   `env:"CACHE" path:"dir,create"`
+		Home   *string  `env:"HOME_DIR" path:"dir"`
+		Plugin []string `env:"PLUGINS" path:"any" sep:":"`
+		Output string   `env:"OUTPUT" path:"file"`
+	}
+
+	root := t.TempDir()
+	file := filepath.Join(root, "app.conf")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	Set("HOME", root)
+	Set("CONFIG", file)
+	Set("CACHE", filepath.Join(root, "cache", "app"))
+	Set("HOME_DIR", "~")
+	Set("PLUGINS", "~/a:b")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	if data.Config != file || data.Home == nil || *data.Home != root ||
+		len(data.Plugin) != 2 ||
+		data.Plugin[0] != filepath.Join(root, "a") ||
+		data.Plugin[1] != filepath.Join(wd, "b") || data.Output != "" {
+		t.Errorf("incorrect paths: %+v", data)
+	}
+
+	if info, err := os.Stat(data.Cache); err != nil || !info.IsDir() {
+		t.Errorf("expected the created directory but %v", err)
+	}
+
+	tests := []struct {
+		key, value string
+		err        error
+	}{
+		{"CONFIG", filepath.Join(root, "none"), os.ErrNotExist},
+		{"CONFIG", root, ErrNotFile},
+		{"CACHE", file, ErrNotDir},
+	}
+
+	for _, test := range tests {
+		Set(test.key, test.value)
+		err := Unmarshal("", &config{})
+
+		var pathErr *PathError
+		if !errors.As(err, &pathErr) || !errors.Is(err, test.err) ||
+			pathErr.Key != test.key {
+			t.Errorf("%s: expected `%v` but `%v`", test.key, test.err, err)
+		}
+		Set("CONFIG", file)
+	}
+}
+
+// TestParsePathTag tests the options of the path tag.
+func TestParsePathTag(t *testing.T) {
+	for tag, ok := range map[string]bool{
+		"file,exists": true,
+		"dir,create":  true,
+		"any":         true,
+		"file,dir":    false,
+		"file,create": false,
+		"exist":       false,
+	} {
+		if _, err := parsePathTag(tag); (err == nil) != ok {
+			t.Errorf("%s: expected %t but `%v`", tag, ok, err)
+		}
+	}
+}
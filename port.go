@@ -0,0 +1,100 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// The portType is the reflect.Type of the Port.
+	portType = reflect.TypeOf(Port(0))
+
+	// The hostPortType is the reflect.Type of the HostPort.
+	hostPortType = reflect.TypeOf(HostPort{})
+)
+
+// Port is the network port number. The fields of this type are
+// unmarshaled from the decimal numbers from 0 to 65535, the other
+// values (like 70000 or http) are errors.
+//
+// # Examples
+//
+//	type Config struct {
+//		Port env.Port `env:"PORT" def:"8080"`
+//	}
+type Port uint16
+
+// ParsePort parses the port number from the string.
+func ParsePort(s string) (Port, error) {
+	p, err := strconv.ParseUint(strings.TrimSpace(s), 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+
+	return Port(p), nil
+}
+
+// String returns the port as the decimal number.
+func (p Port) String() string {
+	return strconv.FormatUint(uint64(p), 10)
+}
+
+// HostPort is the network address as the host and the port, like the
+// address to listen on. The fields of this type are unmarshaled from
+// the values like 0.0.0.0:8080, localhost:80, [::1]:443 or :8080 (the
+// host is empty), the port is required.
+//
+// # Examples
+//
+//	type Config struct {
+//		Listen env.HostPort `env:"LISTEN_ADDR" def:"0.0.0.0:8080"`
+//	}
+//
+//	var config Config
+//	env.Unmarshal("", &config)
+//	fmt.Println(config.Listen.Host, config.Listen.Port) // 0.0.0.0 8080
+//	http.ListenAndServe(config.Listen.String(), nil)
+type HostPort struct {
+	Host string
+	Port Port
+}
+
+// ParseHostPort parses the address in the form host:port.
+func ParseHostPort(s string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+	if err != nil {
+		return HostPort{}, err
+	}
+
+	p, err := ParsePort(port)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("address %s: %w", s, err)
+	}
+
+	return HostPort{Host: host, Port: p}, nil
+}
+
+// String returns the address in the form host:port
+// (the IPv6 host is enclosed in square brackets).
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, hp.Port.String())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (hp HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (hp *HostPort) UnmarshalText(text []byte) error {
+	v, err := ParseHostPort(string(text))
+	if err != nil {
+		return err
+	}
+
+	*hp = v
+	return nil
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+// TestParseHostPort tests ParseHostPort function.
+func TestParseHostPort(t *testing.T) {
+	tests := []struct {
+		value string
+		want  HostPort
+		ok    bool
+	}{
+		{"0.0.0.0:8080", HostPort{"0.0.0.0", 8080}, true},
+		{"localhost:80", HostPort{"localhost", 80}, true},
+		{"[::1]:443", HostPort{"::1", 443}, true},
+		{":8080", HostPort{"", 8080}, true},
+		{"localhost", HostPort{}, false},
+		{"localhost:70000", HostPort{}, false},
+		{"localhost:http", HostPort{}, false},
+		{"::1:80", HostPort{}, false},
+	}
+
+	for _, test := range tests {
+		r, err := ParseHostPort(test.value)
+		if ok := err == nil; ok != test.ok || r != test.want {
+			t.Errorf("%q: expected `%v, %t` but `%v, %v`",
+				test.value, test.want, test.ok, r, err)
+		}
+	}
+
+	if s := (HostPort{"::1", 443}).String(); s != "[::1]:443" {
+		t.Errorf("expected `[::1]:443` but `%s`", s)
+	}
+}
+
+// TestUnmarshalHostPort tests the Port and HostPort fields.
+func TestUnmarshalHostPort(t *testing.T) {
+	type config struct {
+		Listen HostPort  `env:"LISTEN_ADDR" def:"0.0.0.0:8080"`
+		Port   Port      `env:"PORT"`
+		Ports  []Port    `env:"PORTS" sep:","`
+		Peer   *HostPort `env:"PEER"`
+		Empty  HostPort  `env:"EMPTY"`
+	}
+
+	os.Clearenv()
+	Set("PORT", "443")
+	Set("PORTS", "80,8080")
+	Set("PEER", "[::1]:9000")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Listen != (HostPort{"0.0.0.0", 8080}) || data.Port != 443 ||
+		len(data.Ports) != 2 || data.Ports[1] != 8080 ||
+		data.Peer == nil || *data.Peer != (HostPort{"::1", 9000}) {
+		t.Errorf("incorrect addresses: %+v", data)
+	}
+
+	items, err := marshalEnv("", data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"LISTEN_ADDR=0.0.0.0:8080", "PORT=443",
+		"PORTS=80,8080", "PEER=[::1]:9000", "EMPTY=:0"}
+	for i, item := range expected {
+		if items[i] != item {
+			t.Errorf("expected `%s` but `%s`", item, items[i])
+		}
+	}
+
+	for key, value := range map[string]string{
+		"PORT": "65536", "PORTS": "80,-1", "LISTEN_ADDR": "8080",
+	} {
+		os.Clearenv()
+		Set(key, value)
+		if err := Unmarshal("", &config{}); err == nil {
+			t.Errorf("%s: expected an error for `%s`", key, value)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteStyle is the way the value is quoted in the env-file (see Quote).
+type QuoteStyle uint32
+
+const (
+	// QuoteAuto leaves the value as is if it can be parsed without
+	// quotes, otherwise encloses it in the first of the double quotes,
+	// single quotes or backquotes that keeps the value.
+	QuoteAuto QuoteStyle = iota
+
+	// QuoteNone leaves the value as is.
+	QuoteNone
+
+	// QuoteDouble encloses the value in double quotes.
+	QuoteDouble
+
+	// QuoteSingle encloses the value in single quotes.
+	QuoteSingle
+
+	// QuoteBack encloses the value in backquotes.
+	QuoteBack
+)
+
+// String returns the name of the style.
+func (s QuoteStyle) String() string {
+	switch s {
+	case QuoteAuto:
+		return "auto"
+	case QuoteNone:
+		return "none"
+	case QuoteDouble:
+		return "double"
+	case QuoteSingle:
+		return "single"
+	case QuoteBack:
+		return "back"
+	}
+
+	return fmt.Sprintf("QuoteStyle(%d)", uint32(s))
+}
+
+// Quote returns the value as it should be written to the env-file to be
+// parsed back by the Load, Update, Parse and other functions of the
+// package, the same way as Save writes the values. The quote character
+// inside the quoted value is escaped by the backslash, other characters
+// are kept as is (the parser doesn't support other escape sequences).
+//
+// The value that ends with the backslash or has the backslash before the
+// quote character can't be enclosed in this quote character, the value
+// with the line break can't be written to the env-file at all. Use the
+// QuoteAuto style to choose the quotes that keep the value.
+//
+// # Examples
+//
+//	env.Quote("localhost", env.QuoteAuto)     // localhost
+//	env.Quote("hello world", env.QuoteAuto)   // "hello world"
+//	env.Quote(`say "hi"`, env.QuoteAuto)      // "say \"hi\""
+//	env.Quote("", env.QuoteAuto)              // ""
+//	env.Quote("localhost", env.QuoteSingle)   // 'localhost'
+//	env.Quote("it's", env.QuoteSingle)        // 'it\'s'
+func Quote(value string, style QuoteStyle) string {
+	switch style {
+	case QuoteNone:
+		return value
+	case QuoteDouble:
+		return quoteWith(value, '"')
+	case QuoteSingle:
+		return quoteWith(value, '\'')
+	case QuoteBack:
+		return quoteWith(value, '`')
+	}
+
+	if value != "" && !strings.ContainsAny(value, " \t#'\"`") {
+		return value
+	}
+
+	for _, quote := range []rune{'"', '\'', '`'} {
+		result := quoteWith(value, quote)
+		if v, err := Unquote(result); err == nil && v == value {
+			return result
+		}
+	}
+
+	return quoteWith(value, '"')
+}
+
+// Unquote returns the value written in the env-file, as the parser of the
+// package sees it: the quotes around the value are removed, the escaped
+// quote characters are unescaped and the inline comment is ignored. The
+// raw is the part of the expression after the equal sign.
+//
+// Returns an error if the raw is empty or the value is incorrect,
+// like the value without the closing quote.
+//
+// # Examples
+//
+//	env.Unquote(`localhost # host`) // localhost
+//	env.Unquote(`"hello world"`)    // hello world
+//	env.Unquote(`'it\'s' # note`)   // it's
+//	env.Unquote(`"unclosed`)        // error
+func Unquote(raw string) (string, error) {
+	_, value, err := parseExpression("KEY=" + strings.TrimSpace(raw))
+	return value, err
+}
+
+// The quoteWith encloses the value in the quote characters
+// and escapes the quote characters inside the value.
+func quoteWith(value string, quote rune) string {
+	q := string(quote)
+	return q + strings.ReplaceAll(value, q, `\`+q) + q
+}
+
+// The quoteItem returns the key=value item of the Marshal
+// with the value quoted to be written to the env-file.
+func quoteItem(item string) string {
+	key, value, _ := strings.Cut(item, "=")
+	return key + "=" + Quote(value, QuoteAuto)
+}
@@ -0,0 +1,116 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestQuote tests Quote function.
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value    string
+		style    QuoteStyle
+		expected string
+	}{
+		{"localhost", QuoteAuto, "localhost"},
+		{"hello world", QuoteAuto, `"hello world"`},
+		{`say "hi"`, QuoteAuto, `"say \"hi\""`},
+		{"", QuoteAuto, `""`},
+		{"a#b", QuoteAuto, `"a#b"`},
+		{`a\"b`, QuoteAuto, `'a\"b'`},
+		{`a\"b\'c`, QuoteAuto, "`a\\\"b\\'c`"},
+		{"localhost", QuoteNone, "localhost"},
+		{"localhost", QuoteDouble, `"localhost"`},
+		{"it's", QuoteSingle, `'it\'s'`},
+		{"a`b", QuoteBack, "`a\\`b`"},
+	}
+
+	for _, test := range tests {
+		if v := Quote(test.value, test.style); v != test.expected {
+			t.Errorf("expected `%s` but `%s`", test.expected, v)
+		}
+	}
+}
+
+// TestUnquote tests Unquote function.
+func TestUnquote(t *testing.T) {
+	tests := map[string]string{
+		"localhost # host":  "localhost",
+		`"hello world"`:     "hello world",
+		`'it\'s' # note`:    "it's",
+		`"say \"hi\""`:      `say "hi"`,
+		`""`:                "",
+		" `a # b` ":         "a # b",
+		`"a\nb"`:            `a\nb`,
+		"postgres://a?b=1":  "postgres://a?b=1",
+		`'a\"b'`:            `a\"b`,
+		"`a\\\"b'c` # note": `a\"b'c`,
+	}
+
+	for raw, expected := range tests {
+		v, err := Unquote(raw)
+		if err != nil {
+			t.Errorf("unexpected error for `%s`: %v", raw, err)
+		} else if v != expected {
+			t.Errorf("expected `%s` but `%s`", expected, v)
+		}
+	}
+
+	for _, raw := range []string{"", `"unclosed`, `"a" b`} {
+		if _, err := Unquote(raw); err == nil {
+			t.Errorf("expected error for `%s`", raw)
+		}
+	}
+}
+
+// TestQuoteRoundTrip tests that Unquote returns the value quoted by Quote.
+func TestQuoteRoundTrip(t *testing.T) {
+	values := []string{"", "a", "a b", " a ", "#", `"`, "'", "`",
+		`\`, `a\`, `C:\dir\`, `\"`, `"'` + "`", "$HOME", "a=b"}
+	for _, value := range values {
+		v, err := Unquote(Quote(value, QuoteAuto))
+		if err != nil {
+			t.Errorf("unexpected error for `%s`: %v", value, err)
+		} else if v != value {
+			t.Errorf("expected `%s` but `%s`", value, v)
+		}
+	}
+}
+
+// TestSaveQuoted tests that Save quotes the values.
+func TestSaveQuoted(t *testing.T) {
+	data := struct {
+		Name  string   `env:"NAME"`
+		Empty string   `env:"EMPTY"`
+		Hosts []string `env:"HOSTS"`
+	}{Name: `say "hi"`, Hosts: []string{"a", "b"}}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := Save(filename, "", data); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "NAME=\"say \\\"hi\\\"\"\nEMPTY=\"\"\nHOSTS=\"a b\"\n"
+	if v := string(content); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	os.Clearenv()
+	if err := Load(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("NAME"); v != data.Name {
+		t.Errorf("expected `%s` but `%s`", data.Name, v)
+	}
+
+	if v := Get("HOSTS"); v != "a b" {
+		t.Errorf("expected `a b` but `%s`", v)
+	}
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The rangeType is the reflect.Type of the Range.
+var rangeType = reflect.TypeOf(Range{})
+
+// Range is the inclusive range of integers, like a range of ports.
+// The fields of this type are unmarshaled from the values like 10-20
+// (or 10 for the range of one number), the negative bounds are allowed
+// (like -10--5). The Min can't be greater than Max.
+//
+// # Examples
+//
+//	type Config struct {
+//		Ports env.Range `env:"PORTS" def:"8000-8010"`
+//	}
+//
+//	var config Config
+//	env.Unmarshal("", &config)
+//	fmt.Println(config.Ports.Min, config.Ports.Max) // 8000 8010
+//	fmt.Println(config.Ports.Contains(8080))       // false
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// ParseRange parses the range from the string like 10-20 or 10.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+
+	// The separator is the first dash after the
+	// first character (that can be the minus sign).
+	pos := -1
+	if len(s) > 1 {
+		if i := strings.IndexByte(s[1:], '-'); i >= 0 {
+			pos = i + 1
+		}
+	}
+
+	low, high := s, s
+	if pos >= 0 {
+		low, high = s[:pos], s[pos+1:]
+	}
+
+	min, err := strconv.ParseInt(strings.TrimSpace(low), 10, 64)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+
+	max, err := strconv.ParseInt(strings.TrimSpace(high), 10, 64)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+
+	if min > max {
+		return Range{}, fmt.Errorf("invalid range %q: %d is greater "+
+			"than %d", s, min, max)
+	}
+
+	return Range{Min: min, Max: max}, nil
+}
+
+// Contains returns true if the value is in the range.
+func (r Range) Contains(value int64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+// Len returns the number of integers in the range.
+func (r Range) Len() int64 {
+	return r.Max - r.Min + 1
+}
+
+// String returns the range in the format of ParseRange.
+func (r Range) String() string {
+	return strconv.FormatInt(r.Min, 10) + "-" + strconv.FormatInt(r.Max, 10)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Range) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Range) UnmarshalText(text []byte) error {
+	v, err := ParseRange(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = v
+	return nil
+}
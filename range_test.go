@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestParseRange tests ParseRange function.
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Range
+		ok    bool
+	}{
+		{"10-20", Range{10, 20}, true},
+		{" 10 - 20 ", Range{10, 20}, true},
+		{"5", Range{5, 5}, true},
+		{"-10--5", Range{-10, -5}, true},
+		{"-5-5", Range{-5, 5}, true},
+		{"20-10", Range{}, false},
+		{"10-", Range{}, false},
+		{"a-b", Range{}, false},
+		{"", Range{}, false},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRange(test.value)
+		if ok := err == nil; ok != test.ok || r != test.want {
+			t.Errorf("%q: expected `%v, %t` but `%v, %v`",
+				test.value, test.want, test.ok, r, err)
+		}
+	}
+
+	r := Range{8000, 8010}
+	if !r.Contains(8000) || !r.Contains(8010) || r.Contains(8011) {
+		t.Errorf("incorrect Contains for %s", r)
+	}
+
+	if r.Len() != 11 {
+		t.Errorf("expected `11` but `%d`", r.Len())
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil || string(data) != `"8000-8010"` {
+		t.Errorf("expected `\"8000-8010\"` but `%s, %v`", data, err)
+	}
+}
+
+// TestUnmarshalRange tests the Range fields.
+func TestUnmarshalRange(t *testing.T) {
+	type config struct {
+		Ports  Range   `env:"PORTS" def:"8000-8010"`
+		Ptr    *Range  `env:"PTR"`
+		Ranges []Range `env:"RANGES" sep:","`
+	}
+
+	os.Clearenv()
+	Set("PTR", "1-2")
+	Set("RANGES", "1-5,10")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Ports != (Range{8000, 8010}) || data.Ptr == nil ||
+		*data.Ptr != (Range{1, 2}) || len(data.Ranges) != 2 ||
+		data.Ranges[1] != (Range{10, 10}) {
+		t.Errorf("incorrect ranges: %+v", data)
+	}
+
+	items, err := marshalEnv("", data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"PORTS=8000-8010", "PTR=1-2", "RANGES=1-5,10-10"}
+	for i, item := range expected {
+		if items[i] != item {
+			t.Errorf("expected `%s` but `%s`", item, items[i])
+		}
+	}
+
+	Set("PORTS", "10-1")
+	if err := Unmarshal("", &data); err == nil {
+		t.Error("expected an error for invalid range")
+	}
+}
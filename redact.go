@@ -0,0 +1,217 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The redacted is a wrapper of the configuration object that formats it
+// with the values of the fields marked by the secret tag masked.
+type redacted struct {
+	obj interface{}
+}
+
+// Redacted returns a wrapper of the configuration object (a structure or
+// a pointer to a structure) that formats it for logging with the values
+// of the secret fields masked. The fields are marked as secret by the
+// `secret:"true"` tag, the passwords in the url.URL fields are also masked.
+//
+// The wrapper implements fmt.Stringer, fmt.GoStringer and fmt.Formatter,
+// so the secrets are masked for all verbs, including %v, %+v and %#v.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host     string `env:"HOST"`
+//		Password string `env:"PASSWORD" secret:"true"`
+//	}
+//
+//	cfg := Config{Host: "localhost", Password: "qwerty"}
+//	log.Printf("config: %v", env.Redacted(cfg))
+//	// Output:
+//	//  config: {Host:localhost Password:******}
+func Redacted(obj interface{}) fmt.Stringer {
+	return redacted{obj: obj}
+}
+
+// String returns the object as string like the %+v verb
+// with the values of the secret fields masked.
+func (r redacted) String() string {
+	var sb strings.Builder
+	formatRedacted(&sb, reflect.ValueOf(r.obj), false)
+	return sb.String()
+}
+
+// GoString returns the object as string like the %#v verb
+// with the values of the secret fields masked.
+func (r redacted) GoString() string {
+	var sb strings.Builder
+	formatRedacted(&sb, reflect.ValueOf(r.obj), true)
+	return sb.String()
+}
+
+// Format implements fmt.Formatter, so that all
+// verbs display the object with masked secrets.
+func (r redacted) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, r.GoString())
+		return
+	}
+
+	fmt.Fprint(f, r.String())
+}
+
+// The formatRedacted writes the value into sb. If gosyntax is true,
+// the value is written in Go-syntax like the %#v verb.
+func formatRedacted(sb *strings.Builder, v reflect.Value, gosyntax bool) {
+	if !v.IsValid() {
+		sb.WriteString("<nil>")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			if gosyntax {
+				fmt.Fprintf(sb, "(%s)(nil)", v.Type())
+			} else {
+				sb.WriteString("<nil>")
+			}
+			return
+		}
+
+		if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+			sb.WriteString("&")
+		}
+		formatRedacted(sb, v.Elem(), gosyntax)
+	case reflect.Struct:
+		if v.Type() == secretStringType {
+			if gosyntax {
+				fmt.Fprintf(sb, "%q", maskValue)
+			} else {
+				sb.WriteString(maskValue)
+			}
+			return
+		}
+
+		if v.Type() == reflect.TypeOf(url.URL{}) {
+			u := v.Interface().(url.URL)
+			if gosyntax {
+				fmt.Fprintf(sb, "%q", u.Redacted())
+			} else {
+				sb.WriteString(u.Redacted())
+			}
+			return
+		}
+
+		if gosyntax {
+			sb.WriteString(v.Type().String())
+		}
+
+		sb.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if i > 0 {
+				if gosyntax {
+					sb.WriteString(", ")
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+
+			sb.WriteString(field.Name)
+			sb.WriteString(":")
+			if newTagGroup(field).secret {
+				if gosyntax {
+					fmt.Fprintf(sb, "%q", maskValue)
+				} else {
+					sb.WriteString(maskValue)
+				}
+				continue
+			}
+
+			formatRedacted(sb, v.Field(i), gosyntax)
+		}
+		sb.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if gosyntax {
+			sb.WriteString(v.Type().String())
+			sb.WriteString("{")
+		} else {
+			sb.WriteString("[")
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				if gosyntax {
+					sb.WriteString(", ")
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+			formatRedacted(sb, v.Index(i), gosyntax)
+		}
+
+		if gosyntax {
+			sb.WriteString("}")
+		} else {
+			sb.WriteString("]")
+		}
+	case reflect.Map:
+		if gosyntax {
+			sb.WriteString(v.Type().String())
+			if v.IsNil() {
+				sb.WriteString("(nil)")
+				return
+			}
+			sb.WriteString("{")
+		} else {
+			sb.WriteString("map[")
+		}
+
+		// The entries are sorted by keys, like fmt does.
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		for i, k := range keys {
+			if i > 0 {
+				if gosyntax {
+					sb.WriteString(", ")
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+			formatRedacted(sb, k, gosyntax)
+			sb.WriteString(":")
+			formatRedacted(sb, v.MapIndex(k), gosyntax)
+		}
+
+		if gosyntax {
+			sb.WriteString("}")
+		} else {
+			sb.WriteString("]")
+		}
+	default:
+		if !v.CanInterface() {
+			// Unexported fields can't be formatted through
+			// interface, but fmt can print reflect.Value.
+			if gosyntax {
+				fmt.Fprintf(sb, "%#v", v)
+			} else {
+				fmt.Fprintf(sb, "%v", v)
+			}
+			return
+		}
+
+		if gosyntax {
+			fmt.Fprintf(sb, "%#v", v.Interface())
+		} else {
+			fmt.Fprintf(sb, "%v", v.Interface())
+		}
+	}
+}
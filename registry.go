@@ -0,0 +1,87 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// The registration is the configuration object registered
+// to be loaded by the Bind function.
+type registration struct {
+	prefix string      // prefix of the keys
+	obj    interface{} // pointer to the structure
+}
+
+var (
+	// The registry is the list of the registered configuration objects.
+	registry []registration
+
+	// The registryMu protects the registry.
+	registryMu sync.Mutex
+)
+
+// Register registers the configuration object with the prefix to be
+// unmarshaled by the Bind function. The obj must be a non-nil pointer
+// to a structure. Usually it is called in the init function of the
+// package that owns the configuration.
+//
+// # Examples
+//
+//	package db
+//
+//	var config Config
+//
+//	func init() {
+//		env.Register("DB_", &config)
+//	}
+//
+// ...
+//
+//	package main
+//
+//	func main() {
+//		if err := env.Bind(".env"); err != nil {
+//			log.Fatal(err)
+//		}
+//		...
+//	}
+func Register(prefix string, obj interface{}) error {
+	if _, _, err := validateStruct(obj); err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registration{prefix: prefix, obj: obj})
+
+	return nil
+}
+
+// Bind loads the env-files (new keys only, see Load) in the given order
+// and unmarshals all registered configuration objects. The errors of
+// all objects are collected and returned together, so the application
+// can report all configuration problems at once.
+func Bind(filenames ...string) error {
+	for _, filename := range filenames {
+		if err := Load(filename); err != nil {
+			return err
+		}
+	}
+
+	// The objects are unmarshaled without the lock, so the custom
+	// UnmarshalEnv methods can register other objects (they are
+	// unmarshaled by the next call).
+	registryMu.Lock()
+	list := append([]registration(nil), registry...)
+	registryMu.Unlock()
+
+	var errs []error
+	for _, r := range list {
+		if err := unmarshalEnv(r.prefix, r.obj); err != nil {
+			errs = append(errs, fmt.Errorf("%T (%s): %w", r.obj, r.prefix, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
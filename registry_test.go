@@ -0,0 +1,133 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRegisterBind tests Register and Bind functions.
+func TestRegisterBind(t *testing.T) {
+	type dbConfig struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	type appConfig struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT"`
+	}
+
+	defer func(r []registration) { registry = r }(registry)
+	registry = nil
+
+	var (
+		db  dbConfig
+		app appConfig
+	)
+
+	if err := Register("DB_", &db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Register("APP_", &app); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Register("", app); err == nil {
+		t.Error("an error is expected for no-pointer object")
+	}
+
+	filename := t.TempDir() + "/.env"
+	data := "DB_HOST=localhost\nDB_PORT=5432\nAPP_NAME=test\n"
+	if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	if err := Bind(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Host != "localhost" || db.Port != 5432 || app.Name != "test" {
+		t.Errorf("incorrect values: %v, %v", db, app)
+	}
+
+	// All errors are reported.
+	os.Setenv("DB_PORT", "abc")
+	os.Setenv("APP_PORT", "abc")
+	err := Bind()
+	if err == nil {
+		t.Fatal("an error is expected for incorrect values")
+	}
+
+	if !strings.Contains(err.Error(), "DB_") ||
+		!strings.Contains(err.Error(), "APP_") {
+		t.Errorf("all errors should be reported: %v", err)
+	}
+
+	// Missing file.
+	if err := Bind("./fixtures/nonexist.env"); err == nil {
+		t.Error("an error is expected for missing file")
+	}
+}
+
+// pluginConfig registers the configuration of the plugin
+// while it's unmarshaled.
+type pluginConfig struct {
+	Name   string
+	plugin *struct {
+		Level string `env:"LEVEL"`
+	}
+}
+
+// UnmarshalEnv implements Unmarshaler.
+func (c *pluginConfig) UnmarshalEnv() error {
+	c.Name = Get("PLUGIN_NAME")
+	return Register("PLUGIN_", c.plugin)
+}
+
+// TestBindRegister tests that Register can be called
+// by the objects unmarshaled by Bind.
+func TestBindRegister(t *testing.T) {
+	defer func(r []registration) { registry = r }(registry)
+	registry = nil
+
+	c := &pluginConfig{plugin: &struct {
+		Level string `env:"LEVEL"`
+	}{}}
+	if err := Register("", c); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	os.Setenv("PLUGIN_NAME", "log")
+	os.Setenv("PLUGIN_LEVEL", "debug")
+
+	done := make(chan error, 1)
+	go func() { done <- Bind() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Bind is deadlocked")
+	}
+
+	if c.Name != "log" || len(registry) != 2 {
+		t.Errorf("expected `log` and 2 objects but `%s` and %d",
+			c.Name, len(registry))
+	}
+
+	// The registered object is unmarshaled by the next call.
+	if err := Bind(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.plugin.Level != "debug" {
+		t.Errorf("expected `debug` but `%s`", c.plugin.Level)
+	}
+}
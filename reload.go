@@ -0,0 +1,370 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldChange describes the change of one field of the configuration
+// after reloading. The values are raw values from the environment.
+type FieldChange struct {
+	Key      string // key name in the environment
+	Field    string // Go-style path to the field, like DB.Host
+	Old      string // old value
+	New      string // new value
+	OldExist bool   // true if the key was set in the environment
+	NewExist bool   // true if the key is set in the environment
+}
+
+// The rawValue is the raw value of the key in the environment.
+type rawValue struct {
+	value string
+	ok    bool
+}
+
+// Reloader keeps the configuration structure in sync with the
+// environment. It knows which keys are mapped to which fields, so on
+// reloading only the fields whose keys have changed are decoded again,
+// instead of rebuilding the whole structure.
+//
+// The fields are changed under the lock, use RLock and RUnlock
+// to read the configuration concurrently with reloading.
+type Reloader struct {
+	sync.RWMutex
+
+	prefix   string
+	obj      interface{}
+	fields   []fieldInfo
+	values   map[string]rawValue
+	onChange func([]FieldChange)
+	onReload func(ReloadReport)
+	policy   ReloadPolicy
+	limit    reloadLimit
+	run      func() // performs the reload for Trigger, Reload if nil
+}
+
+// NewReloader unmarshals the environment into obj (a pointer to the
+// structure) and returns a reloader for it. The onChange function (if
+// not nil) is called after each reload that changed at least one field,
+// with the list of the changes.
+//
+// If the object implements the Unmarshaler interface, it's unmarshaled
+// entirely by its UnmarshalEnv method when any of its keys changes, the
+// object that implements the PartialUnmarshaler is unmarshaled entirely
+// too, so the UnmarshalEnvPartial method refines the new values. The
+// new zero object is unmarshaled and replaces the configuration only if
+// there are no errors, so the failed reload doesn't change it.
+//
+// # Examples
+//
+//	var cfg Config
+//	r, err := env.NewReloader("APP_", &cfg, func(c []env.FieldChange) {
+//		for _, change := range c {
+//			log.Printf("%s changed", change.Key)
+//		}
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	...
+//	if _, err := r.Reload(); err != nil {
+//		log.Println(err)
+//	}
+func NewReloader(prefix string, obj interface{},
+	onChange func([]FieldChange)) (*Reloader, error) {
+	t, _, err := validateStruct(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		prefix:   prefix,
+		obj:      obj,
+		values:   make(map[string]rawValue),
+		onChange: onChange,
+	}
+
+	err = walkFields(prefix, t, func(fi fieldInfo) error {
+		r.fields = append(r.fields, fi)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalEnv(prefix, obj); err != nil {
+		return nil, err
+	}
+
+	for _, fi := range r.fields {
+		r.values[fi.tg.key] = lookupRaw(fi)
+	}
+
+	return r, nil
+}
+
+// The lookupRaw returns the raw value of the field in the environment.
+// The value of the map of structures is the list of the items of its
+// entries, like DB_MAIN_HOST=localhost, one per line, so the changes
+// of the entries are found too.
+func lookupRaw(fi fieldInfo) rawValue {
+	if isStructMap(fi.field.Type) {
+		items, _ := mapItems(fi.tg.key+"_", fi.field.Type)
+		return rawValue{value: strings.Join(items, "\n"), ok: len(items) != 0}
+	}
+
+	value, ok, _ := fi.tg.lookup(lookupEnv)
+	return rawValue{value: value, ok: ok}
+}
+
+// ReloadPolicy defines what the Reloader does when some changed values
+// can't be decoded (or the required keys are removed).
+type ReloadPolicy int
+
+const (
+	// ReloadKeepLastGood keeps the last known good configuration: none
+	// of the changes is applied if any of them fails (the default).
+	ReloadKeepLastGood ReloadPolicy = iota
+
+	// ReloadPartial applies the changes that are decoded successfully,
+	// the fields of the failed changes keep their previous values (the
+	// object with the custom unmarshaler is never changed partially).
+	ReloadPartial
+
+	// ReloadCrash panics with the error after the report is delivered
+	// (see OnReload), for the services that must not run with the
+	// configuration that differs from the environment.
+	ReloadCrash
+)
+
+// String returns the name of the policy.
+func (p ReloadPolicy) String() string {
+	switch p {
+	case ReloadPartial:
+		return "partial"
+	case ReloadCrash:
+		return "crash"
+	}
+
+	return "keep-last-good"
+}
+
+// ReloadReport describes the result of the reload that found changes:
+// the decision made by the policy and the details of the failures.
+type ReloadReport struct {
+	Policy   ReloadPolicy  // policy that made the decision
+	Applied  []FieldChange // changes applied to the configuration
+	Rejected []FieldChange // changes that weren't applied
+	Err      error         // failures of the changes, nil if there are none
+}
+
+// SetPolicy sets the policy of the reloads with failed changes,
+// the default policy is ReloadKeepLastGood.
+func (r *Reloader) SetPolicy(policy ReloadPolicy) {
+	r.Lock()
+	defer r.Unlock()
+	r.policy = policy
+}
+
+// OnReload sets the function called with the report after each reload
+// that found changes, successful or not, so the service can choose the
+// safe behavior, like to report the degraded status or to drain the
+// connections. The nil function removes the previous one.
+//
+// # Examples
+//
+//	r.SetPolicy(env.ReloadPartial)
+//	r.OnReload(func(report env.ReloadReport) {
+//		for _, c := range report.Rejected {
+//			log.Printf("%s keeps the previous value", c.Key)
+//		}
+//		if report.Err != nil {
+//			log.Println(report.Err)
+//		}
+//	})
+func (r *Reloader) OnReload(fn func(ReloadReport)) {
+	r.Lock()
+	defer r.Unlock()
+	r.onReload = fn
+}
+
+// Reload compares the environment with the values applied last time and
+// decodes the changed fields only. Returns the list of the applied
+// changes. The subscribers of the changed keys (see Subscribe) are
+// notified.
+//
+// By default the changes are applied all-or-nothing: if any changed
+// value can't be decoded, the configuration keeps all its previous
+// values and the error is returned (see SetPolicy for other policies).
+// The failed changes are found again by the next reload.
+func (r *Reloader) Reload() ([]FieldChange, error) {
+	r.Lock()
+	report := r.reload()
+	onReload := r.onReload
+	r.Unlock()
+	r.limit.done(report.Err)
+
+	// The subscribers of the keys changed by other means (see Subscribe).
+	for _, list := range [][]FieldChange{report.Applied, report.Rejected} {
+		for _, c := range list {
+			notify(c.Key)
+		}
+	}
+
+	if len(report.Applied) != 0 || len(report.Rejected) != 0 {
+		if onReload != nil {
+			onReload(report)
+		}
+
+		if report.Err != nil && report.Policy == ReloadCrash {
+			panic(fmt.Sprintf("env: reload: %v", report.Err))
+		}
+	}
+
+	if len(report.Applied) != 0 && r.onChange != nil {
+		r.onChange(report.Applied)
+	}
+
+	return report.Applied, report.Err
+}
+
+// The reload performs reloading, the lock must be held.
+func (r *Reloader) reload() ReloadReport {
+	type update struct {
+		change FieldChange
+		item   reflect.Value // field of the object, if reachable
+		value  reflect.Value // new value of the field
+		ok     bool          // true if the field can be updated
+	}
+
+	var (
+		report  = ReloadReport{Policy: r.policy}
+		updates []update
+		errs    []error
+		current = make(map[string]rawValue, len(r.fields))
+	)
+
+	// Find changed keys and decode new values into temporary variables.
+	for _, fi := range r.fields {
+		raw := lookupRaw(fi)
+		value, ok := raw.value, raw.ok
+		current[fi.tg.key] = raw
+
+		old := r.values[fi.tg.key]
+		if old.ok == ok && old.value == value {
+			continue
+		}
+
+		u := update{change: FieldChange{
+			Key:      fi.tg.key,
+			Field:    fi.name,
+			Old:      old.value,
+			New:      value,
+			OldExist: old.ok,
+			NewExist: ok,
+		}}
+
+		item, reachable := fieldValue(reflect.ValueOf(r.obj), fi.path)
+		if !reachable {
+			updates = append(updates, u) // decoded by a custom unmarshaler
+			continue
+		}
+
+		tg := *fi.tg
+		if ok {
+			tg.value = value
+		} else if tg.required && tg.value == "" &&
+			!isStructMap(fi.field.Type) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrRequired, tg.key))
+			report.Rejected = append(report.Rejected, u.change)
+			continue
+		}
+
+		tmp := reflect.New(item.Type()).Elem()
+		if err := setFieldValue(&tmp, &tg, lookupEnv, environKeys); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tg.key, err))
+			report.Rejected = append(report.Rejected, u.change)
+			continue
+		}
+
+		u.item, u.value, u.ok = item, tmp, true
+		updates = append(updates, u)
+	}
+
+	// The object with custom unmarshaler is unmarshaled entirely into
+	// the new object, it replaces the configuration if there are no errors.
+	var fresh reflect.Value
+	_, custom := r.obj.(Unmarshaler)
+	_, partial := r.obj.(PartialUnmarshaler)
+	if len(updates) != 0 && len(errs) == 0 && (custom || partial) {
+		fresh = reflect.New(reflect.TypeOf(r.obj).Elem())
+		if err := unmarshalEnv(r.prefix, fresh.Interface()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	report.Err = errors.Join(errs...)
+	if report.Err != nil && (r.policy != ReloadPartial || custom || partial) {
+		// Nothing is applied, all changes are found again next time.
+		for _, u := range updates {
+			report.Rejected = append(report.Rejected, u.change)
+		}
+		return report
+	}
+
+	if fresh.IsValid() {
+		reflect.ValueOf(r.obj).Elem().Set(fresh.Elem())
+	}
+
+	for _, u := range updates {
+		if u.ok && !custom && !partial {
+			u.item.Set(u.value)
+		}
+		report.Applied = append(report.Applied, u.change)
+	}
+
+	// The raw values of the rejected changes aren't remembered,
+	// so they are found again by the next reload.
+	for _, c := range report.Rejected {
+		current[c.Key] = r.values[c.Key]
+	}
+	r.values = current
+
+	return report
+}
+
+// NextExpiry returns the key of the configuration that expires first
+// and its expiration time (see SetWithTTL). The boolean is false if no
+// key of the configuration has an expiration time. It allows to refresh
+// the source of the credentials and to call Reload before they expire.
+//
+// # Examples
+//
+//	if key, t, ok := r.NextExpiry(); ok {
+//		time.AfterFunc(time.Until(t)-time.Minute, func() {
+//			refreshToken(key) // sets the new value by env.SetWithTTL
+//			r.Reload()
+//		})
+//	}
+func (r *Reloader) NextExpiry() (string, time.Time, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var (
+		key   string
+		first time.Time
+	)
+
+	for _, fi := range r.fields {
+		t, ok := Expiry(fi.tg.key)
+		if ok && (key == "" || t.Before(first)) {
+			key, first = fi.tg.key, t
+		}
+	}
+
+	return key, first, key != ""
+}
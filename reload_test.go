@@ -0,0 +1,295 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// TestReloader tests Reloader.
+func TestReloader(t *testing.T) {
+	type nested struct {
+		Label string `env:"LABEL"`
+	}
+
+	type config struct {
+		Host   string   `env:"HOST" def:"localhost"`
+		Port   int      `env:"PORT"`
+		Debug  *bool    `env:"DEBUG"`
+		IPs    []string `env:"IPS" sep:","`
+		Nested *nested  `env:"NESTED"`
+	}
+
+	var (
+		cfg     config
+		changed []FieldChange
+	)
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_IPS", "127.0.0.1,127.0.0.2")
+	os.Setenv("APP_NESTED_LABEL", "a")
+
+	r, err := NewReloader("APP_", &cfg, func(c []FieldChange) {
+		changed = c
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Host != "localhost" || cfg.Port != 80 || len(cfg.IPs) != 2 {
+		t.Errorf("incorrect initial values: %v", cfg)
+	}
+
+	// Nothing changed.
+	if c, err := r.Reload(); err != nil || len(c) != 0 || changed != nil {
+		t.Errorf("expected no changes but %v, %v", c, err)
+	}
+
+	// Change some keys.
+	os.Setenv("APP_HOST", "0.0.0.0")
+	os.Setenv("APP_IPS", "10.0.0.1")
+	os.Setenv("APP_NESTED_LABEL", "b")
+	os.Setenv("APP_DEBUG", "true")
+
+	c, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c) != 4 || len(changed) != 4 {
+		t.Fatalf("expected 4 changes but %v", c)
+	}
+
+	if c[0].Key != "APP_HOST" || c[0].Field != "Host" ||
+		c[0].OldExist || c[0].New != "0.0.0.0" {
+		t.Errorf("incorrect change: %v", c[0])
+	}
+
+	if c[3].Field != "Nested.Label" {
+		t.Errorf("incorrect change: %v", c[3])
+	}
+
+	if cfg.Host != "0.0.0.0" || cfg.Nested.Label != "b" ||
+		len(cfg.IPs) != 1 || cfg.IPs[0] != "10.0.0.1" || !*cfg.Debug {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+
+	// The key is removed - the default value is used.
+	os.Unsetenv("APP_HOST")
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Host != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", cfg.Host)
+	}
+
+	// Incorrect value, nothing is changed.
+	os.Setenv("APP_HOST", "127.0.0.1")
+	os.Setenv("APP_PORT", "abc")
+	if _, err := r.Reload(); err == nil {
+		t.Error("an error is expected for incorrect value")
+	}
+
+	if cfg.Host != "localhost" || cfg.Port != 80 {
+		t.Errorf("the values shouldn't be changed: %v", cfg)
+	}
+
+	// The values are fixed.
+	os.Setenv("APP_PORT", "8080")
+	if c, err := r.Reload(); err != nil || len(c) != 2 {
+		t.Errorf("expected 2 changes but %v, %v", c, err)
+	}
+
+	if cfg.Host != "127.0.0.1" || cfg.Port != 8080 {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+}
+
+// TestReloaderPolicy tests the policies of Reloader.
+func TestReloaderPolicy(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		Key  string `env:"KEY" required:"true"`
+	}
+
+	var (
+		cfg     config
+		reports []ReloadReport
+	)
+
+	os.Clearenv()
+	os.Setenv("PORT", "80")
+	os.Setenv("KEY", "a")
+
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.OnReload(func(report ReloadReport) {
+		reports = append(reports, report)
+	})
+
+	// Keep the last good configuration.
+	os.Setenv("HOST", "0.0.0.0")
+	os.Setenv("PORT", "abc")
+	os.Unsetenv("KEY")
+	if c, err := r.Reload(); err == nil || len(c) != 0 {
+		t.Errorf("expected an error but %v, %v", c, err)
+	}
+
+	if len(reports) != 1 || len(reports[0].Rejected) != 3 ||
+		reports[0].Policy != ReloadKeepLastGood {
+		t.Fatalf("incorrect report: %+v", reports)
+	}
+
+	if !errors.Is(reports[0].Err, ErrRequired) {
+		t.Errorf("expected ErrRequired but %v", reports[0].Err)
+	}
+
+	if cfg.Host != "" || cfg.Port != 80 {
+		t.Errorf("the values shouldn't be changed: %v", cfg)
+	}
+
+	// Apply the partial updates.
+	r.SetPolicy(ReloadPartial)
+	c, err := r.Reload()
+	if err == nil || len(c) != 1 || c[0].Key != "HOST" {
+		t.Errorf("expected HOST change but %v, %v", c, err)
+	}
+
+	if cfg.Host != "0.0.0.0" || cfg.Port != 80 || cfg.Key != "a" {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+
+	if report := reports[1]; len(report.Applied) != 1 ||
+		len(report.Rejected) != 2 {
+		t.Errorf("incorrect report: %+v", report)
+	}
+
+	// The rejected changes are found again.
+	os.Setenv("PORT", "8080")
+	os.Setenv("KEY", "b")
+	if c, err := r.Reload(); err != nil || len(c) != 2 {
+		t.Errorf("expected 2 changes but %v, %v", c, err)
+	}
+
+	if cfg.Port != 8080 || cfg.Key != "b" || reports[2].Err != nil {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+
+	// Crash after the report.
+	r.SetPolicy(ReloadCrash)
+	os.Setenv("PORT", "abc")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		r.Reload()
+	}()
+
+	if len(reports) != 4 || reports[3].Policy != ReloadCrash {
+		t.Errorf("expected the report before panic: %+v", reports)
+	}
+}
+
+// reloadCustom is the configuration with the custom unmarshaler
+// that changes the fields before it fails.
+type reloadCustom struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+}
+
+// UnmarshalEnv implements Unmarshaler.
+func (c *reloadCustom) UnmarshalEnv() (err error) {
+	c.Host = Get("HOST")
+	c.Port, err = strconv.Atoi(Get("PORT"))
+	return err
+}
+
+// TestReloaderCustom tests that the failed reload doesn't change
+// the object with the custom unmarshaler.
+func TestReloaderCustom(t *testing.T) {
+	var cfg reloadCustom
+
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("PORT", "80")
+
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("HOST", "0.0.0.0")
+	os.Setenv("PORT", "abc")
+	if c, err := r.Reload(); err == nil || len(c) != 0 {
+		t.Errorf("expected an error but %v, %v", c, err)
+	}
+
+	if cfg.Host != "localhost" || cfg.Port != 80 {
+		t.Errorf("the values shouldn't be changed: %v", cfg)
+	}
+
+	os.Setenv("PORT", "8080")
+	if c, err := r.Reload(); err != nil || len(c) != 2 {
+		t.Errorf("expected 2 changes but %v, %v", c, err)
+	}
+
+	if cfg.Host != "0.0.0.0" || cfg.Port != 8080 {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+}
+
+// TestReloaderStructMap tests the changes of
+// the entries of the maps of structures.
+func TestReloaderStructMap(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST"`
+	}
+
+	var cfg struct {
+		Port      int           `env:"PORT"`
+		Databases map[string]db `env:"DB"`
+	}
+
+	os.Clearenv()
+	os.Setenv("DB_MAIN_HOST", "db1")
+
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("DB_MAIN_HOST", "db2")
+	os.Setenv("DB_LOGS_HOST", "db3")
+	c, err := r.Reload()
+	if err != nil || len(c) != 1 || c[0].Key != "DB" {
+		t.Errorf("expected DB change but %v, %v", c, err)
+	}
+
+	expected := map[string]db{"MAIN": {Host: "db2"}, "LOGS": {Host: "db3"}}
+	if !reflect.DeepEqual(cfg.Databases, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, cfg.Databases)
+	}
+
+	// The removed entry.
+	os.Unsetenv("DB_LOGS_HOST")
+	if c, err := r.Reload(); err != nil || len(c) != 1 {
+		t.Errorf("expected 1 change but %v, %v", c, err)
+	}
+
+	if _, ok := cfg.Databases["LOGS"]; ok || len(cfg.Databases) != 1 {
+		t.Errorf("the LOGS entry should be removed: %v", cfg.Databases)
+	}
+
+	if c, err := r.Reload(); err != nil || len(c) != 0 {
+		t.Errorf("expected no changes but %v, %v", c, err)
+	}
+}
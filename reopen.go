@@ -0,0 +1,81 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ReopenFiles re-reads the secrets referenced by the keys with the prefix
+// and the _FILE suffix (like the Docker secrets convention): the content
+// of the file from the DB_PASSWORD_FILE key is set as the value of the
+// DB_PASSWORD key (without one trailing newline). Only the values that
+// have changed are set, returns the sorted list of the changed keys.
+//
+// It allows to pick up the certificates and credentials rotated by the
+// sidecars without reloading the whole configuration, for example, on
+// the signal or the change of the file (see FileWatcher), the Reloader
+// then updates the fields of the changed keys only. It's safe to call
+// the function concurrently.
+//
+// The unreadable file doesn't stop re-reading the other files, its key
+// keeps the previous value and the KeyError is returned (all errors
+// are joined).
+//
+// # Examples
+//
+//	sig := make(chan os.Signal, 1)
+//	signal.Notify(sig, syscall.SIGHUP)
+//	for range sig {
+//		if keys, err := env.ReopenFiles("APP_"); err != nil {
+//			log.Println(err)
+//		} else if len(keys) != 0 {
+//			r.Reload() // see Reloader
+//		}
+//	}
+func ReopenFiles(prefix string) ([]string, error) {
+	var (
+		changed []string
+		errs    []error
+	)
+
+	files := make(map[string]string)
+	for _, item := range environ() {
+		key, path, _ := strings.Cut(item, "=")
+		if path == "" || !strings.HasPrefix(key, prefix) ||
+			!strings.HasSuffix(key, fileKeySuffix) ||
+			len(key) == len(fileKeySuffix) {
+			continue
+		}
+		files[strings.TrimSuffix(key, fileKeySuffix)] = path
+	}
+
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data, err := os.ReadFile(files[key])
+		if err != nil {
+			errs = append(errs, &KeyError{Key: key + fileKeySuffix, Err: err})
+			continue
+		}
+
+		value := strings.TrimSuffix(string(data), "\n")
+		value = strings.TrimSuffix(value, "\r")
+		if old, ok := getenv(key); ok && old == value {
+			continue
+		}
+
+		if err := setenv(key, value); err != nil {
+			errs = append(errs, &KeyError{Key: key, Err: err})
+			continue
+		}
+		changed = append(changed, key)
+	}
+
+	return changed, errors.Join(errs...)
+}
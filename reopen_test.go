@@ -0,0 +1,62 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestReopenFiles tests ReopenFiles function.
+func TestReopenFiles(t *testing.T) {
+	dir := t.TempDir()
+	password := filepath.Join(dir, "password")
+	cert := filepath.Join(dir, "cert")
+	os.WriteFile(password, []byte("secret\n"), 0o600)
+	os.WriteFile(cert, []byte("CERT"), 0o600)
+
+	os.Clearenv()
+	os.Setenv("APP_DB_PASSWORD_FILE", password)
+	os.Setenv("APP_TLS_CERT_FILE", cert)
+	os.Setenv("APP_EMPTY_FILE", "")
+	os.Setenv("OTHER_FILE", cert)
+
+	keys, err := ReopenFiles("APP_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"APP_DB_PASSWORD", "APP_TLS_CERT"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, keys)
+	}
+
+	if v := Get("APP_DB_PASSWORD"); v != "secret" {
+		t.Errorf("expected `secret` but `%s`", v)
+	}
+
+	if Exists("OTHER") || Exists("APP_EMPTY") {
+		t.Error("the keys without prefix or path shouldn't be set")
+	}
+
+	// The rotated secret only.
+	os.WriteFile(password, []byte("rotated\n"), 0o600)
+	keys, err = ReopenFiles("APP_")
+	if err != nil || len(keys) != 1 || keys[0] != "APP_DB_PASSWORD" {
+		t.Errorf("expected `[APP_DB_PASSWORD]` but `%v` (%v)", keys, err)
+	}
+
+	// The removed file.
+	os.Remove(cert)
+	var keyErr *KeyError
+	keys, err = ReopenFiles("APP_")
+	if !errors.As(err, &keyErr) || keyErr.Key != "APP_TLS_CERT_FILE" ||
+		len(keys) != 0 {
+		t.Errorf("expected KeyError but `%v` (%v)", keys, err)
+	}
+
+	if v := Get("APP_TLS_CERT"); v != "CERT" {
+		t.Errorf("expected `CERT` but `%s`", v)
+	}
+}
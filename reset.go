@@ -0,0 +1,80 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Reset sets all fields of the structure that are mapped to keys in
+// the environment to zero values. The nested structures are processed
+// recursively (the allocated pointers to them are kept).
+//
+// If unset is true, the environment variables of the fields (with the
+// prefix and the aliases) and of the entries of the maps of structures
+// (like DB_MAIN_HOST) are unset too, in this case ErrFrozen is returned if the
+// environment is frozen by Freeze and the object isn't changed.
+//
+// The obj must be a non-nil pointer to a structure.
+//
+// # Examples
+//
+//	var config Config
+//	env.Unmarshal("APP_", &config)
+//	// ...
+//	env.Reset("APP_", &config, true) // config is zero, APP_* are unset
+func Reset(prefix string, obj interface{}, unset bool) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return errors.New("obj should be a non-nil pointer to a struct")
+	}
+
+	if unset && frozen.Load() {
+		return ErrFrozen
+	}
+
+	var keys []string
+	err := walkFields(prefix, rv.Type(), func(fi fieldInfo) error {
+		keys = append(append(keys, fi.tg.key), fi.tg.alias...)
+
+		// The entries of the maps of structures,
+		// like DB_MAIN_HOST for the DB map.
+		if unset && isStructMap(fi.field.Type) {
+			items, err := mapItems(fi.tg.key+"_", fi.field.Type)
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				key, _, _ := strings.Cut(item, "=")
+				keys = append(keys, key)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The fields are set to zero values after all keys are found,
+	// so the object isn't changed on error.
+	walkFields(prefix, rv.Type(), func(fi fieldInfo) error {
+		if item, ok := fieldValue(rv, fi.path); ok && item.CanSet() {
+			item.Set(reflect.Zero(item.Type()))
+		}
+		return nil
+	})
+	if !unset {
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := unsetenv(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
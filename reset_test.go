@@ -0,0 +1,71 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestReset tests Reset function.
+func TestReset(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+	}
+
+	type Config struct {
+		Port  int           `env:"PORT|LISTEN"`
+		Hosts []string      `env:"HOSTS"`
+		DB    *DB           `env:"DB"`
+		Pools map[string]DB `env:"POOL"`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_DB_HOST", "localhost")
+	os.Setenv("APP_LISTEN", "8080")
+	os.Setenv("APP_POOL_MAIN_HOST", "main")
+	os.Setenv("OTHER", "value")
+
+	var config Config
+	if err := Unmarshal("APP_", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	// Zero fields only.
+	if err := Reset("APP_", &config, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Port != 0 || config.Hosts != nil || config.DB == nil ||
+		config.DB.Host != "" || config.Pools != nil {
+		t.Errorf("incorrect result: %v", config)
+	}
+
+	if !Exists("APP_PORT", "APP_DB_HOST", "APP_LISTEN",
+		"APP_POOL_MAIN_HOST") {
+		t.Error("the environment shouldn't be changed")
+	}
+
+	// Zero fields and unset variables.
+	config.Port = 80
+	if err := Reset("APP_", &config, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Port != 0 || ExistsPrefix("APP_") || !Exists("OTHER") {
+		t.Errorf("incorrect result: %v %v", config, Environ())
+	}
+
+	// Frozen environment.
+	config.Port = 80
+	Freeze()
+	defer frozen.Store(false)
+
+	if err := Reset("APP_", &config, true); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	if config.Port != 80 {
+		t.Error("the object shouldn't be changed")
+	}
+}
@@ -0,0 +1,172 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RetryPolicy describes how to retry the requests to the sources that
+// can be temporarily unavailable (remote services, network filesystems).
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts,
+	// the values less than 1 mean one attempt.
+	Attempts int
+
+	// Timeout is the timeout of one attempt, zero means no timeout
+	// (only the timeout of the parent context is used).
+	Timeout time.Duration
+
+	// Backoff is the delay before the second attempt,
+	// the delay is doubled before each next attempt.
+	Backoff time.Duration
+
+	// MaxBackoff limits the delay between attempts,
+	// zero means no limit.
+	MaxBackoff time.Duration
+}
+
+// Retry calls fn until it succeeds or the attempts of the policy are
+// exhausted. Each attempt gets the context with the timeout of the
+// policy. If all attempts fail, the error wraps ErrSourceUnavailable
+// and the last error of fn.
+//
+// The attempt is cut off by the timeout even if fn doesn't check the
+// context (like the read of the hung network filesystem): fn keeps
+// running in the background, but its result is ignored and the next
+// attempt is made. So fn must be safe to be called concurrently.
+//
+// The retrying stops immediately if the parent context is done.
+//
+// # Examples
+//
+//	policy := env.RetryPolicy{Attempts: 5, Backoff: 100 * time.Millisecond}
+//	err := env.Retry(ctx, policy, func(ctx context.Context) error {
+//		return env.Load("/mnt/nfs/.env")
+//	})
+func Retry(ctx context.Context, policy RetryPolicy,
+	fn func(ctx context.Context) error) error {
+	var (
+		err     error
+		backoff = policy.Backoff
+	)
+
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for i := 0; i < attempts; i++ {
+		if i > 0 && backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%w: %w", ErrSourceUnavailable, ctx.Err())
+			case <-timer.C:
+			}
+
+			backoff *= 2
+			if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+
+		err = attempt(ctx, policy.Timeout, fn)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrSourceUnavailable, err)
+}
+
+// The attempt calls fn with the context limited by the timeout.
+// The fn is called in the goroutine, so the attempt returns the error
+// of the context when it's done, without waiting for fn.
+func attempt(ctx context.Context, timeout time.Duration,
+	fn func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if ctx.Done() == nil {
+		return fn(ctx) // the context is never done
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// The retrySource is the Source that retries the failed lookups.
+type retrySource struct {
+	src    Source
+	policy RetryPolicy
+}
+
+// WithRetry returns the source that retries the failed lookups of the
+// src according to the policy. The keys are listed (see KeyLister) with
+// the retries too, if the src implements the KeyLister interface.
+func WithRetry(src Source, policy RetryPolicy) Source {
+	return &retrySource{src: src, policy: policy}
+}
+
+// Lookup returns the value of the key from the source.
+func (s *retrySource) Lookup(ctx context.Context,
+	key string) (value string, ok bool, err error) {
+	// The result of the attempt cut off by the timeout is ignored.
+	var mu sync.Mutex
+	err = Retry(ctx, s.policy, func(ctx context.Context) error {
+		v, found, e := s.src.Lookup(ctx, key)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if e == nil && ctx.Err() == nil {
+			value, ok = v, found
+		}
+		return e
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	return value, ok, err
+}
+
+// Keys returns the keys of the source, nil if it doesn't list them.
+func (s *retrySource) Keys(ctx context.Context) (keys []string, err error) {
+	lister, ok := s.src.(KeyLister)
+	if !ok {
+		return nil, nil
+	}
+
+	// The result of the attempt cut off by the timeout is ignored.
+	var mu sync.Mutex
+	err = Retry(ctx, s.policy, func(ctx context.Context) error {
+		list, e := lister.Keys(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if e == nil && ctx.Err() == nil {
+			keys = list
+		}
+		return e
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	return keys, err
+}
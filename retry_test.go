@@ -0,0 +1,124 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRetry tests Retry function.
+func TestRetry(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		calls  = 0
+		failed = errors.New("failed")
+		policy = RetryPolicy{
+			Attempts: 3,
+			Timeout:  time.Second,
+			Backoff:  time.Millisecond,
+		}
+	)
+
+	// Success on the last attempt.
+	err := Retry(ctx, policy, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("the attempt should have a deadline")
+		}
+
+		if calls++; calls < 3 {
+			return failed
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success after 3 calls but %v after %d", err, calls)
+	}
+
+	// All attempts fail.
+	calls = 0
+	err = Retry(ctx, policy, func(ctx context.Context) error {
+		calls++
+		return failed
+	})
+	if !errors.Is(err, ErrSourceUnavailable) || !errors.Is(err, failed) {
+		t.Errorf("expected ErrSourceUnavailable but %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls but %d", calls)
+	}
+
+	// The parent context is canceled.
+	calls = 0
+	cctx, cancel := context.WithCancel(ctx)
+	err = Retry(cctx, policy, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrSourceUnavailable) || calls != 1 {
+		t.Errorf("expected 1 call but %d (%v)", calls, err)
+	}
+}
+
+// TestWithRetry tests WithRetry function.
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	src := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		if calls++; calls < 2 {
+			return "", false, errors.New("unavailable")
+		}
+		return "value", true, nil
+	})
+
+	ctx := context.Background()
+	value, ok, err := WithRetry(src, RetryPolicy{Attempts: 2}).
+		Lookup(ctx, "KEY")
+	if err != nil || !ok || value != "value" {
+		t.Errorf("expected `value` but `%s` (%v)", value, err)
+	}
+
+	data := struct {
+		Key string `env:"KEY"`
+	}{}
+
+	calls = 0
+	src2 := WithRetry(src, RetryPolicy{Attempts: 1})
+	if err := UnmarshalSource(ctx, src2, "", &data); err == nil {
+		t.Error("an error is expected for unavailable source")
+	}
+}
+
+// TestRetryTimeout tests that the hung attempt is cut off by the timeout.
+func TestRetryTimeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+
+	// The source ignores the context, like the hung read of the file.
+	src := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		calls.Add(1)
+		<-release
+		return "value", true, nil
+	})
+
+	policy := RetryPolicy{Attempts: 2, Timeout: 10 * time.Millisecond}
+	start := time.Now()
+	_, _, err := WithRetry(src, policy).Lookup(context.Background(), "KEY")
+	if !errors.Is(err, ErrSourceUnavailable) ||
+		!errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the timeout error but `%v`", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("the attempts aren't cut off: %v", d)
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 attempts but %d", n)
+	}
+}
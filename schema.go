@@ -0,0 +1,228 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The schemaDraft is the JSON Schema version of the JSONSchema output.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns the JSON Schema that describes the environment
+// variables of the structure as a flat object: each key (with the
+// prefix) is a property with the type of the field, default value from
+// def tag, description from desc tag and enum from oneof rule of the
+// validate tag (like `validate:"oneof=debug info error"`). The keys
+// with required tag are listed as required properties.
+//
+// The integer, float and boolean fields have the corresponding JSON
+// types, the arrays and slices are described as arrays of their items,
+// url.URL is a string with uri format, the secret fields are marked as
+// writeOnly. The fields of the entries of the maps of structures are
+// described by patternProperties, like ^DB_.+_HOST$.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host  string `env:"HOST" def:"localhost" desc:"server host"`
+//		Port  int    `env:"PORT" required:"true"`
+//		Level string `env:"LEVEL" validate:"oneof=debug info"`
+//	}
+//
+//	data, err := env.JSONSchema("APP_", Config{})
+//	// {
+//	//   "$schema": "https://json-schema.org/draft/2020-12/schema",
+//	//   "properties": {
+//	//     "APP_HOST": {
+//	//       "default": "localhost",
+//	//       "description": "server host",
+//	//       "type": "string"
+//	//     },
+//	//     ...
+func JSONSchema(prefix string, obj interface{}) ([]byte, error) {
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return nil, fmt.Errorf("incorrect type: %v", t)
+	}
+
+	properties := make(map[string]interface{})
+	patterns := make(map[string]interface{})
+	required := make([]string, 0)
+	err := walkFields(prefix, t, func(fi fieldInfo) error {
+		// The fields of the entries of the maps of structures
+		// are described by the patterns of their keys.
+		if isStructMap(fi.field.Type) {
+			return walkMapFields(fi, func(fi fieldInfo) error {
+				property, err := schemaProperty(fi)
+				if err != nil {
+					return fmt.Errorf("the %s field: %w", fi.name, err)
+				}
+
+				pattern := strings.ReplaceAll(regexp.QuoteMeta(fi.tg.key),
+					mapNamePlaceholder, ".+")
+				patterns["^"+pattern+"$"] = property
+				return nil
+			})
+		}
+
+		property, err := schemaProperty(fi)
+		if err != nil {
+			return fmt.Errorf("the %s field: %w", fi.name, err)
+		}
+
+		properties[fi.tg.key] = property
+		if fi.tg.required {
+			required = append(required, fi.tg.key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schema := map[string]interface{}{
+		"$schema":    schemaDraft,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+
+	if len(patterns) != 0 {
+		schema["patternProperties"] = patterns
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// The schemaProperty returns the schema of the field.
+func schemaProperty(fi fieldInfo) (map[string]interface{}, error) {
+	property := schemaType(fi.field.Type)
+	if fi.tg.desc != "" {
+		property["description"] = fi.tg.desc
+	}
+
+	if fi.tg.secret {
+		property["writeOnly"] = true
+	}
+
+	// Default value as a value of the field type.
+	if fi.tg.value != "" {
+		item := reflect.New(fi.field.Type).Elem()
+		if err := setFieldValue(&item, fi.tg, nil, nil); err != nil {
+			return nil, err
+		}
+		property["default"] = schemaValue(item)
+	}
+
+	// Enum from the oneof rule of the validate tag.
+	for _, rule := range strings.Split(fi.field.Tag.Get("validate"), ",") {
+		values, ok := strings.CutPrefix(rule, "oneof=")
+		if !ok {
+			continue
+		}
+
+		t := fi.field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if k := t.Kind(); k == reflect.Array || k == reflect.Slice {
+			t = t.Elem()
+		}
+
+		enum := make([]interface{}, 0)
+		for _, value := range strings.Fields(values) {
+			item := reflect.New(t).Elem()
+			if err := setValue(item, value); err != nil {
+				return nil, err
+			}
+			enum = append(enum, schemaValue(item))
+		}
+
+		if _, ok := property["items"]; ok {
+			property["items"].(map[string]interface{})["enum"] = enum
+		} else {
+			property["enum"] = enum
+		}
+	}
+
+	return property, nil
+}
+
+// The durationPattern is the pattern of the time.Duration value
+// in the format of the time.ParseDuration.
+const durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+// The schemaType returns the schema of the type.
+func schemaType(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		return map[string]interface{}{
+			"type":    "string",
+			"pattern": durationPattern,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Array:
+		return map[string]interface{}{
+			"type":     "array",
+			"items":    schemaType(t.Elem()),
+			"maxItems": t.Len(),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaType(t.Elem()),
+		}
+	case reflect.Struct:
+		if t == reflect.TypeOf(url.URL{}) {
+			return map[string]interface{}{"type": "string", "format": "uri"}
+		}
+	}
+
+	return map[string]interface{}{"type": "string"}
+}
+
+// The schemaValue returns the value of the item for the JSON Schema.
+func schemaValue(item reflect.Value) interface{} {
+	item = reflect.Indirect(item)
+	if !item.IsValid() {
+		return nil
+	}
+
+	if d, ok := item.Interface().(time.Duration); ok {
+		return d.String()
+	}
+
+	switch item.Kind() {
+	case reflect.Array, reflect.Slice:
+		result := make([]interface{}, item.Len())
+		for i := range result {
+			result[i] = schemaValue(item.Index(i))
+		}
+		return result
+	case reflect.Struct:
+		if u, ok := item.Interface().(url.URL); ok {
+			return u.String()
+		}
+	}
+
+	return item.Interface()
+}
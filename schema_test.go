@@ -0,0 +1,104 @@
+package env
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// TestJSONSchema tests JSONSchema function.
+func TestJSONSchema(t *testing.T) {
+	type DB struct {
+		URL      url.URL `env:"URL" def:"postgres://localhost"`
+		Password string  `env:"PASSWORD" secret:"true"`
+	}
+
+	type Config struct {
+		Host  string        `env:"HOST" def:"localhost" desc:"server host"`
+		Port  int           `env:"PORT" required:"true"`
+		Rate  float64       `env:"RATE" def:"0.5"`
+		Debug *bool         `env:"DEBUG" def:"true"`
+		Level string        `env:"LEVEL" validate:"required,oneof=debug info"`
+		Codes [2]int        `env:"CODES" def:"200,404" sep:","`
+		DB    DB            `env:"DB"`
+		Pools map[string]DB `env:"POOL"`
+		Wait  time.Duration `env:"WAIT" def:"90s"`
+	}
+
+	data, err := JSONSchema("APP_", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result, expected map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	json.Unmarshal([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"APP_HOST": {"type": "string", "default": "localhost",
+				"description": "server host"},
+			"APP_PORT": {"type": "integer"},
+			"APP_RATE": {"type": "number", "default": 0.5},
+			"APP_DEBUG": {"type": "boolean", "default": true},
+			"APP_LEVEL": {"type": "string", "enum": ["debug", "info"]},
+			"APP_CODES": {"type": "array", "items": {"type": "integer"},
+				"maxItems": 2, "default": [200, 404]},
+			"APP_DB_URL": {"type": "string", "format": "uri",
+				"default": "postgres://localhost"},
+			"APP_DB_PASSWORD": {"type": "string", "writeOnly": true}
+		},
+		"patternProperties": {
+			"^APP_POOL_.+_URL$": {"type": "string", "format": "uri",
+				"default": "postgres://localhost"},
+			"^APP_POOL_.+_PASSWORD$": {"type": "string", "writeOnly": true}
+		},
+		"required": ["APP_PORT"]
+	}`), &expected)
+	expected["properties"].(map[string]interface{})["APP_WAIT"] =
+		map[string]interface{}{
+			"type":    "string",
+			"pattern": durationPattern,
+			"default": "1m30s",
+		}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected:\n%v\nbut:\n%s", expected, data)
+	}
+
+	// Incorrect default value.
+	var wrong struct {
+		Port int `env:"PORT" def:"http"`
+	}
+
+	if _, err := JSONSchema("", wrong); err == nil {
+		t.Error("an error is expected for incorrect default value")
+	}
+}
+
+// TestJSONSchemaDuration tests the pattern of the time.Duration.
+func TestJSONSchemaDuration(t *testing.T) {
+	re := regexp.MustCompile(durationPattern)
+	for _, value := range []string{"0", "90s", "1h30m", "-1.5h", "+.5s",
+		"300ms", "2us", "10µs", "1h2m3s4ms5us6ns"} {
+		if _, err := time.ParseDuration(value); err != nil {
+			t.Fatal(err)
+		}
+
+		if !re.MatchString(value) {
+			t.Errorf("the `%s` doesn't match the pattern", value)
+		}
+	}
+
+	for _, value := range []string{"", "90", "1d", "s", ".s", "1h 30m"} {
+		if re.MatchString(value) {
+			t.Errorf("the `%s` matches the pattern", value)
+		}
+	}
+}
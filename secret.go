@@ -0,0 +1,130 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// The secretStringType is the reflect.Type of the SecretString.
+var secretStringType = reflect.TypeOf(SecretString{})
+
+// SecretString holds the secret value (password, token, etc.) outside
+// of the Go heap: the memory is allocated by mmap and locked by mlock
+// (where it's supported), so the value isn't swapped to disk and isn't
+// included in heap dumps. The memory is zeroed and released by Destroy
+// or when the value is garbage collected.
+//
+// The String, GoString and Format methods return the mask instead of
+// the value, so the secret isn't leaked to logs by mistake. Use Value
+// to get the secret.
+//
+// The fields of this type are unmarshaled from the environment like
+// strings, so it can be used for the fields marked by the secret tag
+// to keep their values in the protected memory.
+//
+// # Examples
+//
+//	type Config struct {
+//		Password env.SecretString `env:"PASSWORD" secret:"true"`
+//	}
+//
+//	var config Config
+//	env.Unmarshal("", &config)
+//	fmt.Println(config.Password)       // ******
+//	db.Connect(config.Password.Value()) // real password
+type SecretString struct {
+	data *secretData
+}
+
+// The secretData is the memory of the secret.
+type secretData struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewSecretString returns the SecretString with the copy of the value.
+// Note that the value itself stays in the memory as is, so the source
+// of the value should be cleaned up if it's possible.
+func NewSecretString(value string) SecretString {
+	if value == "" {
+		return SecretString{}
+	}
+
+	d := &secretData{buf: allocSecret(len(value))}
+	copy(d.buf, value)
+	runtime.SetFinalizer(d, (*secretData).destroy)
+
+	return SecretString{data: d}
+}
+
+// Value returns the secret value. The empty string is
+// returned if the secret is empty or destroyed.
+func (s SecretString) Value() string {
+	if s.data == nil {
+		return ""
+	}
+
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+	return string(s.data.buf)
+}
+
+// IsZero returns true if the secret is empty or destroyed.
+func (s SecretString) IsZero() bool {
+	if s.data == nil {
+		return true
+	}
+
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+	return len(s.data.buf) == 0
+}
+
+// Destroy zeroes and releases the memory of the secret.
+// All copies of the SecretString become empty.
+func (s SecretString) Destroy() {
+	if s.data != nil {
+		s.data.destroy()
+	}
+}
+
+// String returns the mask instead of the secret value.
+func (s SecretString) String() string {
+	return maskValue
+}
+
+// GoString returns the mask instead of the secret value.
+func (s SecretString) GoString() string {
+	return fmt.Sprintf("%q", maskValue)
+}
+
+// Format writes the mask instead of the secret value for all verbs.
+func (s SecretString) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, s.GoString())
+		return
+	}
+
+	fmt.Fprint(f, maskValue)
+}
+
+// MarshalJSON returns the mask instead of the secret value.
+func (s SecretString) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + maskValue + `"`), nil
+}
+
+// The destroy zeroes and releases the memory.
+func (d *secretData) destroy() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.buf != nil {
+		for i := range d.buf {
+			d.buf[i] = 0
+		}
+		freeSecret(d.buf)
+		d.buf = nil
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package env
+
+// The allocSecret allocates the memory for the secret,
+// the memory locking isn't supported on this platform.
+func allocSecret(size int) []byte {
+	return make([]byte, size)
+}
+
+// The freeSecret releases the memory allocated by allocSecret.
+func freeSecret(buf []byte) {}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestSecretString tests SecretString type.
+func TestSecretString(t *testing.T) {
+	s := NewSecretString("qwerty")
+	if s.Value() != "qwerty" || s.IsZero() {
+		t.Errorf("expected `qwerty` but `%s`", s.Value())
+	}
+
+	for _, format := range []string{"%s", "%v", "%+v", "%q", "%x"} {
+		if v := fmt.Sprintf(format, s); v != maskValue {
+			t.Errorf("%s: expected `%s` but `%s`", format, maskValue, v)
+		}
+	}
+
+	if v := fmt.Sprintf("%#v", s); v != `"******"` {
+		t.Errorf("expected `\"******\"` but `%s`", v)
+	}
+
+	data, _ := json.Marshal(struct{ S SecretString }{s})
+	if string(data) != `{"S":"******"}` {
+		t.Errorf("incorrect json: %s", data)
+	}
+
+	// Destroy the secret, the copies are destroyed too.
+	c := s
+	s.Destroy()
+	if c.Value() != "" || !c.IsZero() {
+		t.Errorf("expected empty value but `%s`", c.Value())
+	}
+
+	if v := NewSecretString(""); !v.IsZero() || v.Value() != "" {
+		t.Error("expected empty secret")
+	}
+}
+
+// TestSecretStringUnmarshal tests Unmarshal and Marshal
+// with SecretString fields.
+func TestSecretStringUnmarshal(t *testing.T) {
+	type Config struct {
+		Password SecretString   `env:"PASSWORD"`
+		Token    *SecretString  `env:"TOKEN"`
+		Keys     []SecretString `env:"KEYS" sep:","`
+	}
+
+	os.Clearenv()
+	os.Setenv("PASSWORD", "qwerty")
+	os.Setenv("TOKEN", "abc")
+	os.Setenv("KEYS", "a,b")
+
+	var config Config
+	if err := Unmarshal("", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Password.Value() != "qwerty" || config.Token == nil ||
+		config.Token.Value() != "abc" || len(config.Keys) != 2 ||
+		config.Keys[1].Value() != "b" {
+		t.Errorf("incorrect result: %#v", config)
+	}
+
+	if v := Redacted(config).String(); v != "{Password:****** "+
+		"Token:&****** Keys:[****** ******]}" {
+		t.Errorf("incorrect redacted value: %s", v)
+	}
+
+	items, err := marshalEnv("", &config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 3 || items[0] != "PASSWORD=qwerty" ||
+		items[1] != "TOKEN=abc" || items[2] != "KEYS=a,b" {
+		t.Errorf("incorrect marshal result: %v", items)
+	}
+
+	pairs, err := effectiveConfig("", &config, []string{})
+	if err != nil || pairs[0].value != maskValue ||
+		pairs[1].value != maskValue || pairs[2].value != "a,b" {
+		t.Errorf("incorrect effective config: %v (%v)", pairs, err)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package env
+
+import (
+	"os"
+	"syscall"
+)
+
+// The allocSecret allocates the memory for the secret outside
+// of the Go heap and locks it (if the limits allow it).
+func allocSecret(size int) []byte {
+	page := os.Getpagesize()
+	length := (size + page - 1) / page * page
+
+	buf, err := syscall.Mmap(-1, 0, length,
+		syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size)
+	}
+
+	syscall.Mlock(buf) // the lock can fail because of RLIMIT_MEMLOCK
+	return buf[:size]
+}
+
+// The freeSecret releases the memory allocated by allocSecret.
+func freeSecret(buf []byte) {
+	buf = buf[:cap(buf)]
+	syscall.Munlock(buf)
+	syscall.Munmap(buf)
+}
@@ -0,0 +1,169 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Finding is the probable real credential found by ScanSecrets.
+type Finding struct {
+	Line int    // line number, starting from 1
+	Key  string // key name, empty if the line isn't an expression
+	Rule string // name of the rule, like aws-access-key
+}
+
+// String returns the finding as a string, the value isn't shown.
+func (f Finding) String() string {
+	if f.Key == "" {
+		return fmt.Sprintf("%d %s", f.Line, f.Rule)
+	}
+
+	return fmt.Sprintf("%d %s: %s", f.Line, f.Key, f.Rule)
+}
+
+// The secretRule is the pattern of the known credential.
+type secretRule struct {
+	name string
+	rgx  *regexp.Regexp
+}
+
+var (
+	// The secretRules are the patterns of the known credentials.
+	secretRules = []secretRule{
+		{"aws-access-key", regexp.MustCompile(
+			`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{"jwt", regexp.MustCompile(
+			`\beyJ[\w-]{8,}\.eyJ[\w-]{8,}\.[\w-]{8,}`)},
+		{"github-token", regexp.MustCompile(
+			`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{"slack-token", regexp.MustCompile(
+			`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+		{"private-key", regexp.MustCompile(
+			`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+	}
+
+	// The secretKeyRgx matches the names of the keys
+	// that usually contain credentials.
+	secretKeyRgx = regexp.MustCompile(
+		`(?i)SECRET|PASSWORD|PASSWD|TOKEN|API_?KEY|PRIVATE|CREDENTIAL`)
+
+	// The placeholderRgx matches the values that are
+	// obviously not real credentials.
+	placeholderRgx = regexp.MustCompile(
+		`(?i)^(?:<.*>|\$\{?\w+\}?|x+|\*+|.*(?:change_?me|example|` +
+			`placeholder|your[_-]).*)$`)
+)
+
+const (
+	// The minSecretLength is the minimum length of the value
+	// checked by the entropy.
+	minSecretLength = 16
+
+	// The keyEntropy is the minimum entropy (bits per character)
+	// of the value of the key with the secret-like name.
+	keyEntropy = 3.5
+
+	// The valueEntropy is the minimum entropy (bits per character)
+	// of the value of the key with any name.
+	valueEntropy = 4.5
+)
+
+// ScanSecrets scans the env-file for probable real credentials that
+// shouldn't be committed: AWS access keys, JWTs, GitHub and Slack tokens,
+// private key headers, and random-looking values (with the high Shannon
+// entropy) especially of the keys like *_SECRET, *_TOKEN, *_PASSWORD.
+// The obvious placeholders like <token>, changeme, xxx are ignored.
+//
+// The heuristics can give false positives, so the findings should be
+// reviewed. It can be used in the pre-commit hooks.
+//
+// # Examples
+//
+//	findings, err := env.ScanSecrets(".env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, f := range findings {
+//		fmt.Printf(".env:%s\n", f) // .env:3 AWS_KEY_ID: aws-access-key
+//	}
+func ScanSecrets(filename string) ([]Finding, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var findings []Finding
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(text), "#") {
+			continue
+		}
+
+		key, value, err := parseExpression(text)
+		if err != nil {
+			// The multi-line values like private keys
+			// aren't parsed, so check the raw line.
+			key, value = "", text
+		}
+
+		if rule := scanValue(key, value); rule != "" {
+			findings = append(findings, Finding{line, key, rule})
+		}
+	}
+
+	return findings, scanner.Err()
+}
+
+// The scanValue returns the name of the rule
+// if the value looks like a real credential.
+func scanValue(key, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || placeholderRgx.MatchString(value) {
+		return ""
+	}
+
+	for _, rule := range secretRules {
+		if rule.rgx.MatchString(value) {
+			return rule.name
+		}
+	}
+
+	if len(value) < minSecretLength || strings.ContainsAny(value, " \t") {
+		return ""
+	}
+
+	entropy := shannonEntropy(value)
+	if entropy >= valueEntropy ||
+		(key != "" && secretKeyRgx.MatchString(key) &&
+			entropy >= keyEntropy) {
+		return "high-entropy"
+	}
+
+	return ""
+}
+
+// The shannonEntropy returns the Shannon entropy
+// of the string in bits per character.
+func shannonEntropy(str string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range str {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"reflect"
+	"time"
+)
+
+// SetInt sets the integer value of the environment variable named by
+// the key. The value is formatted in the same way as Marshal does it,
+// so it can be read back by Unmarshal into any integer field.
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
+func SetInt(key string, value int64) error {
+	return setTyped(key, reflect.ValueOf(value))
+}
+
+// SetBool sets the boolean value of the environment variable named
+// by the key as "true" or "false".
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
+func SetBool(key string, value bool) error {
+	return setTyped(key, reflect.ValueOf(value))
+}
+
+// SetDuration sets the time.Duration value of the environment variable
+// named by the key. Like Marshal, the value is saved as the number of
+// nanoseconds, so it can be read back by Unmarshal into time.Duration.
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
+func SetDuration(key string, value time.Duration) error {
+	return setTyped(key, reflect.ValueOf(value))
+}
+
+// SetSlice sets the slice or array as value of the environment variable
+// named by the key. The items are joined by the sep separator, if sep is
+// empty the default separator is used (the same as for the sep tag).
+//
+// Returns an error if value isn't a slice or array of supported type,
+// and ErrFrozen if the environment is frozen by Freeze.
+//
+// # Examples
+//
+//	env.SetSlice("PORTS", []int{80, 443}, ",") // PORTS=80,443
+//	env.SetSlice("HOSTS", []string{"a", "b"}, "") // HOSTS=a b
+func SetSlice(key string, value interface{}, sep string) error {
+	if sep == "" {
+		sep = defValueSep
+	}
+
+	item := reflect.ValueOf(value)
+	if item.Kind() == reflect.Ptr {
+		item = item.Elem()
+	}
+
+	str, err := getSequence(&item, sep)
+	if err != nil {
+		return err
+	}
+
+	return setenv(key, str)
+}
+
+// The setTyped formats the item as Marshal does and sets it
+// into environment.
+func setTyped(key string, item reflect.Value) error {
+	str, err := toStr(item)
+	if err != nil {
+		return err
+	}
+
+	return setenv(key, str)
+}
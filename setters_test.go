@@ -0,0 +1,83 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestSetTyped tests SetInt, SetBool, SetDuration and SetSlice functions.
+func TestSetTyped(t *testing.T) {
+	type config struct {
+		Port    int           `env:"PORT"`
+		Debug   bool          `env:"DEBUG"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Hosts   []string      `env:"HOSTS" sep:","`
+		Codes   [2]int        `env:"CODES"`
+	}
+
+	os.Clearenv()
+	if err := SetInt("PORT", 8080); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetBool("DEBUG", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetDuration("TIMEOUT", 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetSlice("HOSTS", []string{"a", "b"}, ","); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetSlice("CODES", &[2]int{200, 404}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Compare with values that Marshal creates.
+	set := Environ()
+	os.Clearenv()
+
+	expected := config{8080, true, 3 * time.Second, []string{"a", "b"},
+		[2]int{200, 404}}
+	marshaled, err := marshalEnv("", expected, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pair := range marshaled {
+		found := false
+		for _, s := range set {
+			found = found || s == pair
+		}
+
+		if !found {
+			t.Errorf("expected `%s` but not found in %v", pair, set)
+		}
+	}
+
+	// Round-trip through Unmarshal.
+	for _, pair := range set {
+		key, value, _ := parseExpression(pair)
+		os.Setenv(key, value)
+	}
+
+	var c config
+	if err := Unmarshal("", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Port != expected.Port || c.Debug != expected.Debug ||
+		c.Timeout != expected.Timeout || c.Codes != expected.Codes ||
+		len(c.Hosts) != 2 || c.Hosts[1] != "b" {
+		t.Errorf("expected `%v` but `%v`", expected, c)
+	}
+
+	// Unsupported type.
+	if err := SetSlice("MAP", map[string]int{}, ""); err == nil {
+		t.Error("an error is expected for map value")
+	}
+}
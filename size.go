@@ -0,0 +1,103 @@
+package env
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The limits of the environment of the new process.
+const (
+	// The execArgMax is the common limit of the total size of the
+	// arguments and the environment on Unix (ARG_MAX), 2 MiB.
+	execArgMax = 2 << 20
+
+	// The execStrMax is the limit of the size of one KEY=VALUE
+	// item on Linux (MAX_ARG_STRLEN), 128 KiB.
+	execStrMax = 128 << 10
+
+	// The windowsMaxBlock is the limit of the size
+	// of the environment block on Windows, in characters.
+	windowsMaxBlock = 32767
+)
+
+// SizeOf returns the size in bytes of the environment block of the keys
+// with the prefix (all keys for the empty prefix), as it's passed to the
+// new process: each key is counted as the KEY=VALUE string with the
+// terminating zero byte.
+//
+// # Examples
+//
+//	fmt.Printf("the APP_ keys take %d bytes\n", env.SizeOf("APP_"))
+func SizeOf(prefix string) int {
+	size := 0
+	for _, item := range environ() {
+		if strings.HasPrefix(item, prefix) {
+			size += len(item) + 1
+		}
+	}
+
+	return size
+}
+
+// WouldExceedExecLimit returns true if the environment with the extra
+// keys (they override the existing ones) is likely too big to start the
+// new process, which fails with the E2BIG error (argument list too long)
+// on Unix. The estimation takes the limits of the current platform into
+// account:
+//
+//   - Unix - the total size of the items with the terminating zero bytes
+//     and the pointers to them is limited to 2 MiB (the common ARG_MAX),
+//     and the size of one item is limited to 128 KiB on Linux;
+//   - Windows - the size of the environment block is limited to 32767
+//     characters.
+//
+// The arguments of the command share the limit with the environment on
+// Unix, so the real room is smaller, and the ARG_MAX of the system can
+// be bigger, depending on the stack size limit.
+//
+// # Examples
+//
+//	extra := map[string]string{"PAYLOAD": payload}
+//	if env.WouldExceedExecLimit(extra) {
+//		return errors.New("pass the payload through the file")
+//	}
+func WouldExceedExecLimit(extra map[string]string) bool {
+	items := environMap(environ())
+	for key, value := range extra {
+		items[key] = value
+	}
+
+	size := 0
+	for key, value := range items {
+		item := len(key) + len(value) + 2 // = and zero byte
+		if runtime.GOOS == "linux" && item > execStrMax {
+			return true
+		}
+
+		if runtime.GOOS == "windows" {
+			size += utf16Len(key) + utf16Len(value) + 2
+		} else {
+			size += item + strconv.IntSize/8 // and the pointer
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return size+1 > windowsMaxBlock // with the final zero
+	}
+
+	return size > execArgMax
+}
+
+// The utf16Len returns the length of the string in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n++ // surrogate pair
+		}
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestSizeOf tests SizeOf function.
+func TestSizeOf(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_HOST", "localhost") // 19 bytes
+	os.Setenv("APP_PORT", "80")        // 12 bytes
+	os.Setenv("USER", "goloop")        // 12 bytes
+
+	if v := SizeOf("APP_"); v != 31 {
+		t.Errorf("expected 31 but %d", v)
+	}
+
+	if v := SizeOf(""); v != 43 {
+		t.Errorf("expected 43 but %d", v)
+	}
+
+	if v := SizeOf("DB_"); v != 0 {
+		t.Errorf("expected 0 but %d", v)
+	}
+}
+
+// TestWouldExceedExecLimit tests WouldExceedExecLimit function.
+func TestWouldExceedExecLimit(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("USER", "goloop")
+
+	if WouldExceedExecLimit(nil) {
+		t.Error("expected false for the small environment")
+	}
+
+	extra := map[string]string{"PAYLOAD": strings.Repeat("a", 4<<20)}
+	if !WouldExceedExecLimit(extra) {
+		t.Error("expected true for the large value")
+	}
+
+	// The extra keys override the existing ones.
+	os.Setenv("PAYLOAD", strings.Repeat("a", 4<<20))
+	if WouldExceedExecLimit(map[string]string{"PAYLOAD": "small"}) {
+		t.Error("expected false for the overridden value")
+	}
+}
+
+// TestUTF16Len tests utf16Len function.
+func TestUTF16Len(t *testing.T) {
+	if v := utf16Len("aé€😀"); v != 5 {
+		t.Errorf("expected 5 but %d", v)
+	}
+}
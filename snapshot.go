@@ -0,0 +1,64 @@
+package env
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// The snapshotMode is true if the Unmarshal function reads the
+// environment once into the snapshot (see SnapshotMode).
+var snapshotMode atomic.Bool
+
+// SnapshotMode sets whether the Unmarshal function (and the functions based
+// on it, like UnmarshalAll and Reloader) reads the environment only once:
+// the keys with the prefix are copied into the immutable snapshot before
+// the unmarshaling and all fields are resolved from it. This is faster for
+// large structures (one pass instead of a lookup for each field) and makes
+// the result consistent when another goroutine changes the environment
+// during the unmarshaling. The custom UnmarshalEnv methods still read the
+// environment by themselves. Returns the previous mode.
+//
+// The snapshot mode is disabled by default.
+//
+// # Examples
+//
+//	env.SnapshotMode(true)
+//
+//	var config Config
+//	if err := env.Unmarshal("APP_", &config); err != nil {
+//		log.Fatal(err)
+//	}
+func SnapshotMode(enabled bool) bool {
+	return snapshotMode.Swap(enabled)
+}
+
+// The snapshotLookup copies the keys with the prefix from the environment
+// and returns the lookupFunc that takes the values from the copy and the
+// keysFunc that returns the keys of the copy.
+func snapshotLookup(prefix string) (lookupFunc, keysFunc) {
+	snapshot := make(map[string]string)
+	for _, item := range environ() {
+		if key, value, ok := strings.Cut(item, "="); ok &&
+			strings.HasPrefix(key, prefix) {
+			snapshot[key] = value
+		}
+	}
+
+	lookup := func(key string) (string, bool, error) {
+		value, ok := snapshot[key]
+		if ok {
+			traceKey(key)
+		}
+		return value, ok, nil
+	}
+
+	keys := func() ([]string, error) {
+		list := make([]string, 0, len(snapshot))
+		for key := range snapshot {
+			list = append(list, key)
+		}
+		return list, nil
+	}
+
+	return lookup, keys
+}
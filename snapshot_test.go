@@ -0,0 +1,63 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+// snapshotConfig is the structure whose UnmarshalEnv changes
+// the environment during the unmarshaling.
+type snapshotConfig struct {
+	Host  string `env:"HOST"`
+	Inner snapshotInner
+	Port  int                      `env:"PORT"`
+	DB    map[string]snapshotInner `env:"DB"`
+}
+
+// snapshotInner changes the PORT key and adds the DB_NEW
+// entry when it is unmarshaled.
+type snapshotInner struct {
+	Name string `env:"NAME"`
+}
+
+// UnmarshalEnv changes the environment.
+func (s *snapshotInner) UnmarshalEnv() error {
+	Set("APP_DB_NEW_NAME", "new")
+	return Set("APP_PORT", "9090")
+}
+
+// TestSnapshotMode tests that the values are taken from the snapshot.
+func TestSnapshotMode(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		prev := SnapshotMode(enabled)
+		os.Clearenv()
+		Set("APP_HOST", "localhost")
+		Set("APP_PORT", "8080")
+		Set("APP_DB_MAIN_NAME", "main")
+
+		var data snapshotConfig
+		if err := Unmarshal("APP_", &data); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, entries := 9090, 2
+		if enabled {
+			expected, entries = 8080, 1 // the changes aren't seen
+		}
+
+		if data.Host != "localhost" || data.Port != expected {
+			t.Errorf("expected `localhost:%d` but `%s:%d`",
+				expected, data.Host, data.Port)
+		}
+
+		if len(data.DB) != entries {
+			t.Errorf("expected %d entries but `%v`", entries, data.DB)
+		}
+
+		SnapshotMode(prev)
+	}
+
+	if SnapshotMode(false) {
+		t.Error("expected the snapshot mode disabled by default")
+	}
+}
@@ -0,0 +1,323 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Source is the interface implemented by the stores of configuration
+// values: the process environment, env-files, remote services, etc.
+type Source interface {
+	// Lookup returns the value of the key. The boolean is false if the
+	// key isn't found. An error is returned if the source can't be
+	// reached or the request is canceled by the context.
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+// KeyLister is the optional interface of the Source that lists its keys.
+// The entries of the maps of structures (like DB_MAIN_HOST for the
+// map[string]DB field with the DB key) are found by the keys of the
+// source, so they aren't unmarshaled from the sources without it.
+type KeyLister interface {
+	// Keys returns the keys of the source. An error is returned if the
+	// source can't be reached or the request is canceled by the context.
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// The sourceKeys returns the keysFunc that lists the keys of the sources
+// that implement the KeyLister interface, nil if there are none.
+func sourceKeys(ctx context.Context, sources ...Source) keysFunc {
+	var listers []KeyLister
+	for _, src := range sources {
+		if lister, ok := src.(KeyLister); ok {
+			listers = append(listers, lister)
+		}
+	}
+
+	if len(listers) == 0 {
+		return nil
+	}
+
+	return func() ([]string, error) {
+		var keys []string
+		for _, lister := range listers {
+			list, err := lister.Keys(ctx)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, list...)
+		}
+		return keys, nil
+	}
+}
+
+// SourceFunc is an adapter to allow the use of
+// ordinary functions as a Source.
+type SourceFunc func(ctx context.Context, key string) (string, bool, error)
+
+// Lookup calls f(ctx, key).
+func (f SourceFunc) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	return f(ctx, key)
+}
+
+// The envSource is the Source of the process environment.
+type envSource struct{}
+
+// EnvSource returns the Source of the process environment,
+// it implements the KeyLister interface.
+func EnvSource() Source {
+	return envSource{}
+}
+
+// Lookup returns the value of the key from the environment.
+func (envSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	value, ok := getenv(key)
+	return value, ok, nil
+}
+
+// Keys returns the keys of the environment.
+func (envSource) Keys(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return environKeys()
+}
+
+// The fileSource is the Source of the env-file.
+type fileSource struct {
+	filename string
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// FileSource returns the Source of the env-file. The file is read and
+// parsed on the first lookup, the variables like ${var} or $var are
+// expanded by the values from the file itself and from the environment.
+// The environment isn't changed.
+//
+// If the file can't be read, the error is returned and the
+// file will be read again on the next lookup. The source implements
+// the KeyLister interface.
+func FileSource(filename string) Source {
+	return &fileSource{filename: filename}
+}
+
+// Lookup returns the value of the key from the env-file.
+func (s *fileSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", false, err
+	}
+
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+// Keys returns the keys of the env-file.
+func (s *fileSource) Keys(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// The load reads and parses the file if it isn't loaded yet,
+// the lock must be held.
+func (s *fileSource) load() error {
+	if s.values != nil {
+		return nil
+	}
+
+	pairs, _, err := readParse(s.filename, true, false)
+	if err != nil {
+		return err
+	}
+
+	values, err := expandPairs(pairs)
+	if err != nil {
+		return err
+	}
+	s.values = values
+
+	return nil
+}
+
+// The expandPairs converts the pairs to the map, expanding variables
+// in the values by the previous pairs and by the environment. Returns
+// an error for the ${VAR:?message} of the undefined variable.
+func expandPairs(pairs []pair) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	mapping := func(key string) (string, bool) {
+		if value, ok := values[key]; ok {
+			return value, true
+		}
+		return getenv(key)
+	}
+
+	for _, p := range pairs {
+		if p.expanded {
+			value, err := expandValue(p.value, mapping)
+			if err != nil {
+				return nil, &KeyError{Key: p.key, Err: err}
+			}
+			p.value = value
+		}
+		values[p.key] = p.value
+	}
+
+	return values, nil
+}
+
+// UnmarshalSource works like Unmarshal but takes the values from
+// the source instead of the environment. The environment isn't
+// changed. The context is passed to each lookup of the source. The
+// entries of the maps of structures are found by the keys of the
+// source, if it implements the KeyLister interface.
+//
+// # Examples
+//
+//	src := env.WithRetry(env.FileSource("/mnt/nfs/.env"), env.RetryPolicy{
+//		Attempts: 3,
+//		Backoff:  time.Second,
+//	})
+//
+//	var cfg Config
+//	if err := env.UnmarshalSource(ctx, src, "", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func UnmarshalSource(ctx context.Context, src Source, prefix string,
+	obj interface{}) error {
+	lookup := func(key string) (string, bool, error) {
+		return src.Lookup(ctx, key)
+	}
+
+	return unmarshalWith(lookup, sourceKeys(ctx, src), prefix, obj)
+}
+
+// NamedSource is the Source with the name
+// used in the source tags (see UnmarshalSources).
+type NamedSource struct {
+	Name   string
+	Source Source
+}
+
+// UnmarshalSources works like UnmarshalSource but takes the values from
+// several sources. The value of the field is taken from the first source
+// (in the order of the list) that provides its key. The field with the
+// source tag takes the value only from the source with this name, like
+// the secrets from the vault and the tunables from the environment.
+//
+// If the pinned source doesn't provide the key (and its fallback names)
+// and the field has no default value, the error is returned with the
+// *KeyError wrapping ErrNotInSource. The source tag of the nested
+// structure is ignored, the tags of its fields are used.
+//
+// Returns an error if the source tag refers to the unknown source.
+//
+// # Examples
+//
+//	type Config struct {
+//		Workers  int    `env:"WORKERS" def:"4"`
+//		Password string `env:"DB_PASSWORD" source:"vault"`
+//	}
+//
+//	err := env.UnmarshalSources(ctx, []env.NamedSource{
+//		{Name: "env", Source: env.EnvSource()},
+//		{Name: "vault", Source: vaultSource},
+//	}, "APP_", &config)
+func UnmarshalSources(ctx context.Context, sources []NamedSource,
+	prefix string, obj interface{}) error {
+	byName := make(map[string]Source, len(sources))
+	for _, s := range sources {
+		byName[s.Name] = s.Source
+	}
+
+	// The pinned keys (including the fallback names) by the sources.
+	type pin struct {
+		source string
+		last   bool // the last name of the key to try
+	}
+
+	pins := make(map[string]pin)
+	err := walkFields(prefix, reflect.TypeOf(obj),
+		func(fi fieldInfo) error {
+			if fi.tg.source == "" {
+				return nil
+			}
+
+			if _, ok := byName[fi.tg.source]; !ok {
+				return fmt.Errorf("the %s field refers to the unknown "+
+					"source: %s", fi.name, fi.tg.source)
+			}
+
+			names := append([]string{fi.tg.key}, fi.tg.alias...)
+			for i, name := range names {
+				// The field with the default value can miss the key.
+				last := i == len(names)-1 && fi.tg.value == ""
+				pins[name] = pin{source: fi.tg.source, last: last}
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	lookup := func(key string) (string, bool, error) {
+		if p, ok := pins[key]; ok {
+			value, ok, err := byName[p.source].Lookup(ctx, key)
+			if err == nil && !ok && p.last {
+				err = &KeyError{
+					Key: key,
+					Err: fmt.Errorf("%w: %s", ErrNotInSource, p.source),
+				}
+			}
+			return value, ok, err
+		}
+
+		for _, s := range sources {
+			value, ok, err := s.Source.Lookup(ctx, key)
+			if err != nil || ok {
+				return value, ok, err
+			}
+		}
+
+		return "", false, nil
+	}
+
+	list := make([]Source, len(sources))
+	for i, s := range sources {
+		list[i] = s.Source
+	}
+
+	return unmarshalWith(lookup, sourceKeys(ctx, list...), prefix, obj)
+}
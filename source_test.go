@@ -0,0 +1,177 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestUnmarshalSource tests UnmarshalSource function.
+func TestUnmarshalSource(t *testing.T) {
+	type config struct {
+		Key0 string `env:"KEY_0"`
+		Key2 string `env:"KEY_2"`
+		Key4 string `env:"KEY_4"`
+	}
+
+	os.Clearenv()
+	ctx := context.Background()
+
+	// From env-file, the environment isn't changed.
+	var cfg config
+	src := FileSource("./fixtures/variables.env")
+	if err := UnmarshalSource(ctx, src, "", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Key0 != "value_0" || cfg.Key2 != "value_001" ||
+		cfg.Key4 != "value_0value_001" {
+		t.Errorf("incorrect values: %v", cfg)
+	}
+
+	if len(os.Environ()) != 0 {
+		t.Error("the environment has been changed")
+	}
+
+	// From environment.
+	os.Setenv("KEY_0", "env")
+	if err := UnmarshalSource(ctx, EnvSource(), "", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Key0 != "env" {
+		t.Errorf("expected `env` but `%s`", cfg.Key0)
+	}
+
+	// Missing file.
+	src = FileSource("./fixtures/nonexist.env")
+	if err := UnmarshalSource(ctx, src, "", &cfg); err == nil {
+		t.Error("an error is expected for missing file")
+	}
+}
+
+// TestUnmarshalSources tests UnmarshalSources function.
+func TestUnmarshalSources(t *testing.T) {
+	mapSource := func(values map[string]string) Source {
+		return SourceFunc(func(ctx context.Context,
+			key string) (string, bool, error) {
+			value, ok := values[key]
+			return value, ok, nil
+		})
+	}
+
+	type db struct {
+		User     string `env:"USER"`
+		Password string `env:"PASSWORD|PASS" source:"vault"`
+	}
+
+	type config struct {
+		Workers int    `env:"WORKERS" def:"4"`
+		Token   string `env:"TOKEN" source:"vault" def:"none"`
+		DB      db     `env:"DB"`
+	}
+
+	ctx := context.Background()
+	sources := []NamedSource{
+		{"env", mapSource(map[string]string{
+			"APP_WORKERS": "8", "APP_DB_USER": "admin",
+			"APP_DB_PASSWORD": "env", "APP_TOKEN": "env",
+		})},
+		{"vault", mapSource(map[string]string{
+			"APP_DB_PASS": "vault", "APP_WORKERS": "16",
+		})},
+	}
+
+	var data config
+	if err := UnmarshalSources(ctx, sources, "APP_", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{8, "none", db{"admin", "vault"}}
+	if data != expected {
+		t.Errorf("expected `%v` but `%v`", expected, data)
+	}
+
+	// The pinned source doesn't provide the key.
+	sources[1].Source = mapSource(map[string]string{})
+	err := UnmarshalSources(ctx, sources, "APP_", &data)
+	var keyErr *KeyError
+	if !errors.Is(err, ErrNotInSource) || !errors.As(err, &keyErr) ||
+		keyErr.Key != "APP_DB_PASS" {
+		t.Errorf("expected ErrNotInSource for APP_DB_PASS but `%v`", err)
+	}
+
+	// The unknown source.
+	if err := UnmarshalSources(ctx, sources[:1], "APP_", &data); err == nil {
+		t.Error("expected an error for the unknown source")
+	}
+}
+
+// TestUnmarshalSourceStructMap tests the maps of
+// structures from the sources that list the keys.
+func TestUnmarshalSourceStructMap(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	type config struct {
+		Databases map[string]db `env:"DB"`
+	}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := "DB_MAIN_HOST=db1\nDB_MAIN_PORT=5432\nDB_LOGS_HOST=db2\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	ctx := context.Background()
+	src := Cached(WithRetry(FileSource(filename), RetryPolicy{}), time.Minute)
+
+	var cfg config
+	if err := UnmarshalSource(ctx, src, "", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]db{
+		"MAIN": {Host: "db1", Port: 5432},
+		"LOGS": {Host: "db2"},
+	}
+	if !reflect.DeepEqual(cfg.Databases, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, cfg.Databases)
+	}
+
+	// The entries of the environment aren't taken.
+	os.Setenv("DB_ENV_HOST", "db3")
+	cfg = config{}
+	err := UnmarshalSources(ctx, []NamedSource{
+		{Name: "file", Source: FileSource(filename)},
+	}, "", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cfg.Databases, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, cfg.Databases)
+	}
+
+	// The source that doesn't list the keys.
+	lookup := SourceFunc(func(ctx context.Context,
+		key string) (string, bool, error) {
+		return src.Lookup(ctx, key)
+	})
+
+	cfg = config{}
+	if err := UnmarshalSource(ctx, lookup, "", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Databases != nil {
+		t.Errorf("expected nil map but `%v`", cfg.Databases)
+	}
+}
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LoadStats describes the last loading of the env-file
+// by the Load, LoadSafe, Update or UpdateSafe functions.
+type LoadStats struct {
+	File       string        // path to the env-file
+	Lines      int           // number of lines read, including comments
+	Keys       int           // number of parsed expressions
+	Expansions int           // number of values with expanded variables
+	Duration   time.Duration // time of reading, parsing and applying
+}
+
+var (
+	// The lastLoadStats contains the stats of the last successful load.
+	lastLoadStats atomic.Pointer[LoadStats]
+
+	// The loadHook is the function called after each successful load.
+	loadHook atomic.Pointer[func(LoadStats)]
+)
+
+// LastLoadStats returns the stats of the last successful loading of the
+// env-file. The zero value is returned if no file has been loaded yet.
+//
+// The parsing goroutines are also labeled with the env.file and env.phase
+// pprof labels (the phase is parse, apply or unmarshal), so the time
+// spent on loading can be found in the profiles.
+//
+// # Examples
+//
+//	if err := env.Load(".env"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	stats := env.LastLoadStats()
+//	log.Printf("%s: %d keys in %s", stats.File, stats.Keys, stats.Duration)
+func LastLoadStats() LoadStats {
+	if stats := lastLoadStats.Load(); stats != nil {
+		return *stats
+	}
+
+	return LoadStats{}
+}
+
+// OnLoad sets the function called with the stats after each successful
+// loading of the env-file, for example, to export them as metrics.
+// The nil function removes the previous one.
+//
+// # Examples
+//
+//	env.OnLoad(func(stats env.LoadStats) {
+//		loadDuration.Observe(stats.Duration.Seconds())
+//	})
+func OnLoad(fn func(LoadStats)) {
+	if fn == nil {
+		loadHook.Store(nil)
+		return
+	}
+
+	loadHook.Store(&fn)
+}
+
+// The storeLoadStats saves the stats and calls the load hook.
+func storeLoadStats(stats LoadStats) {
+	lastLoadStats.Store(&stats)
+	if fn := loadHook.Load(); fn != nil {
+		(*fn)(stats)
+	}
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+// TestLastLoadStats tests the stats of the loaded env-file.
+func TestLastLoadStats(t *testing.T) {
+	var calls []LoadStats
+	OnLoad(func(stats LoadStats) { calls = append(calls, stats) })
+	defer OnLoad(nil)
+
+	os.Clearenv()
+	if err := Load("./fixtures/variables.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := LastLoadStats()
+	if stats.File != "./fixtures/variables.env" {
+		t.Errorf("expected `%s` but `%s`", "./fixtures/variables.env",
+			stats.File)
+	}
+
+	if stats.Lines != 5 || stats.Keys != 5 || stats.Expansions != 3 {
+		t.Errorf("expected 5 lines, 5 keys, 3 expansions but %+v", stats)
+	}
+
+	if stats.Duration <= 0 {
+		t.Errorf("expected positive duration but %s", stats.Duration)
+	}
+
+	if len(calls) != 1 || calls[0] != stats {
+		t.Errorf("expected one call of the hook but %v", calls)
+	}
+}
+
+// TestLastLoadStatsError tests that the stats aren't
+// changed if the env-file can't be loaded.
+func TestLastLoadStatsError(t *testing.T) {
+	os.Clearenv()
+	if err := Load("./fixtures/simple.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := LastLoadStats()
+	if err := Load("./fixtures/nonexist.env"); err == nil {
+		t.Error("expected an error")
+	}
+
+	if stats := LastLoadStats(); stats != expected {
+		t.Errorf("expected `%v` but `%v`", expected, stats)
+	}
+}
@@ -0,0 +1,117 @@
+package env
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// The frozen is true if the environment is frozen by the Freeze function
+// and can't be changed through this package.
+var frozen atomic.Bool
+
+// Freeze forbids changing the environment through this package: after
+// the call, Set, Unset, Load, Update, Marshal and other functions that
+// change the environment return ErrFrozen, and Clear does nothing.
+// It guarantees the immutability of the configuration after startup.
+//
+// The freezing can't be undone. Note that the environment can still
+// be changed directly by the os package.
+//
+// # Examples
+//
+//	if err := env.Load(".env"); err != nil {
+//		log.Fatal(err)
+//	}
+//	env.Freeze()
+//
+//	err := env.Set("HOST", "0.0.0.0") // ErrFrozen
+func Freeze() {
+	frozen.Store(true)
+}
+
+// IsFrozen returns true if the environment is frozen by Freeze.
+func IsFrozen() bool {
+	return frozen.Load()
+}
+
+// The setenv sets the value of the environment variable.
+// All changes of the environment by the package go through it.
+func setenv(key, value string) error {
+	if frozen.Load() {
+		return ErrFrozen
+	}
+
+	value, err := limitValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	record := auditSet(key, value)
+	if err := overlay.setenv(key, value); err != nil {
+		return err
+	}
+
+	clearExpiry(key)
+	clearTaint(key)
+	guardWrite(key, value, true)
+	record()
+	notify(key)
+	return nil
+}
+
+// The unsetenv unsets the environment variable.
+// All deletions of the environment by the package go through it.
+func unsetenv(key string) error {
+	if frozen.Load() {
+		return ErrFrozen
+	}
+
+	record := auditUnset(key)
+	if err := overlay.unsetenv(key); err != nil {
+		return err
+	}
+
+	clearExpiry(key)
+	clearTaint(key)
+	guardWrite(key, "", false)
+	record()
+	notify(key)
+	return nil
+}
+
+// The clearenv deletes all environment variables.
+func clearenv() error {
+	if frozen.Load() {
+		return ErrFrozen
+	}
+
+	if guardEnabled.Load() {
+		for _, item := range environ() {
+			key, _, _ := strings.Cut(item, "=")
+			guardWrite(key, "", false)
+		}
+	}
+
+	overlay.clearenv()
+
+	clearExpiry()
+	clearTaint()
+	if auditEnabled.Load() {
+		audit("clear", "", "", false, "", false)
+	}
+
+	notify()
+	return nil
+}
+
+// The lookupenv retrieves the value of the environment variable.
+// The reads of the keys by Get, Lookup, Unmarshal and the typed
+// getters go through it to be traced (see TraceUsage).
+func lookupenv(key string) (string, bool) {
+	value, ok := getenv(key)
+	if ok {
+		traceKey(key)
+	}
+
+	return value, ok
+}
@@ -0,0 +1,53 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestFreeze tests Freeze function.
+func TestFreeze(t *testing.T) {
+	defer frozen.Store(false)
+
+	os.Clearenv()
+	os.Setenv("KEY_0", "value")
+	Freeze()
+
+	if !IsFrozen() {
+		t.Error("the environment should be frozen")
+	}
+
+	if err := Set("KEY_1", "value"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	if err := Unset("KEY_0"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	if err := Load("./fixtures/simple.env"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	if err := Update("./fixtures/simple.env"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	data := struct {
+		Host string `env:"HOST"`
+	}{Host: "localhost"}
+	if _, err := Marshal("", data); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected ErrFrozen but %v", err)
+	}
+
+	Clear()
+	if v := os.Getenv("KEY_0"); v != "value" {
+		t.Errorf("the environment has been changed: `%s`", v)
+	}
+
+	// Reading is allowed.
+	if err := Unmarshal("", &data); err != nil {
+		t.Error(err)
+	}
+}
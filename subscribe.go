@@ -0,0 +1,188 @@
+package env
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// The subscription is the callback subscribed to the changes of the keys.
+type subscription struct {
+	id uint64
+	fn func(key, old, new string)
+}
+
+// The keySubscriptions contains the callbacks of the key
+// and the last value the callbacks were notified about.
+type keySubscriptions struct {
+	last string
+	list []subscription
+}
+
+var (
+	// The subCount is the number of the subscriptions,
+	// so the changes aren't checked if there are none.
+	subCount atomic.Int64
+
+	// The subMu protects the subs and subID.
+	subMu sync.Mutex
+
+	// The subs contains the subscriptions by the keys.
+	subs = map[string]*keySubscriptions{}
+
+	// The subID is the identifier of the last subscription.
+	subID uint64
+)
+
+// Subscribe calls fn with the old and new values of the key each time the
+// key changes, so the components can react to the changes of individual
+// variables without unmarshaling the whole configuration. Returns the
+// function that cancels the subscription.
+//
+// The changes made through this package (Set, Unset, Clear, Load, Update,
+// Marshal etc.) are reported immediately. The changes made by other means
+// (like os.Setenv) are reported when they are found by the Reload method
+// of a Reloader that watches the key. The unset key has the empty value,
+// so setting the empty value to the unset key isn't a change. The fn is
+// called synchronously in the goroutine that found the change, after the
+// environment is changed.
+//
+// # Examples
+//
+//	cancel := env.Subscribe("LOG_LEVEL", func(old, new string) {
+//		log.Printf("log level: %s -> %s", old, new)
+//		logger.SetLevel(new)
+//	})
+//	defer cancel()
+func Subscribe(key string, fn func(old, new string)) (cancel func()) {
+	return SubscribeKeys([]string{key}, func(_, old, new string) {
+		fn(old, new)
+	})
+}
+
+// SubscribeKeys works like Subscribe, but subscribes fn to the changes
+// of several keys at once, the fn gets the name of the changed key. It's
+// useful to react to the rotation of credentials or feature flags that
+// consist of several keys. Returns the function that cancels the
+// subscriptions of all keys.
+//
+// # Examples
+//
+//	keys := []string{"DB_USER", "DB_PASSWORD"}
+//	cancel := env.SubscribeKeys(keys, func(key, old, new string) {
+//		log.Printf("%s is rotated", key)
+//		pool.Reconnect()
+//	})
+//	defer cancel()
+func SubscribeKeys(keys []string,
+	fn func(key, old, new string)) (cancel func()) {
+	subMu.Lock()
+	defer subMu.Unlock()
+
+	subID++
+	id := subID
+
+	// The same subscription is added to each key once.
+	subscribed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ks, ok := subs[key]
+		if !ok {
+			ks = &keySubscriptions{last: getenvValue(key)}
+			subs[key] = ks
+		} else if ks.has(id) {
+			continue
+		}
+
+		ks.list = append(ks.list, subscription{id: id, fn: fn})
+		subscribed = append(subscribed, key)
+		subCount.Add(1)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { unsubscribe(subscribed, id) })
+	}
+}
+
+// The has returns true if the key has the subscription by the identifier.
+func (ks *keySubscriptions) has(id uint64) bool {
+	for _, s := range ks.list {
+		if s.id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The unsubscribe removes the subscription of the keys by the identifier.
+func unsubscribe(keys []string, id uint64) {
+	subMu.Lock()
+	defer subMu.Unlock()
+
+	for _, key := range keys {
+		ks, ok := subs[key]
+		if !ok {
+			continue
+		}
+
+		for i, s := range ks.list {
+			if s.id == id {
+				ks.list = append(ks.list[:i:i], ks.list[i+1:]...)
+				subCount.Add(-1)
+				break
+			}
+		}
+
+		if len(ks.list) == 0 {
+			delete(subs, key)
+		}
+	}
+}
+
+// The notify compares the current values of the keys with the values
+// the subscribers were notified about last time, and calls the
+// subscribers of the changed keys. If no keys are given,
+// all subscribed keys are checked.
+func notify(keys ...string) {
+	if subCount.Load() == 0 {
+		return
+	}
+
+	type call struct {
+		fn            func(key, old, new string)
+		key, old, new string
+	}
+
+	var calls []call
+	subMu.Lock()
+	check := func(key string, ks *keySubscriptions) {
+		value := getenvValue(key)
+		if value == ks.last {
+			return
+		}
+
+		for _, s := range ks.list {
+			calls = append(calls, call{s.fn, key, ks.last, value})
+		}
+		ks.last = value
+	}
+
+	if len(keys) == 0 {
+		for key, ks := range subs {
+			check(key, ks)
+		}
+	}
+
+	for _, key := range keys {
+		if ks, ok := subs[key]; ok {
+			check(key, ks)
+		}
+	}
+	subMu.Unlock()
+
+	// The callbacks are called without the lock,
+	// so they can subscribe or change the environment.
+	for _, c := range calls {
+		c.fn(c.key, c.old, c.new)
+	}
+}
@@ -0,0 +1,142 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestSubscribe tests Subscribe function.
+func TestSubscribe(t *testing.T) {
+	type change struct{ old, new string }
+
+	var changes []change
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+
+	cancel := Subscribe("HOST", func(old, new string) {
+		changes = append(changes, change{old, new})
+	})
+
+	// Changes made through the package.
+	Set("HOST", "0.0.0.0")
+	Set("HOST", "0.0.0.0") // isn't a change
+	Set("PORT", "80")      // another key
+	Unset("HOST")
+
+	expected := []change{{"localhost", "0.0.0.0"}, {"0.0.0.0", ""}}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %v but %v", expected, changes)
+	}
+
+	for i, c := range changes {
+		if c != expected[i] {
+			t.Errorf("expected `%v` but `%v`", expected[i], c)
+		}
+	}
+
+	// Clear.
+	changes = nil
+	Set("HOST", "127.0.0.1")
+	Clear()
+	if len(changes) != 2 || changes[1] != (change{"127.0.0.1", ""}) {
+		t.Errorf("expected change on clear but %v", changes)
+	}
+
+	// Canceled subscription.
+	changes = nil
+	cancel()
+	cancel() // can be called twice
+	Set("HOST", "localhost")
+	if len(changes) != 0 {
+		t.Errorf("expected no changes but %v", changes)
+	}
+}
+
+// TestSubscribeReloader tests Subscribe function with Reloader.
+func TestSubscribeReloader(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	var (
+		cfg     config
+		changes []string
+	)
+
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel := Subscribe("HOST", func(old, new string) {
+		changes = append(changes, old+"->"+new)
+	})
+	defer cancel()
+
+	// Changed by other means, found by reloading.
+	os.Setenv("HOST", "0.0.0.0")
+	os.Setenv("PORT", "80")
+	if len(changes) != 0 {
+		t.Errorf("expected no changes before reload but %v", changes)
+	}
+
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0] != "localhost->0.0.0.0" {
+		t.Errorf("expected `localhost->0.0.0.0` but `%v`", changes)
+	}
+
+	// The change made through the package isn't reported twice.
+	Set("HOST", "127.0.0.1")
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 2 || changes[1] != "0.0.0.0->127.0.0.1" {
+		t.Errorf("expected one more change but `%v`", changes)
+	}
+}
+
+// TestSubscribeKeys tests SubscribeKeys function.
+func TestSubscribeKeys(t *testing.T) {
+	var changes []string
+	os.Clearenv()
+	os.Setenv("DB_USER", "admin")
+
+	keys := []string{"DB_USER", "DB_PASSWORD", "DB_USER"} // called once
+	cancel := SubscribeKeys(keys, func(key, old, new string) {
+		changes = append(changes, key+":"+old+"->"+new)
+	})
+
+	// Changes made by Set and Update.
+	Set("DB_USER", "root")
+	Set("HOST", "localhost") // isn't subscribed
+	err := LoadBytes([]byte("DB_USER=root\nDB_PASSWORD=secret\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[DB_USER:admin->root DB_PASSWORD:->secret]"
+	if v := fmt.Sprint(changes); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// Canceled subscriptions.
+	changes = nil
+	cancel()
+	Set("DB_USER", "admin")
+	Set("DB_PASSWORD", "")
+	if len(changes) != 0 {
+		t.Errorf("expected no changes but %v", changes)
+	}
+
+	if n := subCount.Load(); n != 0 {
+		t.Errorf("expected no subscriptions but %d", n)
+	}
+}
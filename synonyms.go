@@ -1,43 +1,53 @@
 package env
 
-import "os"
-
 // Get is synonym for the os.Getenv, retrieves the value of the environment
 // variable named by the key. It returns the value, which will be empty if
 // the variable is not present.
 //
 // To distinguish between an empty value and an unset value, use Lookup.
 func Get(key string) string {
-	return os.Getenv(key)
+	value, _ := lookupenv(key)
+	return value
 }
 
 // Set is synonym for the os.Setenv, sets the value of the environment
 // variable named by the key. It returns an error, if any.
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
 func Set(key, value string) error {
-	return os.Setenv(key, value)
+	return setenv(key, value)
 }
 
 // Unset is synonym for the os.Unsetenv, unsets a single environment variable.
+//
+// Returns ErrFrozen if the environment is frozen by Freeze.
 func Unset(key string) error {
-	return os.Unsetenv(key)
+	return unsetenv(key)
 }
 
 // Clear is synonym for the os.Clearenv, deletes all environment variables.
+//
+// Does nothing if the environment is frozen by Freeze.
 func Clear() {
-	os.Clearenv()
+	clearenv()
 }
 
 // Environ is synonym for the os.Environ, returns a copy of strings
 // representing the environment, in the form "key=value".
 func Environ() []string {
-	return os.Environ()
+	return environ()
 }
 
 // Expand is synonym for the os.Expand, replaces ${var} or $var in the
 // string according to the values of the current environment variables.
 // References to undefined variables are replaced by the empty string.
+//
+// The operators are supported like in ExpandWith, for example,
+// ${PORT:-8080} gives 8080 if PORT is undefined or empty, and
+// ${HOST:?message} gives the empty string if HOST is undefined.
 func Expand(value string) string {
-	return os.Expand(value, os.Getenv)
+	result, _ := expandValue(value, getenv)
+	return result
 }
 
 // Lookup is synonym for the [os.LookupEnv], retrieves the value of
@@ -46,5 +56,5 @@ func Expand(value string) string {
 // returned and the boolean is true. Otherwise the returned
 // value will be empty and the boolean will be false.
 func Lookup(key string) (string, bool) {
-	return os.LookupEnv(key)
+	return lookupenv(key)
 }
@@ -191,6 +191,22 @@ func TestExpand(t *testing.T) {
 			t.Errorf("for keys `%s`. expected `%s` but `%s`", tpl, exp, v)
 		}
 	}
+
+	// The default values.
+	os.Setenv("EMPTY", "")
+	defaults := map[string]string{
+		"${KEY_0:-1}":        "7",
+		"${EMPTY:-1}":        "1",
+		"${MISSING:-1}":      "1",
+		"${EMPTY-1}":         "",
+		"${MISSING:-$KEY_1}": "5",
+		"$MISSING/path":      "/path",
+	}
+	for tpl, exp := range defaults {
+		if v := Expand(tpl); v != exp {
+			t.Errorf("for `%s` expected `%s` but `%s`", tpl, exp, v)
+		}
+	}
 }
 
 // TestLookup tests Lookup function.
@@ -1,14 +1,111 @@
 package env
 
+import (
+	"reflect"
+	"strings"
+)
+
 // The tagGroup represents the tag group of a field.
 type tagGroup struct {
-	key   string // key name
-	value string // key value
-	sep   string // separator between value items (for sequences)
+	key   string   // key name
+	alias []string // fallback key names, like OLD in env:"NEW|OLD"
+	value string   // key value
+	sep   string   // separator between value items (for sequences)
+	kvsep string   // separator between keys and values (for maps)
+	desc  string   // description of the key
+
+	decimal string // decimal separator of the float values, if any
+	path    string // options of the path values, if any
+	delims  string // delimiters of the template values, if any
+	source  string // name of the source of the value, if any
+
+	required bool // true if the key must be set
+	secret   bool // true if the value is secret
+}
+
+// The newTagGroup creates tag group from the tags of the field.
+// The key name is returned without prefix, if the env tag is
+// empty the field name is used as the key name.
+func newTagGroup(field reflect.StructField) *tagGroup {
+	// The name of the key and the fallback names.
+	key, rest, _ := strings.Cut(field.Tag.Get(tagNameKey), keyAliasSep)
+	key = strings.TrimSpace(key)
+	if key == "" {
+		key = field.Name
+	}
+
+	var alias []string
+	if rest != "" {
+		alias = strings.Split(rest, keyAliasSep)
+		for i := range alias {
+			alias[i] = strings.TrimSpace(alias[i])
+		}
+	}
+
+	// Separator value for slices/arrays.
+	sep := field.Tag.Get(tagNameSep)
+	if sep == "" {
+		sep = defValueSep
+	}
+
+	// Separator of keys and values for maps.
+	kvsep := field.Tag.Get(tagNameKVSep)
+	if kvsep == "" {
+		kvsep = defKVSep
+	}
+
+	// Flags.
+	required, _ := parseBool(field.Tag.Get(tagNameRequired))
+	secret, _ := parseBool(field.Tag.Get(tagNameSecret))
+
+	return &tagGroup{
+		key:      key,
+		alias:    alias,
+		value:    field.Tag.Get(tagNameValue),
+		sep:      sep,
+		kvsep:    kvsep,
+		desc:     strings.TrimSpace(field.Tag.Get(tagNameDesc)),
+		decimal:  field.Tag.Get(tagNameDecimal),
+		path:     field.Tag.Get(tagNamePath),
+		delims:   field.Tag.Get(tagNameDelims),
+		source:   strings.TrimSpace(field.Tag.Get(tagNameSource)),
+		required: required,
+		secret:   secret,
+	}
+}
+
+// The addPrefix method adds the prefix to the key and fallback names.
+func (tg *tagGroup) addPrefix(prefix string) {
+	tg.key = prefix + tg.key
+	if len(tg.alias) != 0 {
+		alias := make([]string, len(tg.alias))
+		for i, name := range tg.alias {
+			alias[i] = prefix + name
+		}
+		tg.alias = alias
+	}
 }
 
-// The isValid method returns true if the key name is valid.
+// The lookup method returns the value of the key, or the value of the
+// first fallback name that is found if the key isn't found.
+func (tg tagGroup) lookup(lookup lookupFunc) (string, bool, error) {
+	value, ok, err := lookup(tg.key)
+	for i := 0; !ok && err == nil && i < len(tg.alias); i++ {
+		value, ok, err = lookup(tg.alias[i])
+	}
+
+	return value, ok, err
+}
+
+// The isValid method returns true if the key name
+// and all fallback names are valid.
 func (tg tagGroup) isValid() bool {
+	for _, name := range tg.alias {
+		if !validKeyRgx.MatchString(name) {
+			return false
+		}
+	}
+
 	return validKeyRgx.MatchString(tg.key)
 }
 
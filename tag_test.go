@@ -1,6 +1,7 @@
 package env
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -26,3 +27,38 @@ func TestTagGroupl(t *testing.T) {
 		t.Error("should be valid")
 	}
 }
+
+// TestTagGroupAlias tests the fallback names of the key.
+func TestTagGroupAlias(t *testing.T) {
+	field := reflect.StructField{
+		Name: "Port",
+		Tag:  `env:"PORT | NOMAD_PORT_http|LEGACY_PORT"`,
+	}
+
+	tg := newTagGroup(field)
+	tg.addPrefix("APP_")
+	if tg.key != "APP_PORT" || len(tg.alias) != 2 ||
+		tg.alias[0] != "APP_NOMAD_PORT_http" {
+		t.Errorf("incorrect key and fallback names: %s %v", tg.key, tg.alias)
+	}
+
+	values := map[string]string{"APP_LEGACY_PORT": "1", "APP_X": "2"}
+	lookup := func(key string) (string, bool, error) {
+		value, ok := values[key]
+		return value, ok, nil
+	}
+
+	if value, ok, _ := tg.lookup(lookup); !ok || value != "1" {
+		t.Errorf("expected `1` but `%s`", value)
+	}
+
+	values["APP_PORT"] = "3"
+	if value, ok, _ := tg.lookup(lookup); !ok || value != "3" {
+		t.Errorf("expected `3` but `%s`", value)
+	}
+
+	tg.alias = append(tg.alias, "1_BAD")
+	if tg.isValid() {
+		t.Error("should be invalid")
+	}
+}
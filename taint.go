@@ -0,0 +1,106 @@
+package env
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	// The tainted is the set of the keys loaded from untrusted sources.
+	tainted = make(map[string]bool)
+
+	// The taintedMu protects the tainted.
+	taintedMu sync.RWMutex
+)
+
+// LoadUntrusted loads the keys of the env-file read from the r, like
+// LoadReader does, and marks them as tainted: the values come from the
+// untrusted source (remote URL, user-supplied file etc.), so the
+// security-sensitive code can check them by IsTainted and refuse to use
+// them for the things like command paths. The variables like ${var} or
+// $var in the values aren't expanded, so the untrusted content can't
+// copy the values of other keys.
+//
+// The mark is removed when the key is changed through the package
+// by other functions (Set, Unset, Load, Update etc.).
+//
+// # Examples
+//
+//	file, err := os.Open(userPath)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer file.Close()
+//
+//	if err := env.LoadUntrusted(file, false); err != nil {
+//		log.Fatal(err)
+//	}
+//	...
+//	if env.IsTainted("EDITOR") {
+//		log.Fatal("EDITOR is set by untrusted source")
+//	}
+func LoadUntrusted(r io.Reader, update bool) error {
+	expand, forced := false, false
+	pairs, _, err := parseReader("", r, expand, forced)
+	if err != nil {
+		return err
+	}
+
+	for i := range pairs {
+		pairs[i].tainted = true
+	}
+
+	return applyPairs(pairs, expand, update)
+}
+
+// IsTainted returns true if the value of the key is loaded
+// from the untrusted source (see LoadUntrusted).
+//
+// # Examples
+//
+//	if env.IsTainted("PLUGIN_PATH") {
+//		return errors.New("untrusted plugin path")
+//	}
+func IsTainted(key string) bool {
+	taintedMu.RLock()
+	defer taintedMu.RUnlock()
+	return tainted[key]
+}
+
+// TaintedKeys returns the sorted keys marked as tainted.
+func TaintedKeys() []string {
+	taintedMu.RLock()
+	defer taintedMu.RUnlock()
+
+	keys := make([]string, 0, len(tainted))
+	for key := range tainted {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// The setTaint marks the key as tainted.
+func setTaint(key string) {
+	taintedMu.Lock()
+	defer taintedMu.Unlock()
+	tainted[key] = true
+}
+
+// The clearTaint removes the taint marks of the keys,
+// all keys are cleared if no keys are given.
+func clearTaint(keys ...string) {
+	taintedMu.Lock()
+	defer taintedMu.Unlock()
+
+	if len(keys) == 0 {
+		tainted = make(map[string]bool)
+		return
+	}
+
+	for _, key := range keys {
+		delete(tainted, key)
+	}
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestLoadUntrusted tests LoadUntrusted and IsTainted functions.
+func TestLoadUntrusted(t *testing.T) {
+	os.Clearenv()
+	clearTaint()
+	os.Setenv("HOME", "/home/goloop")
+	os.Setenv("EDITOR", "vim")
+
+	data := "EDITOR=/tmp/evil\nPLUGIN=${HOME}/plugin\nLANG=en\n"
+	if err := LoadUntrusted(strings.NewReader(data), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing keys aren't updated and aren't tainted.
+	if v := Get("EDITOR"); v != "vim" || IsTainted("EDITOR") {
+		t.Errorf("expected trusted `vim` but `%s`", v)
+	}
+
+	// The values aren't expanded.
+	if v := Get("PLUGIN"); v != "${HOME}/plugin" {
+		t.Errorf("expected `${HOME}/plugin` but `%s`", v)
+	}
+
+	if v := strings.Join(TaintedKeys(), " "); v != "LANG PLUGIN" {
+		t.Errorf("expected `LANG PLUGIN` but `%s`", v)
+	}
+
+	// The mark is removed by the trusted change.
+	Set("LANG", "uk")
+	if IsTainted("LANG") {
+		t.Error("expected trusted LANG")
+	}
+
+	// Update the existing keys.
+	if err := LoadUntrusted(strings.NewReader(data), true); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsTainted("EDITOR") {
+		t.Error("expected tainted EDITOR")
+	}
+
+	Clear()
+	if v := TaintedKeys(); len(v) != 0 {
+		t.Errorf("expected no tainted keys but `%v`", v)
+	}
+
+	// Incorrect content.
+	if err := LoadUntrusted(strings.NewReader("A\n"), true); err == nil {
+		t.Error("an error is expected for the incorrect content")
+	}
+}
@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Target is the platform where the keys of the marshaled objects are used
+// (see SetTarget). The keys that are valid for the package can still be
+// rejected or changed by the platform.
+type Target uint32
+
+const (
+	// TargetAny doesn't check the keys, it's the default target.
+	TargetAny Target = iota
+
+	// TargetPOSIX accepts the portable names of the POSIX standard:
+	// the upper case letters, digits and underscores, the name can't
+	// start with a digit.
+	TargetPOSIX
+
+	// TargetWindows accepts the names without the equal sign, the total
+	// size of the KEY=VALUE item is limited to 32767 characters, and
+	// the names that differ only in case are the same variable.
+	TargetWindows
+
+	// TargetKubernetes accepts the names of the environment variables
+	// of the containers: letters, digits, underscores, dots and dashes,
+	// the name can't start with a digit and is limited to 253
+	// characters (as the key of the ConfigMap).
+	TargetKubernetes
+)
+
+// The target is the current target platform of the marshaled keys.
+var target atomic.Uint32
+
+// The rules of the names of the target platforms.
+var (
+	posixNameRgx      = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+	kubernetesNameRgx = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+)
+
+// The limits of the target platforms.
+const (
+	windowsMaxItem    = 32767
+	kubernetesMaxName = 253
+)
+
+// SetTarget sets the target platform of the keys generated by Marshal and
+// the functions based on it (Save, SaveCommented, SaveMerge, Encoder etc.).
+// If the target isn't TargetAny, the keys are checked against the rules
+// of the platform before the environment is changed or the file is
+// written, so the incompatible names are found before the deployment
+// rather than at runtime on the platform. Returns the previous target.
+//
+// The error contains a *KeyError wrapping ErrIncompatibleKey
+// for each incompatible key.
+//
+// # Examples
+//
+//	env.SetTarget(env.TargetPOSIX)
+//	err := env.Save(".env", "app_", config) // app_HOST: incompatible key
+func SetTarget(t Target) Target {
+	return Target(target.Swap(uint32(t)))
+}
+
+// String returns the name of the target.
+func (t Target) String() string {
+	switch t {
+	case TargetAny:
+		return "any"
+	case TargetPOSIX:
+		return "POSIX"
+	case TargetWindows:
+		return "Windows"
+	case TargetKubernetes:
+		return "Kubernetes"
+	}
+
+	return fmt.Sprintf("Target(%d)", uint32(t))
+}
+
+// Check checks the KEY=VALUE items (like the result of Marshal) against
+// the rules of the target platform. Returns nil if all keys are valid,
+// otherwise the error contains a *KeyError wrapping ErrIncompatibleKey
+// for each incompatible key.
+//
+// # Examples
+//
+//	items, _ := env.NewEncoder("APP_").Encode(config)
+//	if err := env.TargetKubernetes.Check(items); err != nil {
+//		log.Println(err)
+//	}
+func (t Target) Check(items []string) error {
+	var errs []error
+	incompatible := func(key, format string, a ...interface{}) {
+		errs = append(errs, &KeyError{
+			Key: key,
+			Err: fmt.Errorf("%w: %s: %s", ErrIncompatibleKey, t,
+				fmt.Sprintf(format, a...)),
+		})
+	}
+
+	seen := make(map[string]string, len(items))
+	for _, item := range items {
+		key, _, _ := strings.Cut(item, "=")
+		switch t {
+		case TargetPOSIX:
+			if !posixNameRgx.MatchString(key) {
+				incompatible(key, "upper case letters, digits and "+
+					"underscores are allowed only")
+			}
+		case TargetWindows:
+			if len(item) > windowsMaxItem {
+				incompatible(key, "the item is longer than %d characters",
+					windowsMaxItem)
+			}
+
+			upper := strings.ToUpper(key)
+			if other, ok := seen[upper]; ok && other != key {
+				incompatible(key, "the same variable as %s", other)
+			}
+			seen[upper] = key
+		case TargetKubernetes:
+			if !kubernetesNameRgx.MatchString(key) {
+				incompatible(key, "letters, digits, underscores, dots "+
+					"and dashes are allowed only")
+			} else if len(key) > kubernetesMaxName {
+				incompatible(key, "the name is longer than %d characters",
+					kubernetesMaxName)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// The checkTarget checks the items against the current target.
+func checkTarget(items []string) error {
+	t := Target(target.Load())
+	if t == TargetAny {
+		return nil
+	}
+
+	return t.Check(items)
+}
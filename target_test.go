@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTargetCheck tests the checks of the keys by the target platforms.
+func TestTargetCheck(t *testing.T) {
+	long := strings.Repeat("A", 254)
+	tests := []struct {
+		target Target
+		items  []string
+		keys   []string // incompatible keys
+	}{
+		{TargetAny, []string{"a.b=1", "9=2"}, nil},
+		{TargetPOSIX, []string{"HOST=a", "_X1=b", "app_HOST=c"},
+			[]string{"app_HOST"}},
+		{TargetWindows, []string{"Host=a", "HOST=b", "PORT=" +
+			strings.Repeat("1", windowsMaxItem)}, []string{"HOST", "PORT"}},
+		{TargetKubernetes, []string{"app.host-1=a", "1X=b", long + "=c"},
+			[]string{"1X", long}},
+	}
+
+	for i, s := range tests {
+		err := s.target.Check(s.items)
+		if len(s.keys) == 0 {
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+
+		if !errors.Is(err, ErrIncompatibleKey) {
+			t.Errorf("test %d: expected ErrIncompatibleKey but `%v`", i, err)
+			continue
+		}
+
+		var keys []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var keyErr *KeyError
+			if errors.As(e, &keyErr) {
+				keys = append(keys, keyErr.Key)
+			}
+		}
+
+		if strings.Join(keys, " ") != strings.Join(s.keys, " ") {
+			t.Errorf("test %d: expected `%v` but `%v`", i, s.keys, keys)
+		}
+	}
+}
+
+// TestSetTarget tests the checks of the keys on marshal.
+func TestSetTarget(t *testing.T) {
+	data := struct {
+		Host string `env:"host"`
+	}{Host: "localhost"}
+
+	defer SetTarget(SetTarget(TargetPOSIX))
+	os.Clearenv()
+	if _, err := Marshal("", data); !errors.Is(err, ErrIncompatibleKey) {
+		t.Errorf("expected ErrIncompatibleKey but `%v`", err)
+	}
+
+	if v := Get("host"); v != "" {
+		t.Errorf("the environment is changed: `%s`", v)
+	}
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := Save(filename, "", data); err == nil {
+		t.Error("expected an error for the incompatible key")
+	}
+
+	if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file is written: %v", err)
+	}
+
+	enc := NewEncoder("")
+	if _, err := enc.Encode(data); err == nil {
+		t.Error("expected an error for the incompatible key")
+	}
+
+	if _, err := enc.AppendEncode([]string{"path=/"}, data); err == nil {
+		t.Error("expected an error for the incompatible key")
+	}
+
+	// The lower case letters are valid in Kubernetes.
+	SetTarget(TargetKubernetes)
+	if _, err := Marshal("APP_", data); err != nil {
+		t.Error(err)
+	}
+
+	if v := Get("APP_host"); v != "localhost" {
+		t.Errorf("expected `localhost` but `%s`", v)
+	}
+}
+
+// countedMarshaler counts the calls of its MarshalEnv method.
+type countedMarshaler struct {
+	calls int
+}
+
+// MarshalEnv implements Marshaler.
+func (m *countedMarshaler) MarshalEnv() ([]string, error) {
+	m.calls++
+	return []string{"LABEL=a"}, nil
+}
+
+// TestSetTargetOnce tests that the object is marshaled once
+// and the fields are set after the check.
+func TestSetTargetOnce(t *testing.T) {
+	data := struct {
+		Host   string            `env:"HOST"`
+		Labels *countedMarshaler `env:"LABELS"`
+		Port   string            `env:"port"`
+	}{Host: "localhost", Labels: &countedMarshaler{}, Port: "80"}
+
+	defer SetTarget(SetTarget(TargetPOSIX))
+	os.Clearenv()
+	if _, err := Marshal("", &data); !errors.Is(err, ErrIncompatibleKey) {
+		t.Errorf("expected ErrIncompatibleKey but `%v`", err)
+	}
+
+	// The HOST is before the incompatible key, but isn't set.
+	if v := Environ(); len(v) != 0 {
+		t.Errorf("the environment is changed: `%v`", v)
+	}
+
+	if data.Labels.calls != 1 {
+		t.Errorf("expected 1 call but %d", data.Labels.calls)
+	}
+
+	// The compatible keys are set.
+	data.Port = ""
+	SetTarget(TargetKubernetes)
+	items, err := Marshal("", &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 3 || Get("HOST") != "localhost" ||
+		data.Labels.calls != 2 {
+		t.Errorf("incorrect items `%v` or calls %d", items,
+			data.Labels.calls)
+	}
+}
@@ -0,0 +1,184 @@
+package env
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"reflect"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// The templateSourceName is the name of the template associated with
+// the compiled template that keeps the source text of the template,
+// to marshal the template back (see templateSource).
+const templateSourceName = "env:source"
+
+var (
+	// The textTemplateType is the reflect.Type of the *text/template.Template.
+	textTemplateType = reflect.TypeOf((*texttemplate.Template)(nil))
+
+	// The htmlTemplateType is the reflect.Type of the *html/template.Template.
+	htmlTemplateType = reflect.TypeOf((*htmltemplate.Template)(nil))
+
+	// The templateCache contains the last compiled template (that is
+	// never executed) of each templateKey as *templateEntry, so the
+	// same value isn't compiled again on each unmarshaling. The fields
+	// get the clones of the compiled template.
+	templateCache sync.Map
+)
+
+// The templateKey is the key of the compiled template in the cache.
+type templateKey struct {
+	typ    reflect.Type // type of the template
+	name   string       // name of the template (key name)
+	delims string       // value of the delims tag
+}
+
+// The templateEntry is the compiled template of the source.
+type templateEntry struct {
+	source string      // text of the template
+	tmpl   interface{} // compiled template
+}
+
+// The isTemplateType returns true if the type is the pointer
+// to the text/template or html/template Template.
+func isTemplateType(t reflect.Type) bool {
+	return t == textTemplateType || t == htmlTemplateType
+}
+
+// The setTemplate compiles the value of the tag group into the template
+// of the item type (see isTemplateType), the template is named by the key.
+// The delimiters are set by the delims tag, like delims:"[[ ]]". The empty
+// value sets the nil template.
+//
+// Each item gets its own clone of the compiled template,
+// so the changes of one template don't affect the others.
+func setTemplate(item *reflect.Value, tg *tagGroup) error {
+	if tg.value == "" {
+		item.Set(reflect.Zero(item.Type()))
+		return nil
+	}
+
+	var tmpl interface{}
+	key := templateKey{item.Type(), tg.key, tg.delims}
+	if entry, ok := templateCache.Load(key); ok &&
+		entry.(*templateEntry).source == tg.value {
+		tmpl = entry.(*templateEntry).tmpl
+	} else {
+		var err error
+		if tmpl, err = compileTemplate(item.Type(), tg); err != nil {
+			return err
+		}
+
+		// Only the last source of the key is cached.
+		templateCache.Store(key, &templateEntry{tg.value, tmpl})
+	}
+
+	var (
+		clone interface{}
+		err   error
+	)
+	switch t := tmpl.(type) {
+	case *texttemplate.Template:
+		clone, err = t.Clone()
+	case *htmltemplate.Template:
+		clone, err = t.Clone()
+	}
+	if err != nil {
+		return err
+	}
+
+	item.Set(reflect.ValueOf(clone))
+	return nil
+}
+
+// The compileTemplate compiles the value of the tag group into the
+// template of the type t (see setTemplate) with the associated template
+// that keeps the source (see templateSourceName).
+func compileTemplate(t reflect.Type, tg *tagGroup) (interface{}, error) {
+	left, right, err := parseDelims(tg.delims)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tg.key, err)
+	}
+
+	// The source is kept as the text of the associated template.
+	source := &parse.Tree{
+		Name:      templateSourceName,
+		ParseName: templateSourceName,
+		Root: &parse.ListNode{
+			NodeType: parse.NodeList,
+			Nodes: []parse.Node{&parse.TextNode{
+				NodeType: parse.NodeText,
+				Text:     []byte(tg.value),
+			}},
+		},
+	}
+
+	if t == htmlTemplateType {
+		tmpl, err := htmltemplate.New(tg.key).Delims(left, right).
+			Parse(tg.value)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tmpl.AddParseTree(templateSourceName, source)
+		return tmpl, err
+	}
+
+	tmpl, err := texttemplate.New(tg.key).Delims(left, right).
+		Parse(tg.value)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tmpl.AddParseTree(templateSourceName, source)
+	return tmpl, err
+}
+
+// The parseDelims parses the value of the delims tag: the left
+// and right delimiters separated by space. The empty value
+// means the default delimiters.
+func parseDelims(delims string) (string, string, error) {
+	if delims == "" {
+		return "", "", nil
+	}
+
+	items := strings.Fields(delims)
+	if len(items) != 2 {
+		return "", "", fmt.Errorf("invalid delims: %q", delims)
+	}
+
+	return items[0], items[1], nil
+}
+
+// The templateSource returns the source of the template compiled by
+// the setTemplate. Returns an error if the template was created in
+// another way, and the empty string for the nil template.
+func templateSource(item reflect.Value) (string, error) {
+	if item.IsNil() {
+		return "", nil
+	}
+
+	var tree *parse.Tree
+	switch t := item.Interface().(type) {
+	case *texttemplate.Template:
+		if source := t.Lookup(templateSourceName); source != nil {
+			tree = source.Tree
+		}
+	case *htmltemplate.Template:
+		if source := t.Lookup(templateSourceName); source != nil {
+			tree = source.Tree
+		}
+	}
+
+	if tree != nil && tree.Root != nil && len(tree.Root.Nodes) == 1 {
+		if text, ok := tree.Root.Nodes[0].(*parse.TextNode); ok {
+			return string(text.Text), nil
+		}
+	}
+
+	return "", fmt.Errorf("source of the %s template is unknown",
+		item.Type())
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	htmltemplate "html/template"
+	"os"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+// TestUnmarshalTemplate tests the template fields.
+func TestUnmarshalTemplate(t *testing.T) {
+	type config struct {
+		Greeting *texttemplate.Template `env:"GREETING"`
+		Page     *htmltemplate.Template `env:"PAGE" delims:"[[ ]]"`
+		Empty    *texttemplate.Template `env:"EMPTY"`
+	}
+
+	os.Clearenv()
+	Set("GREETING", "Hello, {{.}}!")
+	Set("PAGE", "<p>[[.]]</p>")
+
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Empty != nil || data.Greeting == nil || data.Page == nil {
+		t.Fatalf("incorrect templates: %+v", data)
+	}
+
+	var sb strings.Builder
+	data.Greeting.Execute(&sb, "World")
+	data.Page.Execute(&sb, "<b>")
+	if s := sb.String(); s != "Hello, World!<p>&lt;b&gt;</p>" {
+		t.Errorf("expected `Hello, World!<p>&lt;b&gt;</p>` but `%s`", s)
+	}
+
+	// Each field gets its own template.
+	var other config
+	if err := Unmarshal("", &other); err != nil {
+		t.Fatal(err)
+	}
+
+	if other.Greeting == data.Greeting || other.Page == data.Page {
+		t.Error("the templates are shared")
+	}
+
+	texttemplate.Must(other.Greeting.New("extra").Parse("x"))
+	htmltemplate.Must(other.Page.New("extra").Parse("x"))
+	if data.Greeting.Lookup("extra") != nil ||
+		data.Page.Lookup("extra") != nil {
+		t.Error("the change of the template affects another field")
+	}
+
+	// The cache keeps the last source of the key only.
+	Set("GREETING", "Hi, {{.}}!")
+	if err := Unmarshal("", &other); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	templateCache.Range(func(k, v interface{}) bool {
+		if k.(templateKey).name == "GREETING" {
+			count++
+		}
+		return true
+	})
+	if count != 1 {
+		t.Errorf("expected one cached template but %d", count)
+	}
+	Set("GREETING", "Hello, {{.}}!")
+
+	items, err := marshalEnv("", data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"GREETING=Hello, {{.}}!", "PAGE=<p>[[.]]</p>",
+		"EMPTY="}
+	for i, item := range expected {
+		if items[i] != item {
+			t.Errorf("expected `%s` but `%s`", item, items[i])
+		}
+	}
+
+	// The source of the template created by
+	// the program is unknown.
+	data.Empty = texttemplate.Must(texttemplate.New("").Parse("x"))
+	if _, err := marshalEnv("", data, true); err == nil {
+		t.Error("expected an error for unknown source")
+	}
+
+	Set("GREETING", "{{.")
+	if err := Unmarshal("", &config{}); err == nil {
+		t.Error("expected an error for invalid template")
+	}
+}
@@ -0,0 +1,187 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TerraformVariables writes the variables.tf snippet (for Terraform or
+// OpenTofu) with a variable for each environment variable of the
+// structure. The name of the variable is the key (with prefix) in lower
+// case, the type, default value, description and sensitivity are taken
+// from the field type and the def, desc and secret tags.
+//
+// The fields without def tag have the zero value of their type as
+// default (the same as Unmarshal sets), except the required fields
+// that have no default at all, so Terraform requires them too.
+// The maps of structures (like map[string]DB) are skipped, as the keys
+// of their entries aren't fixed.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST" def:"localhost" desc:"server host"`
+//		Port int    `env:"PORT" required:"true"`
+//	}
+//
+//	env.TerraformVariables(os.Stdout, "APP_", Config{})
+//
+//	// Output:
+//	// variable "app_host" {
+//	//   type        = string
+//	//   description = "server host"
+//	//   default     = "localhost"
+//	// }
+//	//
+//	// variable "app_port" {
+//	//   type        = number
+//	// }
+func TerraformVariables(w io.Writer, prefix string, obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return fmt.Errorf("incorrect type: %v", t)
+	}
+
+	var sb strings.Builder
+	err := walkFields(prefix, t, func(fi fieldInfo) error {
+		if isStructMap(fi.field.Type) {
+			return nil // the keys of the entries aren't fixed
+		}
+
+		if sb.Len() != 0 {
+			sb.WriteString("\n")
+		}
+
+		fmt.Fprintf(&sb, "variable %q {\n", strings.ToLower(fi.tg.key))
+		fmt.Fprintf(&sb, "  type        = %s\n", hclType(fi.field.Type))
+		if fi.tg.desc != "" {
+			fmt.Fprintf(&sb, "  description = %s\n", hclString(fi.tg.desc))
+		}
+
+		if fi.tg.value != "" || !fi.tg.required {
+			item := reflect.New(fi.field.Type).Elem()
+			if err := setFieldValue(&item, fi.tg, nil, nil); err != nil {
+				return fmt.Errorf("the %s field: %w", fi.name, err)
+			}
+			fmt.Fprintf(&sb, "  default     = %s\n", hclValue(item))
+		}
+
+		if fi.tg.secret {
+			sb.WriteString("  sensitive   = true\n")
+		}
+
+		sb.WriteString("}\n")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// TerraformTFVars writes the .tfvars file with the values of the object
+// fields for the variables created by TerraformVariables. Note that the
+// values of secret fields are written as is.
+//
+// The obj can be a structure or a pointer to a structure.
+//
+// # Examples
+//
+//	config := Config{Host: "0.0.0.0", Port: 80}
+//	env.TerraformTFVars(os.Stdout, "APP_", config)
+//
+//	// Output:
+//	// app_host = "0.0.0.0"
+//	// app_port = 80
+func TerraformTFVars(w io.Writer, prefix string, obj interface{}) error {
+	rv := reflect.ValueOf(obj)
+	if reflect.Indirect(rv).Kind() != reflect.Struct {
+		return fmt.Errorf("incorrect type: %T", obj)
+	}
+
+	var sb strings.Builder
+	err := walkFields(prefix, rv.Type(), func(fi fieldInfo) error {
+		if isStructMap(fi.field.Type) {
+			return nil // see TerraformVariables
+		}
+
+		item, ok := fieldValue(rv, fi.path)
+		if !ok {
+			item = reflect.Zero(fi.field.Type)
+		}
+
+		fmt.Fprintf(&sb, "%s = %s\n",
+			strings.ToLower(fi.tg.key), hclValue(item))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// The hclType returns the Terraform type of the Go type.
+func hclType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Array, reflect.Slice:
+		return fmt.Sprintf("list(%s)", hclType(t.Elem()))
+	}
+
+	return "string"
+}
+
+// The hclValue returns the item as a Terraform literal.
+func hclValue(item reflect.Value) string {
+	item = reflect.Indirect(item)
+	if !item.IsValid() {
+		return "null"
+	}
+
+	switch item.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.Bool:
+		return fmt.Sprint(item.Interface())
+	case reflect.Array, reflect.Slice:
+		items := make([]string, item.Len())
+		for i := range items {
+			items[i] = hclValue(item.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Struct:
+		if u, ok := item.Interface().(url.URL); ok {
+			return hclString(u.String())
+		}
+	case reflect.String:
+		return hclString(item.String())
+	}
+
+	return hclString(fmt.Sprint(item.Interface()))
+}
+
+// The hclString quotes the string for Terraform, the template
+// sequences ${ and %{ are escaped to be used literally.
+func hclString(str string) string {
+	str = strconv.Quote(str)
+	str = strings.ReplaceAll(str, "${", "$${")
+	return strings.ReplaceAll(str, "%{", "%%{")
+}
@@ -0,0 +1,104 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTerraformVariables tests TerraformVariables function.
+func TestTerraformVariables(t *testing.T) {
+	type DB struct {
+		Password string `env:"PASSWORD" required:"true" secret:"true"`
+	}
+
+	type Config struct {
+		Host  string        `env:"HOST" def:"localhost" desc:"server \"host\""`
+		Port  int           `env:"PORT" def:"80"`
+		Hosts []string      `env:"HOSTS" def:"a,b" sep:","`
+		Debug *bool         `env:"DEBUG"`
+		DB    DB            `env:"DB"`
+		Pools map[string]DB `env:"POOL"`
+	}
+
+	var sb strings.Builder
+	if err := TerraformVariables(&sb, "APP_", Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `variable "app_host" {
+  type        = string
+  description = "server \"host\""
+  default     = "localhost"
+}
+
+variable "app_port" {
+  type        = number
+  default     = 80
+}
+
+variable "app_hosts" {
+  type        = list(string)
+  default     = ["a", "b"]
+}
+
+variable "app_debug" {
+  type        = bool
+  default     = false
+}
+
+variable "app_db_password" {
+  type        = string
+  sensitive   = true
+}
+`
+
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, sb.String())
+	}
+}
+
+// TestTerraformTFVars tests TerraformTFVars function.
+func TestTerraformTFVars(t *testing.T) {
+	type DB struct {
+		Name string `env:"NAME"`
+	}
+
+	type Config struct {
+		Host  string        `env:"HOST"`
+		Port  int           `env:"PORT"`
+		Codes []int         `env:"CODES"`
+		Debug bool          `env:"DEBUG"`
+		DB    *DB           `env:"DB"`
+		Tmpl  string        `env:"TMPL"`
+		Rates [2]float64    `env:"RATES"`
+		Pools map[string]DB `env:"POOL"`
+	}
+
+	config := Config{
+		Host:  "0.0.0.0",
+		Port:  8080,
+		Codes: []int{200, 404},
+		Debug: true,
+		Tmpl:  "${name}",
+		Rates: [2]float64{0.5, 1},
+		Pools: map[string]DB{"MAIN": {Name: "main"}},
+	}
+
+	var sb strings.Builder
+	if err := TerraformTFVars(&sb, "APP_", &config); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `app_host = "0.0.0.0"
+app_port = 8080
+app_codes = [200, 404]
+app_debug = true
+app_db_name = ""
+app_tmpl = "$${name}"
+app_rates = [0.5, 1]
+`
+
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\nbut:\n%s", expected, sb.String())
+	}
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// The textUnmarshalerType is the reflect.Type
+// of the encoding.TextUnmarshaler interface.
+var textUnmarshalerType = reflect.TypeOf(
+	(*encoding.TextUnmarshaler)(nil)).Elem()
+
+// The isTextUnmarshaler returns true if the pointer to the type
+// implements the encoding.TextUnmarshaler, like netip.Addr or
+// uuid.UUID. The pointer types are unmarshaled by their elements.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr &&
+		reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// The unmarshalText sets the value into the item by its UnmarshalText
+// method, the empty value is the zero value. The boolean is false if
+// the item doesn't implement the encoding.TextUnmarshaler.
+func unmarshalText(item reflect.Value, value string) (bool, error) {
+	t := item.Type()
+	if !isTextUnmarshaler(t) {
+		return false, nil
+	}
+
+	if value == "" {
+		item.Set(reflect.Zero(t))
+		return true, nil
+	}
+
+	tmp := reflect.New(t)
+	u := tmp.Interface().(encoding.TextUnmarshaler)
+	if err := u.UnmarshalText([]byte(value)); err != nil {
+		return true, err
+	}
+
+	item.Set(tmp.Elem())
+	return true, nil
+}
+
+// The textMarshalerType is the reflect.Type
+// of the encoding.TextMarshaler interface.
+var textMarshalerType = reflect.TypeOf(
+	(*encoding.TextMarshaler)(nil)).Elem()
+
+// The isTextMarshaler returns true if the type or the pointer
+// to the type implements the encoding.TextMarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || (t.Kind() != reflect.Ptr &&
+		reflect.PointerTo(t).Implements(textMarshalerType))
+}
+
+// The marshalText returns the text of the item by its MarshalText method
+// declared on the value or pointer receiver, like marshalerOf does. The
+// boolean is false if the item doesn't implement the
+// encoding.TextMarshaler.
+func marshalText(item reflect.Value) (string, bool, error) {
+	if !item.IsValid() || !item.CanInterface() ||
+		(item.Kind() == reflect.Ptr && item.IsNil()) {
+		return "", false, nil
+	}
+
+	t := item.Type()
+	if !isTextMarshaler(t) {
+		return "", false, nil
+	} else if !t.Implements(textMarshalerType) {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(item)
+		item = ptr
+	}
+
+	text, err := item.Interface().(encoding.TextMarshaler).MarshalText()
+	return string(text), true, err
+}
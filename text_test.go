@@ -0,0 +1,144 @@
+package env
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestUnmarshalTextUnmarshaler tests unmarshaling of the types
+// that implement encoding.TextUnmarshaler.
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	type config struct {
+		Addr    netip.Addr     `env:"ADDR"`
+		Addrs   []netip.Addr   `env:"ADDRS" sep:","`
+		Prefix  *netip.Prefix  `env:"PREFIX"`
+		IP      net.IP         `env:"IP"`
+		Amount  big.Int        `env:"AMOUNT"`
+		Empty   netip.AddrPort `env:"EMPTY"`
+		Default netip.Addr     `env:"DEFAULT" def:"::1"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ADDR", "10.0.0.1")
+	os.Setenv("ADDRS", "10.0.0.2,10.0.0.3")
+	os.Setenv("PREFIX", "10.0.0.0/8")
+	os.Setenv("IP", "192.168.0.1")
+	os.Setenv("AMOUNT", "123456789012345678901234567890")
+	os.Setenv("EMPTY", "")
+
+	var c config
+	if err := Unmarshal("", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "10.0.0.1 [10.0.0.2 10.0.0.3] 10.0.0.0/8 192.168.0.1 " +
+		"123456789012345678901234567890 invalid AddrPort ::1"
+	v := fmt.Sprintf("%v %v %v %v %v %v %v", c.Addr, c.Addrs, *c.Prefix,
+		c.IP, &c.Amount, c.Empty, c.Default)
+	if v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// Incorrect value.
+	os.Setenv("ADDR", "10.0.0")
+	if err := Unmarshal("", &c); err == nil {
+		t.Error("an error is expected for the incorrect address")
+	}
+
+	// The getters support the types too.
+	os.Setenv("ADDR", "10.0.0.4")
+	if addr, err := GetAs[netip.Addr]("ADDR"); err != nil ||
+		addr.String() != "10.0.0.4" {
+		t.Errorf("expected `10.0.0.4` but `%s` (%v)", addr, err)
+	}
+}
+
+// level is the named integer marshaled as the text.
+type level int
+
+// MarshalText implements encoding.TextMarshaler.
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info"}[l]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level: %s", text)
+	}
+
+	return nil
+}
+
+// TestMarshalTextMarshaler tests marshaling of the types
+// that implement encoding.TextMarshaler.
+func TestMarshalTextMarshaler(t *testing.T) {
+	type config struct {
+		Addr   netip.Addr     `env:"ADDR"`
+		Addrs  []netip.Addr   `env:"ADDRS" sep:","`
+		Prefix *netip.Prefix  `env:"PREFIX"`
+		IP     net.IP         `env:"IP"`
+		Amount big.Int        `env:"AMOUNT"`
+		Empty  netip.AddrPort `env:"EMPTY"`
+		Level  level          `env:"LEVEL"`
+	}
+
+	prefix := netip.MustParsePrefix("10.0.0.0/8")
+	c := config{
+		Addr: netip.MustParseAddr("10.0.0.1"),
+		Addrs: []netip.Addr{
+			netip.MustParseAddr("10.0.0.2"),
+			netip.MustParseAddr("10.0.0.3"),
+		},
+		Prefix: &prefix,
+		IP:     net.ParseIP("192.168.0.1"),
+		Level:  1,
+	}
+	c.Amount.SetString("123456789012345678901234567890", 10)
+
+	os.Clearenv()
+	items, err := Marshal("", c) // by value, big.Int has pointer receiver
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[ADDR=10.0.0.1 ADDRS=10.0.0.2,10.0.0.3 PREFIX=10.0.0.0/8 " +
+		"IP=192.168.0.1 AMOUNT=123456789012345678901234567890 EMPTY= " +
+		"LEVEL=info]"
+	if v := fmt.Sprint(items); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// Round-trip through the env-file.
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := Save(filename, "", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	if err := Load(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	var r config
+	if err := Unmarshal("", &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Addr != c.Addr || len(r.Addrs) != 2 || r.Addrs[1] != c.Addrs[1] ||
+		*r.Prefix != prefix || !r.IP.Equal(c.IP) ||
+		r.Amount.Cmp(&c.Amount) != 0 || r.Empty.IsValid() ||
+		r.Level != c.Level {
+		t.Errorf("expected `%v` but `%v`", c, r)
+	}
+}
@@ -0,0 +1,116 @@
+package env
+
+import (
+	"sync"
+	"time"
+)
+
+// ReloadStats describes the reload events of the Reloader,
+// for example, to export them as metrics.
+type ReloadStats struct {
+	Events     uint64 // number of the events passed to Trigger
+	Suppressed uint64 // events merged into the pending reload
+	Reloads    uint64 // number of the performed reloads
+	Failures   uint64 // number of the reloads that returned an error
+	LastErr    error  // error of the last reload, nil if it succeeded
+}
+
+// The reloadLimit limits the rate of the reloads triggered by events.
+type reloadLimit struct {
+	mu          sync.Mutex
+	debounce    time.Duration // quiet period after the last event
+	minInterval time.Duration // minimal time between the reloads
+	timer       *time.Timer   // timer of the pending reload
+	pending     bool          // true if the reload is scheduled
+	last        time.Time     // time of the last reload
+	stats       ReloadStats
+}
+
+// The delay returns the time to wait before the reload, the lock
+// must be held: the debounce period, but not less than the remaining
+// part of the minimal interval after the last reload.
+func (l *reloadLimit) delay() time.Duration {
+	d := l.debounce
+	if l.minInterval > 0 && !l.last.IsZero() {
+		if wait := time.Until(l.last.Add(l.minInterval)); wait > d {
+			d = wait
+		}
+	}
+
+	return d
+}
+
+// SetRateLimit sets the limits of the reloads triggered by the Trigger
+// method. The reload is performed after the debounce period without new
+// events (so the editors that write the file several times per save
+// cause one reload), but not earlier than minInterval after the previous
+// reload (so the rapidly rotating secrets don't cause reload storms).
+// The zero values disable the limits.
+//
+// # Examples
+//
+//	r.SetRateLimit(100*time.Millisecond, 5*time.Second)
+//	...
+//	r.Trigger() // on each change notification of the env-file
+func (r *Reloader) SetRateLimit(debounce, minInterval time.Duration) {
+	r.limit.mu.Lock()
+	defer r.limit.mu.Unlock()
+	r.limit.debounce, r.limit.minInterval = debounce, minInterval
+}
+
+// Trigger schedules the reload with the limits set by the SetRateLimit
+// method, the reload is performed in a separate goroutine. The events
+// that come while the reload is pending are merged into it and counted
+// as suppressed, each of them restarts the debounce period.
+//
+// The result of the reload is available in the Stats, the changes are
+// passed to the onChange function (see NewReloader) as usual.
+func (r *Reloader) Trigger() {
+	l := &r.limit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Events++
+	if l.pending {
+		l.stats.Suppressed++
+
+		// Restart the debounce period if the timer hasn't fired yet.
+		if l.debounce > 0 && l.timer.Stop() {
+			l.timer.Reset(l.delay())
+		}
+		return
+	}
+
+	l.pending = true
+	l.timer = time.AfterFunc(l.delay(), func() {
+		l.mu.Lock()
+		l.pending = false
+		l.mu.Unlock()
+
+		if r.run != nil {
+			r.run()
+		} else {
+			r.Reload()
+		}
+	})
+}
+
+// Stats returns the stats of the reload events.
+func (r *Reloader) Stats() ReloadStats {
+	r.limit.mu.Lock()
+	defer r.limit.mu.Unlock()
+	return r.limit.stats
+}
+
+// The done records the result of the reload.
+func (l *reloadLimit) done(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.last = time.Now()
+	l.stats.Reloads++
+	l.stats.LastErr = err
+	if err != nil {
+		l.stats.Failures++
+	}
+}
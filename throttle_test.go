@@ -0,0 +1,93 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// The waitReloads waits until the reloader performs n reloads.
+func waitReloads(t *testing.T, r *Reloader, n uint64) ReloadStats {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if stats := r.Stats(); stats.Reloads >= n {
+			return stats
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("expected %d reloads but %d", n, r.Stats().Reloads)
+	return ReloadStats{}
+}
+
+// TestReloaderTrigger tests Trigger method with the debounce period.
+func TestReloaderTrigger(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+
+	var cfg config
+	os.Clearenv()
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The burst of events causes one reload.
+	r.SetRateLimit(50*time.Millisecond, 0)
+	for i := 1; i <= 5; i++ {
+		os.Setenv("PORT", "8080")
+		r.Trigger()
+	}
+
+	stats := waitReloads(t, r, 1)
+	if stats.Events != 5 || stats.Suppressed != 4 || stats.Failures != 0 {
+		t.Errorf("incorrect stats: %+v", stats)
+	}
+
+	r.RLock()
+	if cfg.Port != 8080 {
+		t.Errorf("expected `8080` but `%d`", cfg.Port)
+	}
+	r.RUnlock()
+
+	// The failed reload.
+	os.Setenv("PORT", "port")
+	r.Trigger()
+	stats = waitReloads(t, r, 2)
+	if stats.Failures != 1 || stats.LastErr == nil {
+		t.Errorf("expected failure but %+v", stats)
+	}
+}
+
+// TestReloaderMinInterval tests Trigger method with the minimal interval.
+func TestReloaderMinInterval(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+
+	var cfg config
+	os.Clearenv()
+	r, err := NewReloader("", &cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.SetRateLimit(0, 100*time.Millisecond)
+	start := time.Now()
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reload is delayed until the interval ends.
+	r.Trigger()
+	r.Trigger()
+	waitReloads(t, r, 2)
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("expected delay of 100ms but %s", d)
+	}
+
+	if stats := r.Stats(); stats.Events != 2 || stats.Suppressed != 1 {
+		t.Errorf("incorrect stats: %+v", stats)
+	}
+}
@@ -3,14 +3,15 @@ package env
 import (
 	"bufio"
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"runtime/pprof"
+	"sort"
 	"strings"
-	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -76,10 +77,7 @@ func fts(v interface{}, name, sep string) string {
 
 	// Correct the field name to go-style.
 	if strings.Contains(name, "_") {
-		// Split words, capitalize words and join it.
-		name = strings.NewReplacer("_", " ").Replace(name)
-		name = strings.Title(strings.ToLower(name))
-		name = strings.ReplaceAll(name, " ", "")
+		name = FieldNameFromKey(name)
 	}
 
 	// Check if the struct has a field with the given name.
@@ -222,292 +220,678 @@ func isEmpty(str string) bool {
 //	// PORT=80
 //	// EMAIL=goloop@goloop.one
 func readParseStore(filename string, expand, update, forced bool) error {
-	// Define a structure for the line
-	// that is read from the env-file.
-	type line struct {
-		text   string // raw string from env-file
-		number int    // number of line in the env-file
+	opts := loadOptions{expand: expand, update: update, forced: forced}
+	return readParseStoreWith(filename, opts)
+}
+
+// The readParseStoreWith works like readParseStore,
+// but takes the options of loading (see LoadWith).
+func readParseStoreWith(filename string, opts loadOptions) error {
+	// Try to open env-file in read only mode.
+	file, err := os.OpenFile(filename, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return parseStore(filename, file, opts)
+}
+
+// The readParseStoreAll calls readParseStore for each of the env-files
+// in order, stops on the first error. Returns ErrNoFiles if there are
+// no env-files.
+func readParseStoreAll(filenames []string, expand, update,
+	forced bool) error {
+	if len(filenames) == 0 {
+		return ErrNoFiles
 	}
 
-	// Define a structure for the result,
-	// which is a parsed line from the env-file.
-	type output struct {
-		expanded bool   // true if the value can be expanded
-		value    string // key value
-		line     line   // raw line object
-		key      string // key name
+	for _, filename := range filenames {
+		err := readParseStore(filename, expand, update, forced)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The parseStore works like readParseStore, but reads the env-file
+// from the r with the options. The name is the name of the env-file
+// for the stats and pprof labels, it's empty if the content isn't
+// read from the file.
+func parseStore(name string, r io.Reader, opts loadOptions) error {
+	start := time.Now()
+	pairs, lines, err := parseReader(name, r, opts.expand, opts.forced)
+	if err != nil {
+		return err
+	}
+
+	return storePairs(name, pairs, lines, start, opts)
+}
+
+// The storePairs applies the pairs parsed from the env-file with the
+// name (see parseStore) and saves the stats of the loading, the lines
+// is the number of the lines read and the start is the time when the
+// reading began.
+func storePairs(name string, pairs []pair, lines int, start time.Time,
+	opts loadOptions) (err error) {
+	// Only the keys with the prefix are applied.
+	if opts.prefix != "" {
+		filtered := make([]pair, 0, len(pairs))
+		for _, item := range pairs {
+			if strings.HasPrefix(item.key, opts.prefix) {
+				filtered = append(filtered, item)
+			}
+		}
+		pairs = filtered
+	}
+
+	labels := pprof.Labels("env.file", name, "env.phase", "apply")
+	pprof.Do(context.Background(), labels, func(context.Context) {
+		err = applyPairs(pairs, opts.expand, opts.update)
+	})
+	if err != nil {
+		return err
+	}
+
+	stats := LoadStats{
+		File:     name,
+		Lines:    lines,
+		Keys:     len(pairs),
+		Duration: time.Since(start),
 	}
+	if opts.expand {
+		for _, item := range pairs {
+			if item.expanded {
+				stats.Expansions++
+			}
+		}
+	}
+	storeLoadStats(stats)
 
-	// We use sync.Map instead of []output with sync.Mutex.
-	var outputs sync.Map // map[int]output
+	return nil
+}
+
+// The pair is a key/value pair parsed from the env-file.
+type pair struct {
+	key      string // key name
+	value    string // key value
+	expanded bool   // true if the value can be expanded
+	tainted  bool   // true if the value is from untrusted source
 
+	expires time.Time // expiration time from the comment, if any
+}
+
+// The readParse reads env-file and parses this one by the key and value
+// without changing the environment. The pairs are returned in the order
+// in which they are written in the file, with the number of lines read.
+//
+// The parsing goroutines are labeled with the env.file and env.phase
+// pprof labels, so they can be found in the CPU and goroutine profiles.
+//
+// The expand and forced arguments have the same meaning
+// as for the readParseStore function.
+func readParse(filename string, expand, forced bool) ([]pair, int, error) {
 	// Try to open env-file in read only mode.
 	file, err := os.OpenFile(filename, os.O_RDONLY, 0)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 	defer file.Close()
 
+	return parseReader(filename, file, expand, forced)
+}
+
+// The parseReader works like readParse, but reads the env-file from
+// the r, the name is the name of the env-file for the pprof labels.
+func parseReader(name string, r io.Reader, expand,
+	forced bool) ([]pair, int, error) {
+	// Read the file line by line. The line number
+	// is the index of the line in the slice.
+	lines := make([]string, 0, 64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	// Check for errors during reading the file.
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	pairs, err := parseLines(name, lines, expand, forced)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pairs, len(lines), nil
+}
+
+// The joinContinued joins the expressions with the unquoted values that
+// end with the backslash (the line continuation) with the next lines
+// that are indented and aren't expressions themselves: the backslash
+// is removed and the leading whitespaces of the next line are trimmed.
+// The joined expression takes the place of its first line and the
+// continuation lines are replaced by the empty lines, so the line
+// numbers are kept. Other lines that end with the backslash (like the
+// Windows path C:\dir\ followed by the next key) are kept as is.
+//
+// Examples:
+//
+//	joinContinued([]string{`A=-Xms512m \`, `  -Xmx2g`, `B=1`})
+//	// []string{`A=-Xms512m -Xmx2g`, ``, `B=1`}
+//	joinContinued([]string{`A=C:\dir\`, `B=1`})
+//	// []string{`A=C:\dir\`, `B=1`}
+func joinContinued(lines []string) []string {
+	var result []string // the copy of the lines, if any continuation
+	for i := 0; i < len(lines); i++ {
+		if !isContinued(lines[i]) || !isUnquotedExpression(lines[i]) ||
+			i+1 == len(lines) || !isContinuation(lines[i+1]) {
+			continue
+		}
+
+		if result == nil {
+			result = append([]string(nil), lines...)
+		}
+
+		start, line := i, lines[i]
+		for isContinued(line) && i+1 < len(lines) &&
+			isContinuation(lines[i+1]) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t")
+			result[i] = ""
+		}
+
+		result[start] = line
+	}
+
+	if result == nil {
+		return lines
+	}
+
+	return result
+}
+
+// The isUnquotedExpression returns true if the line
+// is the expression with the unquoted value.
+func isUnquotedExpression(line string) bool {
+	tmp := keyRgx.FindString(line)
+	if isEmpty(line) || tmp == "" {
+		return false
+	}
+
+	value := strings.TrimSpace(line[len(tmp):])
+	return value != "" && !strings.ContainsRune("'\"`", rune(value[0]))
+}
+
+// The isContinuation returns true if the line can continue the previous
+// line: it's indented and isn't an expression or a comment.
+func isContinuation(line string) bool {
+	return (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) &&
+		!isEmpty(line) && !keyRgx.MatchString(line)
+}
+
+// The isContinued returns true if the line ends with
+// the odd number of backslashes, i.e. it is continued.
+func isContinued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// The parseLines parses the lines of the env-file by the key and value,
+// the name is the name of the env-file for the pprof labels. The pairs
+// are returned in the order in which they are written in the lines.
+func parseLines(name string, lines []string, expand,
+	forced bool) ([]pair, error) {
+	// Define a structure for the result,
+	// which is a parsed line from the env-file.
+	type output struct {
+		parsed bool // true if the line contains an expression
+		pair        // parsed key/value
+	}
+
+	// The results are written into a pre-sized slice by line number,
+	// so each goroutine writes only its own cells and no additional
+	// synchronization is required to save the results.
+	lines = joinContinued(lines)
+	outputs := make([]output, len(lines))
+
 	// Parse env-file using goroutines.
 	// We use errgroup as a better way to group goroutines and context to
 	// stop all goroutines from executing if an error is detected in a file.
-	lines := make(chan line) // channel for lines from env-file
 	ctx, cancel := context.WithCancel(context.Background())
 	eg, ctx := errgroup.WithContext(ctx)
 	defer cancel()
 
-	// Create some goroutines (parallelTasks)
-	// to parsing lines from an env-file.
-	for i := 0; i < parallelTasks; i++ {
-		eg.Go(func() error {
-			for line := range lines {
-				// Ignore empty string or comments.
-				if isEmpty(line.text) {
-					continue
-				}
+	// The parse parses the lines from start to end.
+	parse := func(start, end int) error {
+		for i := start; i < end; i++ {
+			// Stop parsing if an error is detected
+			// in another goroutine.
+			if ctx.Err() != nil {
+				return nil
+			}
 
-				// Parse expression.
-				// The string containing the expression must be of the
-				// format as: [export] KEY=VALUE [# Comment]
-				key, value, err := parseExpression(line.text)
-				if err != nil {
-					if forced {
-						continue // ignore error in the line
-					} else {
-						cancel() // stop other goroutines too
-						return err
-					}
-				}
+			// Ignore empty string or comments.
+			if isEmpty(lines[i]) {
+				continue
+			}
 
-				// Check whether to execute os.Expand only in expand mode,
-				// otherwise set false for all exceptions.
-				expanded := false
-				if expand {
-					expanded = strings.Contains(value, "$")
+			// Parse expression.
+			// The string containing the expression must be of the
+			// format as: [export] KEY=VALUE [# Comment]
+			key, value, err := parseExpression(lines[i])
+			if err != nil {
+				if forced {
+					continue // ignore error in the line
+				} else {
+					cancel() // stop other goroutines too
+					return err
 				}
+			}
 
-				// Save the result.
-				outputs.Store(line.number, output{
-					expanded: expanded,
-					value:    value,
-					line:     line,
+			// Check whether to execute os.Expand only in expand mode,
+			// otherwise set false for all exceptions.
+			expanded := false
+			if expand {
+				expanded = strings.Contains(value, "$")
+			}
+
+			// The expiration time from the comment.
+			var expires time.Time
+			if strings.Contains(lines[i], "expires=") {
+				expires, _ = parseExpiry(lines[i])
+			}
+
+			// Save the result.
+			outputs[i] = output{
+				parsed: true,
+				pair: pair{
 					key:      key,
-				})
+					value:    value,
+					expanded: expanded,
+					expires:  expires,
+				},
 			}
+		}
 
-			return nil
-		})
+		return nil
 	}
 
-	// Read the file line by line and send it to the channel.
-	number := 0 // file line number
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		select {
-		case lines <- line{text: scanner.Text(), number: number}:
-			number++ // increment line number
-		case <-ctx.Done():
-			break // stop reading the file if an error is detected
+	// The goroutines are labeled for the profiler.
+	labels := pprof.Labels("env.file", name, "env.phase", "parse")
+
+	// Split the lines into continuous chunks, one for each
+	// of the goroutines (parallelTasks).
+	chunk := (len(lines) + parallelTasks - 1) / parallelTasks
+	for start := 0; start < len(lines); start += chunk {
+		start, end := start, start+chunk
+		if end > len(lines) {
+			end = len(lines)
 		}
-	}
-	close(lines)
 
-	// Check for errors during reading the file.
-	if err := scanner.Err(); err != nil {
-		cancel()
-		return err
+		eg.Go(func() (err error) {
+			pprof.Do(ctx, labels, func(context.Context) {
+				err = parse(start, end)
+			})
+			return err
+		})
 	}
 
 	// Check for errors during parsing the file.
-	err = eg.Wait()
+	err := eg.Wait()
 	if err != nil && !errors.Is(err, context.Canceled) {
-		return err
+		return nil, err
 	}
 
-	// We know the actual number of lines in the file,
-	// so the map can have the same number of identified records (or less).
-	//
-	// For expanded mode, it is very important to keep the sequence of
-	// strings to load into environment:
-	//
-	// KEY_0=VALUE_0
-	// KEY_1=${KEY_0}7
-	// KEY_0=VALUE_1 # overridden
-	//
-	// In this case, with expanded mode, the value of KEY_0 will be VALUE_1,
-	// but KEY_1 will be VALUE_07, because the value of KEY_0 is
-	// already loaded in the first row and KEY_1 is updated
-	// in the second row.
-	for i := 0; i < number; i++ {
-		o, ok := outputs.Load(i)
-		if !ok {
-			continue
+	// Collect the parsed lines only, keeping their order.
+	pairs := make([]pair, 0, len(outputs))
+	for _, item := range outputs {
+		if item.parsed {
+			pairs = append(pairs, item.pair)
 		}
+	}
 
-		item := o.(output) // convert to output type
-		if _, ok := os.LookupEnv(item.key); update || !ok {
-			if expand && item.expanded {
-				item.value = os.ExpandEnv(item.value)
+	return pairs, nil
+}
+
+// The applyPairs stores the pairs into environment in one pass.
+// If update is true, existing keys are overwritten without checking
+// their presence in the environment, otherwise only new keys are set.
+//
+// For expanded mode, it is very important to keep the sequence of
+// strings to load into environment:
+//
+//	KEY_0=VALUE_0
+//	KEY_1=${KEY_0}7
+//	KEY_0=VALUE_1 # overridden
+//
+// In this case, with expanded mode, the value of KEY_0 will be VALUE_1,
+// but KEY_1 will be VALUE_07, because the value of KEY_0 is
+// already loaded in the first row and KEY_1 is updated
+// in the second row.
+func applyPairs(pairs []pair, expand, update bool) error {
+	// Don't apply anything, even if all keys are already set.
+	if frozen.Load() {
+		return ErrFrozen
+	}
+
+	for _, item := range pairs {
+		// Don't look up the key if it is overwritten anyway.
+		if !update {
+			if _, ok := getenv(item.key); ok {
+				continue
 			}
+		}
 
-			err := os.Setenv(item.key, item.value)
+		if expand && item.expanded {
+			value, err := expandValue(item.value, getenv)
 			if err != nil {
-				return err
+				return &KeyError{Key: item.key, Err: err}
 			}
+			item.value = value
+		}
+
+		if err := setenv(item.key, item.value); err != nil {
+			return err
+		}
+
+		if !item.expires.IsZero() {
+			setExpiry(item.key, item.expires)
+		}
+
+		if item.tainted {
+			setTaint(item.key)
 		}
 	}
 
 	return nil
 }
 
-// The splitN function splits the string at the specified rune separator,
-// ignoring the position of the separator inside of the group:
-// `...`, '...', "..." and (...), {...}, [...].
-//
-// Arguments:
+// The findPrefixNames returns the sorted list of the instance names
+// discovered in the environment by the pattern. The pattern must contain
+// exactly one asterisk (*) that matches the instance name consisting of
+// letters and digits, for example, for the SERVICE_*_ pattern and the
+// SERVICE_A_HOST key the instance name is A.
+//
+// The key must have at least one character after the
+// matched prefix to be taken into account.
+func findPrefixNames(pattern string) ([]string, error) {
+	head, tail, ok := strings.Cut(pattern, "*")
+	if !ok || strings.Contains(tail, "*") {
+		return nil, fmt.Errorf("pattern must contain one asterisk: %s",
+			pattern)
+	}
+
+	// The isNameRune returns true if r can be used in the instance name.
+	isNameRune := func(r rune) bool {
+		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, item := range environ() {
+		key, _, _ := strings.Cut(item, "=")
+		if !strings.HasPrefix(key, head) {
+			continue
+		}
+
+		rest := key[len(head):]
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return !isNameRune(r)
+		})
+		if end <= 0 {
+			continue
+		}
+
+		name := rest[:end]
+		rest = rest[end:]
+		if !strings.HasPrefix(rest, tail) || len(rest) == len(tail) {
+			continue
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// The listSpecials contains the characters with special meaning in the
+// items of the lists: quotes, brackets and the escape character.
+const listSpecials = "\"'`({[)}]\\"
+
+// The splitList function splits the list at the separator, the items
+// can contain the separator:
 //
-//   - str data;
-//   - sep element separator;
-//   - n   the number of strings to be returned by the function.
+//   - the separator (and any special character) escaped by
+//     the backslash is a part of the item, like a\,b;
+//   - the item enclosed in quotes is unquoted, like "a,b" (the quote
+//     inside is escaped by the backslash), the quotes in the middle
+//     of the item group the characters as is;
+//   - the brackets group the characters as is, like (a,b).
 //
-// The n can be any of the following:
-//  1. n is equal to zero (n == 0): The result is nil, i.e, zero
-//     sub strings. An empty list is returned;
-//  2. n is greater than zero (n > 0): At most n sub strings will be
-//     returned and the last string will be the unsplit remainder;
-//  3. n is less than zero (n < 0): All possible substring
-//     will be returned.
+// The backslash before other characters is kept as is, so the paths
+// like C:\dir are not changed. The empty string is the empty list.
 //
 // Examples:
 //
-//	splitN("a,b,c,d", ',', -1)     // ["a", "b", "c", "d"]
-//	splitN("a,(b,c),d", ',', -1)   // ["a", "(b,c)", "d"]
-//	splitN("'a,b',c,d", ',', -1)   // ["'a,b'", "c", "d"]
-//	splitN("a,\"b,c\",d", ',', -1) // ["a", "\"b,c\"", "d"]
-func splitN(str, sep string, n int) (r []string) {
+//	splitList("a,b,c", ",")      // ["a", "b", "c"]
+//	splitList("a\\,b,c", ",")    // ["a,b", "c"]
+//	splitList(`"a,b",c`, ",")    // ["a,b", "c"]
+//	splitList(`a,(b,c),d`, ",")  // ["a", "(b,c)", "d"]
+//	splitList(`C:\dir;D:`, ";") // ["C:\dir", "D:"]
+func splitList(str, sep string) []string {
+	if str == "" {
+		return []string{}
+	}
+
 	var (
-		level int
-		host  rune
-		char  rune
-		tmp   string
-
-		flips    = map[rune]rune{'}': '{', ']': '[', ')': '('}
-		quotes   = "\"'`"
-		brackets = "({["
+		r     = make([]string, 0, strings.Count(str, sep)+1)
+		start int
+		group []byte // stack of the closing characters
 	)
 
-	if n == 0 {
-		return r
-	} else if n == 1 {
-		return []string{str}
+	for i := 0; i < len(str); i++ {
+		char := str[i]
+		switch {
+		case char == '\\' && i+1 < len(str):
+			if sep != "" && strings.HasPrefix(str[i+1:], sep) {
+				i += len(sep)
+			} else {
+				i++
+			}
+		case len(group) != 0 && char == group[len(group)-1]:
+			group = group[:len(group)-1]
+		case len(group) != 0 && strings.ContainsRune("\"'`", rune(
+			group[len(group)-1])):
+			// Brackets and other quotes inside the quotes.
+		case strings.IndexByte("\"'`", char) >= 0:
+			group = append(group, char)
+		case strings.IndexByte("({[", char) >= 0:
+			group = append(group, ")}]"[strings.IndexByte("({[", char)])
+		case len(group) == 0 && sep != "" &&
+			strings.HasPrefix(str[i:], sep):
+			r = append(r, unescapeItem(str[start:i], sep))
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
 	}
 
-	// The contains returns true if all items from the separators
-	// were found in the string and it's all the same.
-	contains := func(str string, separators ...rune) bool {
-		last := -1
-		for _, sep := range separators {
-			ir := strings.IndexRune(str, sep)
-			if ir < 0 || (last >= 0 && last != ir) {
-				return false
-			}
-			last = ir
+	return append(r, unescapeItem(str[start:], sep))
+}
+
+// The unescapeItem function returns the item of the list
+// without quotes around it and escape characters.
+func unescapeItem(item, sep string) string {
+	if len(item) >= 2 && strings.IndexByte("\"'`", item[0]) >= 0 &&
+		item[len(item)-1] == item[0] {
+		value, err := unescapeQuoted(item, rune(item[0]))
+		if err == nil {
+			return value
 		}
+	}
 
-		return true
+	if !strings.Contains(item, "\\") {
+		return item
 	}
 
-	// Allocate the max memory size for storage all fields.
-	r = make([]string, 0, strings.Count(str, ",")+1)
-
-	// Split value.
-	for i := 0; i < utf8.RuneCountInString(str); i++ {
-		char = rune(str[i])
-		if level == 0 && contains(quotes+brackets, char) {
-			host, level = char, level+1
-		} else if contains(quotes, host, char) {
-			level, host = 0, 0
-		} else if contains(brackets, host, flips[char]) {
-			level--
-			if level <= 0 {
-				level, host = 0, 0
-			}
-		} else if level == 0 {
-			endpoint := i + utf8.RuneCountInString(sep)
-			if endpoint > len(str) {
-				endpoint = len(str)
+	var sb strings.Builder
+	sb.Grow(len(item))
+	for i := 0; i < len(item); i++ {
+		if item[i] == '\\' && i+1 < len(item) {
+			if sep != "" && strings.HasPrefix(item[i+1:], sep) {
+				sb.WriteString(sep)
+				i += len(sep)
+				continue
+			} else if strings.IndexByte(listSpecials, item[i+1]) >= 0 {
+				i++
 			}
+		}
+		sb.WriteByte(item[i])
+	}
 
-			if sep == str[i:endpoint] {
-				i += utf8.RuneCountInString(sep) - 1
-				r = append(r, tmp)
-				tmp = ""
-				if n > 0 && n == len(r)+1 {
-					tmp = str[endpoint:]
-					break
-				}
+	return sb.String()
+}
+
+// The joinList function joins the items of the list by the separator,
+// the items are escaped to be split by the splitList back as is.
+//
+// Examples:
+//
+//	joinList([]string{"a", "b"}, ",")   // `a,b`
+//	joinList([]string{"a,b", "c"}, ",") // `a\,b,c`
+//	joinList([]string{"(a", "b"}, ",")  // `\(a,b`
+func joinList(items []string, sep string) string {
+	escaped := make([]string, len(items))
+	for i, item := range items {
+		escaped[i] = escapeItem(item, sep)
+	}
+
+	return strings.Join(escaped, sep)
+}
+
+// The escapeItem function escapes the item of the list. The separator
+// and the backslashes before the special characters are always escaped,
+// the quotes and brackets are escaped only if they change the item.
+func escapeItem(item, sep string) string {
+	if !strings.ContainsAny(item, listSpecials) &&
+		(sep == "" || !strings.Contains(item, sep)) {
+		return item
+	}
+
+	escape := func(all bool) string {
+		var sb strings.Builder
+		sb.Grow(len(item) + 4)
+		for i := 0; i < len(item); i++ {
+			char := item[i]
+			switch {
+			case sep != "" && strings.HasPrefix(item[i:], sep):
+				sb.WriteByte('\\')
+				sb.WriteString(sep)
+				i += len(sep) - 1
 				continue
+			case char == '\\':
+				// The backslash before the usual character is kept as is.
+				next := i + 1
+				if all || next == len(item) ||
+					strings.IndexByte(listSpecials, item[next]) >= 0 ||
+					(sep != "" && strings.HasPrefix(item[next:], sep)) {
+					sb.WriteByte('\\')
+				}
+			case all && strings.IndexByte(listSpecials, char) >= 0:
+				sb.WriteByte('\\')
 			}
+			sb.WriteByte(char)
 		}
 
-		tmp += string(char)
+		return sb.String()
 	}
 
-	// Add last piece to the result.
-	if len(tmp) != 0 || string(char) == sep {
-		r = append(r, tmp)
+	// The item is checked with the next one to be sure
+	// that all groups are closed.
+	result := escape(false)
+	if r := splitList(result+sep+"x", sep); sep == "" ||
+		len(r) != 2 || r[0] != item {
+		result = escape(true)
 	}
 
-	return
+	return result
 }
 
-// The removeInlineComment function removes the comment in the env-string.
-// It removes comments starting with the hash symbol (#) if they are not
-// enclosed in quotes (single, double, or backquote).
+// The unescapeQuoted function extracts the value enclosed in quotes.
+// The str must begin with the quote character, which may be a single
+// quote ('), a double quote (") or a backquote (`).
 //
-// The value for quote can be as: single quote ('),
-// double quote ("), and backquote (`).
-func removeInlineComment(str string, q rune) string {
-	// If the comment isn't in the string.
-	// The environment file uses the lattice symbol (#) as a comment.
-	if !strings.Contains(str, "#") {
-		return str
-	}
+// The string is processed by a simple state machine: escaped quotes
+// (like \" inside "...") are replaced by the quote itself, all other
+// characters are copied as is. After the closing quote, only spaces
+// and an inline comment starting with the hash symbol (#) are allowed.
+//
+// Returns an error if the closing quote is missing or if there are
+// unexpected characters after it.
+//
+// Examples:
+//
+//	unescapeQuoted(`"abc"`, '"')              // `abc`
+//	unescapeQuoted(`"a \"b\" c" # note`, '"') // `a "b" c`
+//	unescapeQuoted(`'a # b'`, '\'')            // `a # b`
+//	unescapeQuoted(`"abc`, '"')               // error
+func unescapeQuoted(str string, quote rune) (string, error) {
+	const (
+		inside  = iota // between the quotes
+		escaped        // after the backslash between the quotes
+		outside        // after the closing quote
+	)
 
 	var (
-		quote  = string(q)     // quote as string
-		escape = "\\" + quote  // escaped quote
-		inside bool            // inside of the quote
-		result strings.Builder // result string
+		result strings.Builder
+		state  = inside
 	)
 
-	// Remove the comment in the string.
-	for i := 0; i < len(str); i++ {
-		ch := str[i]
+	if len(str) == 0 || rune(str[0]) != quote {
+		return "", fmt.Errorf("incorrect value: %s", str)
+	}
 
-		switch {
-		case ch == byte(q):
-			if inside {
-				// Check if the quote is escaped.
-				if i > 0 && str[i-1] != '\\' {
-					inside = false
-				}
+	result.Grow(len(str))
+	for i, char := range str[1:] {
+		switch state {
+		case inside:
+			if char == '\\' {
+				state = escaped
+			} else if char == quote {
+				state = outside
 			} else {
-				inside = true
+				result.WriteRune(char)
+			}
+		case escaped:
+			// Only the escaped quote is converted,
+			// other escape sequences are kept as is.
+			if char != quote {
+				result.WriteRune('\\')
+			}
+			result.WriteRune(char)
+			state = inside
+		case outside:
+			// Only spaces and inline comment are allowed
+			// after the closing quote.
+			if char == '#' {
+				return result.String(), nil
+			} else if !unicode.IsSpace(char) {
+				return "", fmt.Errorf("incorrect value: %s", str[i+1:])
 			}
-			result.WriteByte(ch)
-		case ch == '#' && !inside:
-			return strings.TrimSpace(result.String())
-		case ch == '\\' && inside && i+1 < len(str) && str[i+1] == byte(q):
-			// Escaping quotes inside a quoted line.
-			result.WriteString(escape)
-			i++
-		default:
-			result.WriteByte(ch)
 		}
 	}
 
-	return result.String()
+	if state != outside {
+		return "", fmt.Errorf("incorrect value: %s", str)
+	}
+
+	return result.String(), nil
 }
 
 // The parseExpression function breaks an expression into a key and value,
@@ -557,27 +941,50 @@ func parseExpression(exp string) (key, value string, err error) {
 		chunks = strings.Split(chunks[0], " ")
 		value = strings.TrimSpace(chunks[0])
 	} else if quote != 0 {
-		// A unique marker for temporary replacement of quotation marks.
-		buffer := make([]byte, 8)
-		rand.Read(buffer)
-		marker := "<::" + hex.EncodeToString(buffer) + "::>"
-
-		// Replace escaped quotes, remove comment in the string,
-		// check begin- and end- quotes and back escaped quotes.
-		value = strings.Replace(value, fmt.Sprintf("\\%c", quote), marker, -1)
-		value = removeInlineComment(value, quote)
-
-		// Check begin- and end- quotes.
-		if strings.Count(value, string(quote))%2 != 0 {
-			err = fmt.Errorf("incorrect value: %s", value)
-			return
+		// Remove begin- and end- quotes, inline comment
+		// and change escaped quotes like `\"` to `"`.
+		value, err = unescapeQuoted(value, quote)
+	}
+
+	return
+}
+
+// The inlineComment returns the inline comment of the expression with
+// the spaces before it, like ` # comment` for the `KEY=value # comment`,
+// or an empty string if there is no comment. The hash signs inside
+// the quoted value aren't the comment.
+func inlineComment(exp string) string {
+	tmp := keyRgx.FindString(exp)
+	if isEmpty(exp) || tmp == "" {
+		return ""
+	}
+
+	// Find the end of the quoted value.
+	value, end := exp[len(tmp):], 0
+	if value != "" && strings.ContainsRune("'\"`", rune(value[0])) {
+		escaped := false
+		for i := 1; i < len(value) && end == 0; i++ {
+			switch {
+			case escaped:
+				escaped = false
+			case value[i] == '\\':
+				escaped = true
+			case value[i] == value[0]:
+				end = i + 1
+			}
 		}
 
-		// Remove begin- and end- quotes
-		// ... change `\"` and `\'` to `"` and `'`.
-		value = value[1 : len(value)-1]
-		value = strings.Replace(value, marker, string(quote), -1)
+		if end == 0 {
+			return "" // unclosed quote
+		}
 	}
 
-	return
+	pos := strings.IndexRune(value[end:], '#')
+	if pos == -1 {
+		return ""
+	}
+
+	// Keep the spaces before the hash sign.
+	pos += end
+	return value[len(strings.TrimRight(value[:pos], " \t")):]
 }
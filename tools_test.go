@@ -236,39 +236,226 @@ func TestReadParseStoreUpdate(t *testing.T) {
 	}
 }
 
-// TestSplitN tests splits the string at the specified rune-marker ignoring
-// the position of the marker inside of the group: `...`, '...', "..."
-// and (...), {...}, [...].
-func TestSplitN(t *testing.T) {
+// TestSplitList tests splitting of the lists with escaped items.
+func TestSplitList(t *testing.T) {
 	tests := []struct {
-		n      int
 		value  string
+		sep    string
 		result []string
 	}{
-		{-1, "a,b,c", []string{"a", "b", "c"}},
-		{-1, "a,,c", []string{"a", "", "c"}},
-		{-1, "a,,", []string{"a", "", ""}},
-		{-1, "a,(b,c),d", []string{"a", "(b,c)", "d"}},
-		{-1, "a,\"b,c\",d", []string{"a", "\"b,c\"", "d"}},
-		{-1, "a,'b,c',d", []string{"a", "'b,c'", "d"}},
-		{-1, "a,`b,c`,d", []string{"a", "`b,c`", "d"}},
-		{-1, "a,b,c,d", []string{"a", "b", "c", "d"}},
-		{0, "a,b,c,d", []string{}},
-		{1, "a,b,c,d", []string{"a,b,c,d"}},
-		{2, "a,b,c,d", []string{"a", "b,c,d"}},
-		{3, "a,b,c,d", []string{"a", "b", "c,d"}},
-		{4, "a,b,c,d", []string{"a", "b", "c", "d"}},
-		{5, "a,b,c,d", []string{"a", "b", "c", "d"}},
-		{3, "a_b_c,, ,", []string{"a_b_c", "", " ,"}},
-		{-1, "a_b_c,, ,", []string{"a_b_c", "", " ", ""}},
+		{"", ",", []string{}},
+		{"a,b,c", ",", []string{"a", "b", "c"}},
+		{"a,,c,", ",", []string{"a", "", "c", ""}},
+		{`a\,b,c`, ",", []string{"a,b", "c"}},
+		{`"a,b",c`, ",", []string{"a,b", "c"}},
+		{`'a "b" c',d`, ",", []string{`a "b" c`, "d"}},
+		{`"a\"b",c`, ",", []string{`a"b`, "c"}},
+		{`a,(b,c),d`, ",", []string{"a", "(b,c)", "d"}},
+		{`a,{b,[c,d]},e`, ",", []string{"a", "{b,[c,d]}", "e"}},
+		{`a="b,c",d`, ",", []string{`a="b,c"`, "d"}},
+		{`\(a,b`, ",", []string{"(a", "b"}},
+		{`a\\,b`, ",", []string{`a\`, "b"}},
+		{`C:\dir;D:\`, ";", []string{`C:\dir`, `D:\`}},
+		{`a\::b::c`, "::", []string{"a::b", "c"}},
+	}
+
+	for i, s := range tests {
+		r := splitList(s.value, s.sep)
+		if fmt.Sprintf("%q", r) != fmt.Sprintf("%q", s.result) {
+			t.Errorf("test %d is failed, expected %q but %q",
+				i, s.result, r)
+		}
+	}
+}
+
+// TestJoinList tests that the joined lists are split back as is.
+func TestJoinList(t *testing.T) {
+	tests := []struct {
+		items  []string
+		sep    string
+		result string
+	}{
+		{[]string{"a", "b"}, ",", "a,b"},
+		{[]string{"a,b", "c"}, ",", `a\,b,c`},
+		{[]string{"(a", "b"}, ",", `\(a,b`},
+		{[]string{"(a,b)", "c"}, ",", `(a\,b),c`},
+		{[]string{"it's", "ok"}, " ", `it\'s ok`},
+		{[]string{`"a"`, "b"}, ",", `\"a\",b`},
+		{[]string{`a\`, `C:\dir`}, ",", `a\\,C:\dir`},
+		{[]string{"", "a::b"}, "::", `::a\::b`},
+	}
+
+	for i, s := range tests {
+		r := joinList(s.items, s.sep)
+		if r != s.result {
+			t.Errorf("test %d is failed, expected `%s` but `%s`",
+				i, s.result, r)
+		}
+
+		items := splitList(r, s.sep)
+		if fmt.Sprintf("%q", items) != fmt.Sprintf("%q", s.items) {
+			t.Errorf("test %d is failed, expected %q but %q",
+				i, s.items, items)
+		}
+	}
+}
+
+// TestUnescapeQuoted tests extracting of the quoted value.
+func TestUnescapeQuoted(t *testing.T) {
+	tests := []struct {
+		value  string
+		quote  rune
+		result string
+		err    bool
+	}{
+		{`"abc"`, '"', "abc", false},
+		{`"abc"   `, '"', "abc", false},
+		{`"a \"b\" c" # comment`, '"', `a "b" c`, false},
+		{`"a # b" # comment`, '"', "a # b", false},
+		{`'a \'b\' c'`, '\'', "a 'b' c", false},
+		{"`a \\`b\\` c`", '`', "a `b` c", false},
+		{`"a \n b"`, '"', `a \n b`, false},
+		{`"a 'b' c"`, '"', "a 'b' c", false},
+		{`"<::marker::>"`, '"', "<::marker::>", false},
+		{`""`, '"', "", false},
+		{`"abc`, '"', "", true},
+		{`"abc\"`, '"', "", true},
+		{`"abc" def`, '"', "", true},
+		{`abc`, '"', "", true},
+		{``, '"', "", true},
 	}
 
 	for i, s := range tests {
-		tmp := splitN(s.value, ",", s.n)
-		r1, _ := sts(tmp, ":")
-		r2, _ := sts(s.result, ":")
-		if r1 != r2 {
-			t.Errorf("test %d is failed, expected %v but %v", i, s.result, r1)
+		r, err := unescapeQuoted(s.value, s.quote)
+		if s.err {
+			if err == nil {
+				t.Errorf("test %d is failed, expected an error", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test %d is failed, unexpected error: %v", i, err)
+		} else if r != s.result {
+			t.Errorf("test %d is failed, expected `%s` but `%s`", i, s.result, r)
+		}
+	}
+}
+
+// TestInlineComment tests extracting of the inline comment.
+func TestInlineComment(t *testing.T) {
+	tests := []struct {
+		value  string
+		result string
+	}{
+		{`A=abc`, ""},
+		{`A=abc # comment`, " # comment"},
+		{`A=abc#comment`, "#comment"},
+		{`export A=abc  # expires=2025-01-01`, "  # expires=2025-01-01"},
+		{`A="a # b"`, ""},
+		{`A="a # b" # comment`, " # comment"},
+		{`A="a \" # b" # comment`, " # comment"},
+		{`A='note #1 expires=2020-01-01'`, ""},
+		{`A="abc # b`, ""},
+		{`# A=abc # comment`, ""},
+	}
+
+	for i, s := range tests {
+		if r := inlineComment(s.value); r != s.result {
+			t.Errorf("test %d is failed, expected `%s` but `%s`",
+				i, s.result, r)
+		}
+	}
+}
+
+// TestReadParseStoreOrder tests that the lines of the env-file are
+// applied in the order in which they are written in the file.
+func TestReadParseStoreOrder(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "KEY_%d=value_%d\n", i, i)
+		fmt.Fprintf(&sb, "REF_%d=${KEY_%d}\n", i, i)
+		fmt.Fprintf(&sb, "KEY_%d=overridden_%d\n", i, i)
+	}
+
+	filename := t.TempDir() + "/.env"
+	if err := os.WriteFile(filename, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	if err := readParseStore(filename, true, true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		key, ref := fmt.Sprintf("KEY_%d", i), fmt.Sprintf("REF_%d", i)
+		if v, e := os.Getenv(key), fmt.Sprintf("overridden_%d", i); v != e {
+			t.Errorf("incorrect value for `%s` key: `%s`!=`%s`", key, e, v)
+		}
+		if v, e := os.Getenv(ref), fmt.Sprintf("value_%d", i); v != e {
+			t.Errorf("incorrect value for `%s` key: `%s`!=`%s`", ref, e, v)
+		}
+	}
+}
+
+// TestJoinContinued tests joining of the continued lines.
+func TestJoinContinued(t *testing.T) {
+	tests := []struct {
+		lines  []string
+		result []string
+	}{
+		{[]string{"A=1", "B=2"}, []string{"A=1", "B=2"}},
+		{
+			[]string{`A=-Xms512m \`, "  -Xmx2g", "B=2"},
+			[]string{"A=-Xms512m -Xmx2g", "", "B=2"},
+		},
+		{
+			[]string{`A=a,\`, `  b,\`, "\tc", "B=2"},
+			[]string{"A=a,b,c", "", "", "B=2"},
+		},
+		{
+			[]string{`A="a \`, `  b" # comment`},
+			[]string{`A="a \`, `  b" # comment`},
+		},
+		{[]string{`A=C:\dir\`, "B=2"}, []string{`A=C:\dir\`, "B=2"}},
+		{[]string{`A=C:\dir\`, "  B=2"}, []string{`A=C:\dir\`, "  B=2"}},
+		{[]string{`A=C:\dir\`, "b"}, []string{`A=C:\dir\`, "b"}},
+		{[]string{`A=C:\dir\\`, "  b"}, []string{`A=C:\dir\\`, "  b"}},
+		{[]string{`# comment \`, "  b"}, []string{`# comment \`, "  b"}},
+		{[]string{`A=1\`}, []string{`A=1\`}},
+	}
+
+	for i, s := range tests {
+		r := joinContinued(s.lines)
+		if fmt.Sprintf("%q", r) != fmt.Sprintf("%q", s.result) {
+			t.Errorf("test %d is failed, expected %q but %q",
+				i, s.result, r)
+		}
+	}
+}
+
+// TestReadParseStoreContinued tests the line continuation in the env-file.
+func TestReadParseStoreContinued(t *testing.T) {
+	data := "JAVA_OPTS=-Xms512m \\\n    -Xmx2g\n# options\n" +
+		"FLAGS=a,\\\n\tb,\\\n\tc\nNEXT=1\n" +
+		"WIN_DIR=C:\\dir\\\nNEXT_DIR=D:\\\n"
+
+	os.Clearenv()
+	if err := LoadBytes([]byte(data), true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"JAVA_OPTS": "-Xms512m -Xmx2g",
+		"FLAGS":     "a,b,c",
+		"NEXT":      "1",
+		"WIN_DIR":   `C:\dir\`, // isn't continued
+		"NEXT_DIR":  `D:\`,
+	}
+	for key, value := range tests {
+		if v := Get(key); v != value {
+			t.Errorf("expected `%s` but `%s`", value, v)
 		}
 	}
 }
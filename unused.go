@@ -0,0 +1,99 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Unused returns the sorted keys of the environment with the prefix that
+// aren't consumed by any configuration object: neither by the objs
+// (unmarshaled with the prefix) nor by the objects registered by the
+// Register function (with their own prefixes). The key is consumed if
+// it's the key or the fallback name of a field, or the key of an entry
+// of the map of structures. It helps to find the dead configuration
+// left in the deployments.
+//
+// Returns an error if any object isn't a structure (or pointer to
+// structure) or has a field with an invalid key name.
+//
+// # Examples
+//
+//	type Config struct {
+//		Host string `env:"HOST"`
+//		Port int    `env:"PORT"`
+//	}
+//
+//	// APP_HOST=localhost, APP_PORT=8080, APP_DEBUG=true
+//	keys, err := env.Unused("APP_", &Config{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(keys) // [APP_DEBUG]
+func Unused(prefix string, objs ...interface{}) ([]string, error) {
+	registryMu.Lock()
+	consumers := make([]registration, 0, len(registry)+len(objs))
+	consumers = append(consumers, registry...)
+	registryMu.Unlock()
+
+	for _, obj := range objs {
+		consumers = append(consumers, registration{prefix, obj})
+	}
+
+	used := make(map[string]bool)
+	var maps [][]string // prefix and field keys of the maps of structures
+	for _, c := range consumers {
+		err := walkFields(c.prefix, reflect.TypeOf(c.obj),
+			func(fi fieldInfo) error {
+				if !isStructMap(fi.field.Type) {
+					used[fi.tg.key] = true
+					for _, name := range fi.tg.alias {
+						used[name] = true
+					}
+					return nil
+				}
+
+				keys, err := mapFieldKeys(fi.field.Type)
+				maps = append(maps, append([]string{fi.tg.key + "_"},
+					keys...))
+				return err
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []string
+	for _, item := range Environ() {
+		key, _, _ := strings.Cut(item, "=")
+		if !strings.HasPrefix(key, prefix) || used[key] ||
+			isMapEntryKey(key, maps) {
+			continue
+		}
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// The isMapEntryKey returns true if the key is the key of an entry
+// of the map of structures, like DB_MAIN_HOST. Each item of the maps
+// is the prefix of the map followed by the keys of the structure.
+func isMapEntryKey(key string, maps [][]string) bool {
+	for _, m := range maps {
+		rest, ok := strings.CutPrefix(key, m[0])
+		if !ok {
+			continue
+		}
+
+		for _, k := range m[1:] {
+			name, ok := strings.CutSuffix(rest, "_"+k)
+			if ok && name != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestUnused tests Unused function.
+func TestUnused(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST"`
+	}
+
+	type config struct {
+		Host  string        `env:"HOST|SERVER"`
+		Port  int           `env:"PORT"`
+		DBs   map[string]db `env:"DB"`
+		Debug bool          `env:"DEBUG"`
+	}
+
+	type logger struct {
+		Level string `env:"LEVEL"`
+	}
+
+	defer func(r []registration) { registry = r }(registry)
+	registry = nil
+
+	os.Clearenv()
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("APP_SERVER", "localhost") // fallback name
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_DB_MAIN_HOST", "db")
+	os.Setenv("APP_DB_MAIN_USER", "root") // unknown field
+	os.Setenv("APP_TIMEOUT", "5s")
+	os.Setenv("APP_LOG_LEVEL", "debug")
+	os.Setenv("OTHER", "value") // without prefix
+
+	keys, err := Unused("APP_", &config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "APP_DB_MAIN_USER APP_LOG_LEVEL APP_TIMEOUT"
+	if v := strings.Join(keys, " "); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// The keys of the registered objects are consumed.
+	if err := Register("APP_LOG_", &logger{}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err = Unused("APP_", config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "APP_DB_MAIN_USER APP_TIMEOUT"
+	if v := strings.Join(keys, " "); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// Incorrect object.
+	if _, err := Unused("APP_", 5); err == nil {
+		t.Error("an error is expected for the incorrect object")
+	}
+}
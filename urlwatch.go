@@ -0,0 +1,371 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The urlWatchTimeout is the timeout of the request of the default
+// client of the HTTPSource and URLWatcher.
+const urlWatchTimeout = 10 * time.Second
+
+// HTTPOptions are the options of the requests of the env-file served
+// over HTTP (see HTTPSource and NewURLWatcher).
+type HTTPOptions struct {
+	// Client is the client of the requests, the default client
+	// with the timeout of 10 seconds is used if it's nil.
+	Client *http.Client
+
+	// TLS is the TLS configuration of the default client, like the
+	// certificates of the private CA or the client certificates for
+	// the mutual TLS. It's ignored if the Client is set.
+	TLS *tls.Config
+
+	// Header contains the headers of the requests,
+	// like Authorization with the token.
+	Header http.Header
+
+	// Retry is the policy of retrying the failed requests,
+	// one attempt is made if it's zero.
+	Retry RetryPolicy
+}
+
+// The httpContent is the parsed content of the env-file.
+type httpContent struct {
+	pairs  []pair            // parsed expressions
+	lines  int               // number of lines read
+	hash   [sha256.Size]byte // hash of the content
+	values map[string]string // values of the keys, with expanded variables
+}
+
+// The httpSource is the Source of the env-file served over HTTP.
+type httpSource struct {
+	url    string
+	client *http.Client
+	header http.Header
+	retry  RetryPolicy
+
+	mu       sync.Mutex
+	etag     string       // ETag of the last response
+	modified string       // Last-Modified of the last response
+	content  *httpContent // last parsed content, nil before the fetch
+}
+
+// HTTPSource returns the Source of the env-file served over HTTP (like
+// the centralized configuration service). The file is requested and
+// parsed on the first lookup, the variables like ${var} or $var are
+// expanded by the values from the file itself and from the environment.
+// The environment isn't changed. The source implements the KeyLister
+// interface.
+//
+// The failed requests are retried by the policy of the options and
+// the error wraps ErrSourceUnavailable if all attempts fail, the file
+// will be requested again on the next lookup.
+//
+// # Examples
+//
+//	src := env.HTTPSource("https://config/app.env", env.HTTPOptions{
+//		Header: http.Header{"Authorization": {"Bearer " + token}},
+//		Retry:  env.RetryPolicy{Attempts: 3, Backoff: time.Second},
+//	})
+//
+//	if err := env.UnmarshalSource(ctx, src, "APP_", &cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func HTTPSource(url string, opts HTTPOptions) Source {
+	return newHTTPSource(url, opts)
+}
+
+// The newHTTPSource returns the httpSource with the options.
+func newHTTPSource(url string, opts HTTPOptions) *httpSource {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: urlWatchTimeout}
+		if opts.TLS != nil {
+			client.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: opts.TLS,
+			}
+		}
+	}
+
+	return &httpSource{
+		url:    url,
+		client: client,
+		header: opts.Header,
+		retry:  opts.Retry,
+	}
+}
+
+// Lookup returns the value of the key from the env-file.
+func (s *httpSource) Lookup(ctx context.Context,
+	key string) (string, bool, error) {
+	content, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := content.values[key]
+	return value, ok, nil
+}
+
+// Keys returns the keys of the env-file.
+func (s *httpSource) Keys(ctx context.Context) ([]string, error) {
+	content, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(content.values))
+	for key := range content.values {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// The load returns the content of the env-file,
+// it's requested if it isn't requested yet.
+func (s *httpSource) load(ctx context.Context) (*httpContent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	content := s.content
+	s.mu.Unlock()
+
+	if content != nil {
+		return content, nil
+	}
+
+	return s.fetch(ctx)
+}
+
+// The fetch requests the env-file with the conditional request and
+// returns its content: the server that supports the ETag or
+// Last-Modified headers responds with the status 304 Not Modified
+// while the content is the same, so the previous content is returned.
+// The content is kept only if it's parsed successfully.
+func (s *httpSource) fetch(ctx context.Context) (*httpContent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		mu       sync.Mutex
+		data     []byte
+		modified bool
+		header   http.Header
+	)
+
+	// The previous content is required to use the 304 response.
+	var etag, lastModified string
+	if s.content != nil {
+		etag, lastModified = s.etag, s.modified
+	}
+
+	// The result of the attempt cut off by the timeout is ignored.
+	err := Retry(ctx, s.retry, func(ctx context.Context) error {
+		d, m, h, err := s.request(ctx, etag, lastModified)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil && ctx.Err() == nil {
+			data, modified, header = d, m, h
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !modified && s.content != nil {
+		return s.content, nil
+	}
+
+	pairs, lines, err := parseReader(s.url, bytes.NewReader(data),
+		true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := expandPairs(pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.etag = header.Get("ETag")
+	s.modified = header.Get("Last-Modified")
+	s.content = &httpContent{
+		pairs:  pairs,
+		lines:  lines,
+		hash:   sha256.Sum256(data),
+		values: values,
+	}
+
+	return s.content, nil
+}
+
+// The request performs one request of the env-file with the conditional
+// headers (if they aren't empty). The boolean is false if the server
+// responds with the status 304 Not Modified.
+func (s *httpSource) request(ctx context.Context, etag,
+	modified string) ([]byte, bool, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	for key, values := range s.header {
+		req.Header[key] = values
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, false, resp.Header, nil
+	case http.StatusOK:
+	default:
+		return nil, false, nil, fmt.Errorf("%s: %s", s.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	return data, true, resp.Header, nil
+}
+
+// URLWatcher polls the env-file served over HTTP (like the centralized
+// configuration service) and applies it to the environment when its
+// content changes. The requests are made by the HTTPSource, so the
+// conditional requests are used: the server that supports the ETag or
+// Last-Modified headers responds with the status 304 Not Modified while
+// the content is the same, so the content is transferred and parsed
+// only when it changes. The content without these headers is compared
+// by the hash.
+type URLWatcher struct {
+	src      *httpSource
+	interval time.Duration
+	onChange func()
+
+	mu      sync.Mutex
+	hash    [sha256.Size]byte // hash of the applied content
+	applied bool              // true if the content has been applied
+	err     error             // error of the last poll
+}
+
+// NewURLWatcher returns the watcher of the env-file by the URL. The
+// interval is the interval of the polling (one second if it's zero),
+// the onChange is called after the changed content is applied to the
+// environment (the existing keys are updated and the variables like
+// ${var} are expanded, as the Update function does), so it's the same
+// callback as for the FileWatcher. The requests are made with the
+// options (see HTTPSource), the stats of the applied content are
+// available by the LastLoadStats.
+//
+// # Examples
+//
+//	w := env.NewURLWatcher("https://config/app.env", 10*time.Second,
+//		func() { r.Trigger() }, // see Reloader
+//		env.HTTPOptions{
+//			Header: http.Header{"Authorization": {"Bearer " + token}},
+//		})
+//
+//	go w.Run(ctx)
+func NewURLWatcher(url string, interval time.Duration, onChange func(),
+	opts HTTPOptions) *URLWatcher {
+	if interval <= 0 {
+		interval = defPollInterval
+	}
+
+	return &URLWatcher{
+		src:      newHTTPSource(url, opts),
+		interval: interval,
+		onChange: onChange,
+	}
+}
+
+// Poll requests the env-file once and applies it if its content has
+// changed since the last poll. Returns true if the content is applied.
+func (w *URLWatcher) Poll(ctx context.Context) (bool, error) {
+	w.mu.Lock()
+	changed, err := w.poll(ctx)
+	w.err = err
+	w.mu.Unlock()
+
+	if changed && w.onChange != nil {
+		w.onChange()
+	}
+
+	return changed, err
+}
+
+// The poll performs the polling, the lock must be held.
+func (w *URLWatcher) poll(ctx context.Context) (bool, error) {
+	start := time.Now()
+	content, err := w.src.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if w.applied && content.hash == w.hash {
+		return false, nil
+	}
+
+	opts := loadOptions{expand: true, update: true}
+	err = storePairs(w.src.url, content.pairs, content.lines, start, opts)
+	if err != nil {
+		return false, err
+	}
+	w.hash, w.applied = content.hash, true
+
+	return true, nil
+}
+
+// Run polls the env-file periodically until the context is canceled and
+// returns the error of the context. The first poll is performed at once.
+// The polling continues after errors, the last one is returned by Err.
+func (w *URLWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Err returns the error of the last poll, nil if it succeeded.
+func (w *URLWatcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
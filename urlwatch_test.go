@@ -0,0 +1,169 @@
+package env
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestURLWatcher tests URLWatcher with the conditional requests.
+func TestURLWatcher(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		content     = "HOST=localhost\nPORT=8080\n"
+		version     = 1
+		notModified int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		etag := `"v` + string(rune('0'+version)) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	changes := 0
+	w := NewURLWatcher(srv.URL, 0, func() { changes++ }, HTTPOptions{})
+	ctx := context.Background()
+
+	os.Clearenv()
+	if ok, err := w.Poll(ctx); err != nil || !ok {
+		t.Fatalf("expected first change but %v, %v", ok, err)
+	}
+
+	if v := Get("PORT"); v != "8080" || changes != 1 {
+		t.Errorf("expected `8080` but `%s` (%d changes)", v, changes)
+	}
+
+	if s := LastLoadStats(); s.File != srv.URL || s.Keys != 2 {
+		t.Errorf("incorrect stats: %+v", s)
+	}
+
+	// The content isn't changed.
+	if ok, err := w.Poll(ctx); err != nil || ok || notModified != 1 {
+		t.Errorf("expected not modified but %v, %v, %d", ok, err, notModified)
+	}
+
+	// The content is changed.
+	mu.Lock()
+	content, version = "HOST=localhost\nPORT=${HOST}:9090\n", 2
+	mu.Unlock()
+
+	if ok, err := w.Poll(ctx); err != nil || !ok {
+		t.Fatalf("expected change but %v, %v", ok, err)
+	}
+
+	if v := Get("PORT"); v != "localhost:9090" || changes != 2 {
+		t.Errorf("expected `localhost:9090` but `%s` (%d changes)",
+			v, changes)
+	}
+
+	// The new version with the same content.
+	mu.Lock()
+	version = 3
+	mu.Unlock()
+
+	if ok, err := w.Poll(ctx); err != nil || ok || changes != 2 {
+		t.Errorf("expected no change but %v, %v", ok, err)
+	}
+
+	// The invalid content isn't applied.
+	mu.Lock()
+	content, version = "PORT=1\nINVALID LINE\n", 4
+	mu.Unlock()
+
+	if _, err := w.Poll(ctx); err == nil || w.Err() == nil {
+		t.Error("expected an error for invalid content")
+	}
+
+	if v := Get("PORT"); v != "localhost:9090" {
+		t.Errorf("expected `localhost:9090` but `%s`", v)
+	}
+
+	// The server error.
+	srv.Close()
+	if _, err := w.Poll(ctx); err == nil {
+		t.Error("expected an error for closed server")
+	}
+}
+
+// TestHTTPSource tests HTTPSource with the options.
+func TestHTTPSource(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// The first request fails.
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte("DB_MAIN_HOST=db1\nDB_LOGS_HOST=${DB_MAIN_HOST}\n"))
+	}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	src := HTTPSource(srv.URL, HTTPOptions{
+		TLS:    &tls.Config{RootCAs: pool},
+		Header: http.Header{"Authorization": {"Bearer token"}},
+		Retry:  RetryPolicy{Attempts: 2},
+	})
+
+	var cfg struct {
+		Databases map[string]struct {
+			Host string `env:"HOST"`
+		} `env:"DB"`
+	}
+
+	os.Clearenv()
+	ctx := context.Background()
+	if err := UnmarshalSource(ctx, src, "", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Databases) != 2 || cfg.Databases["LOGS"].Host != "db1" {
+		t.Errorf("incorrect values: %v", cfg.Databases)
+	}
+
+	// The content is requested once.
+	if requests != 2 || len(os.Environ()) != 0 {
+		t.Errorf("expected 2 requests but %d", requests)
+	}
+
+	// Without the token.
+	src = HTTPSource(srv.URL, HTTPOptions{Client: srv.Client()})
+	_, _, err := src.Lookup(ctx, "DB_MAIN_HOST")
+	if !errors.Is(err, ErrSourceUnavailable) {
+		t.Errorf("expected ErrSourceUnavailable but %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package env
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// The usageTracing is true if the read keys are recorded
+	// (see TraceUsage).
+	usageTracing atomic.Bool
+
+	// The usedKeys is the set of the keys read while tracing.
+	usedKeys map[string]bool
+
+	// The usedKeysMu protects the usedKeys.
+	usedKeysMu sync.Mutex
+)
+
+// TraceUsage sets whether the keys read from the environment by the Get,
+// Lookup, GetRequired, LookupAll, GetAs (and other typed getters) and
+// Unmarshal (and the functions based on it, like Bind and Reloader)
+// functions are recorded. Only the keys that are present in the
+// environment are recorded, so for the field with the fallback names
+// (like env:"NEW|OLD") the name that is actually used is recorded.
+// The recorded keys are cleared when the tracing is enabled. Returns
+// the previous mode.
+//
+// It allows operators to compare the declared configuration with the
+// configuration that is actually consumed and to find the dead tags
+// (see UsedKeys). The tracing is disabled by default.
+//
+// # Examples
+//
+//	env.TraceUsage(true)
+//
+//	var config Config
+//	if err := env.Unmarshal("APP_", &config); err != nil {
+//		log.Fatal(err)
+//	}
+//	...
+//	fmt.Println(env.UsedKeys()) // [APP_HOST APP_PORT]
+func TraceUsage(enabled bool) bool {
+	usedKeysMu.Lock()
+	defer usedKeysMu.Unlock()
+
+	if enabled {
+		usedKeys = make(map[string]bool)
+	}
+
+	return usageTracing.Swap(enabled)
+}
+
+// UsedKeys returns the sorted keys that have been read from the
+// environment since the tracing has been enabled by TraceUsage.
+// The keys recorded before the tracing is disabled are kept.
+//
+// # Examples
+//
+//	declared := []string{"APP_HOST", "APP_PORT", "APP_DEBUG"}
+//	used := env.UsedKeys()
+//	for _, key := range declared {
+//		i := sort.SearchStrings(used, key)
+//		if i == len(used) || used[i] != key {
+//			fmt.Println("not used:", key)
+//		}
+//	}
+func UsedKeys() []string {
+	usedKeysMu.Lock()
+	defer usedKeysMu.Unlock()
+
+	keys := make([]string, 0, len(usedKeys))
+	for key := range usedKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// The traceKey records the key as read if the tracing is enabled.
+func traceKey(key string) {
+	if !usageTracing.Load() {
+		return
+	}
+
+	usedKeysMu.Lock()
+	defer usedKeysMu.Unlock()
+	usedKeys[key] = true
+}
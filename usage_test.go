@@ -0,0 +1,61 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestTraceUsage tests TraceUsage and UsedKeys functions.
+func TestTraceUsage(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST|SERVER"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+
+	defer TraceUsage(TraceUsage(false))
+
+	os.Clearenv()
+	os.Setenv("APP_SERVER", "localhost") // fallback name
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("TIMEOUT", "5s")
+	os.Setenv("USER", "goloop")
+
+	// The keys aren't recorded without tracing.
+	Get("USER")
+	if v := UsedKeys(); len(v) != 0 {
+		t.Errorf("expected no keys but `%v`", v)
+	}
+
+	TraceUsage(true)
+	var c config
+	if err := Unmarshal("APP_", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GetDuration("TIMEOUT", 0); err != nil {
+		t.Fatal(err)
+	}
+	Get("MISSING") // isn't recorded
+
+	expected := "APP_PORT APP_SERVER TIMEOUT"
+	if v := strings.Join(UsedKeys(), " "); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+
+	// The snapshot mode.
+	defer SnapshotMode(SnapshotMode(true))
+	TraceUsage(true) // clears the recorded keys
+	if err := Unmarshal("APP_", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	TraceUsage(false)
+	Lookup("USER") // isn't recorded
+
+	expected = "APP_PORT APP_SERVER"
+	if v := strings.Join(UsedKeys(), " "); v != expected {
+		t.Errorf("expected `%s` but `%s`", expected, v)
+	}
+}
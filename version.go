@@ -0,0 +1,219 @@
+package env
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// The modulePath is the import path of the package module,
+// used to find the package version in the build information.
+const modulePath = "github.com/goloop/env"
+
+var (
+	// The version is the semantic version of the package. It can be
+	// injected at compile time, otherwise it is taken from the build
+	// information of the binary (the version of the required module).
+	//
+	//	go build -ldflags "-X github.com/goloop/env.version=v1.2.3"
+	version = ""
+
+	// The commit is the hash of the commit from which the package
+	// was built. It can be injected at compile time, otherwise it is
+	// taken from the VCS information of the build (if available).
+	//
+	//	go build -ldflags "-X github.com/goloop/env.commit=$(git rev-parse HEAD)"
+	commit = ""
+)
+
+// Version is the semantic version of the package with build metadata.
+type Version struct {
+	Major  int    // incompatible API changes
+	Minor  int    // backward compatible functionality
+	Patch  int    // backward compatible bug fixes
+	Commit string // commit hash, can be empty
+}
+
+// String returns the version as string like v1.2.3 or v1.2.3+commit.
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Commit != "" {
+		s += "+" + v.Commit
+	}
+
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is less than,
+// equal to or greater than the other version. The commit hash
+// isn't taken into account.
+func (v Version) Compare(other Version) int {
+	a := [3]int{v.Major, v.Minor, v.Patch}
+	b := [3]int{other.Major, other.Minor, other.Patch}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		} else if a[i] > b[i] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// ParseVersion parses the version string like v1.2.3, 1.2 or 1.2.3+commit.
+// The missing minor and patch numbers are considered zero, pre-release
+// suffixes (like -rc.1) are ignored.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s, v.Commit = s[:i], s[i+1:]
+	}
+
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || s == "" {
+		return v, fmt.Errorf("incorrect version: %s", s)
+	}
+
+	numbers := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("incorrect version: %s", s)
+		}
+		*numbers[i] = n
+	}
+
+	return v, nil
+}
+
+// CurrentVersion returns the version of the package.
+//
+// The version is taken from the value injected at compile time or from
+// the build information of the binary. If the package is built as the
+// main module (e.g. in development), the version is v0.0.0.
+func CurrentVersion() Version {
+	var v Version
+
+	s, c := version, commit
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if s == "" {
+			s = moduleVersion(info)
+		}
+
+		if c == "" && info.Main.Path == modulePath {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					c = setting.Value
+				}
+			}
+		}
+	}
+
+	if tmp, err := ParseVersion(s); err == nil {
+		v = tmp
+	}
+	v.Commit = c
+
+	return v
+}
+
+// The moduleVersion returns the version of the package module
+// from the build information.
+func moduleVersion(info *debug.BuildInfo) string {
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+
+	return ""
+}
+
+// RequireVersion returns an error if the package version doesn't satisfy
+// the constraint. The constraint consists of one or more comma-separated
+// conditions, and all of them must be met.
+//
+// Each condition is an operator followed by a version. The following
+// operators are supported:
+//
+//   - =, == the same version (used if the operator is omitted);
+//   - !=    any other version;
+//   - >, >= greater than (or equal to) the version;
+//   - <, <= less than (or equal to) the version;
+//   - ~     the same major and minor version, at least the given patch;
+//   - ^     the same major version, at least the given version.
+//
+// # Examples
+//
+//	if err := env.RequireVersion(">=1.2.0, <2"); err != nil {
+//		log.Fatal(err)
+//	}
+func RequireVersion(constraint string) error {
+	return requireVersion(CurrentVersion(), constraint)
+}
+
+// The requireVersion checks that version v satisfies the constraint.
+func requireVersion(v Version, constraint string) error {
+	for _, cond := range strings.Split(constraint, ",") {
+		cond = strings.TrimSpace(cond)
+
+		// Separate the operator from the version.
+		i := strings.IndexFunc(cond, func(r rune) bool {
+			return !strings.ContainsRune("=!<>~^", r)
+		})
+		if i < 0 {
+			return fmt.Errorf("incorrect version constraint: %s", cond)
+		}
+
+		op := cond[:i]
+		target, err := ParseVersion(cond[i:])
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		cmp := v.Compare(target)
+		switch op {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~":
+			ok = cmp >= 0 && v.Major == target.Major &&
+				v.Minor == target.Minor
+		case "^":
+			ok = cmp >= 0 && v.Major == target.Major
+		default:
+			return fmt.Errorf("incorrect version operator: %s", op)
+		}
+
+		if !ok {
+			return fmt.Errorf("version %s doesn't satisfy %s", v, cond)
+		}
+	}
+
+	return nil
+}
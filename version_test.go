@@ -0,0 +1,88 @@
+package env
+
+import "testing"
+
+// TestParseVersion tests ParseVersion function.
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		value  string
+		result string
+		err    bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"1.2.3", "v1.2.3", false},
+		{"1.2", "v1.2.0", false},
+		{"v2", "v2.0.0", false},
+		{"v1.2.3-rc.1", "v1.2.3", false},
+		{"v1.2.3+abc", "v1.2.3+abc", false},
+		{"", "", true},
+		{"(devel)", "", true},
+		{"1.2.3.4", "", true},
+		{"1.a.3", "", true},
+	}
+
+	for i, s := range tests {
+		v, err := ParseVersion(s.value)
+		if s.err {
+			if err == nil {
+				t.Errorf("test %d is failed, expected an error", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test %d is failed, unexpected error: %v", i, err)
+		} else if v.String() != s.result {
+			t.Errorf("test %d is failed, expected %s but %s", i, s.result, v)
+		}
+	}
+}
+
+// TestRequireVersion tests version constraints.
+func TestRequireVersion(t *testing.T) {
+	v := Version{Major: 1, Minor: 2, Patch: 3}
+	tests := []struct {
+		constraint string
+		ok         bool
+	}{
+		{"1.2.3", true},
+		{"=1.2.3", true},
+		{"!=1.2.3", false},
+		{">=1.2", true},
+		{">1.2.3", false},
+		{"<2", true},
+		{"<=1.2.2", false},
+		{">=1.0.0, <2.0.0", true},
+		{">=1.0.0, <1.2.0", false},
+		{"~1.2.0", true},
+		{"~1.1.0", false},
+		{"^1.0.0", true},
+		{"^1.3.0", false},
+		{"^0.9", false},
+		{"=>1.0", false},
+		{">=x", false},
+	}
+
+	for i, s := range tests {
+		err := requireVersion(v, s.constraint)
+		if s.ok && err != nil {
+			t.Errorf("test %d is failed, unexpected error: %v", i, err)
+		} else if !s.ok && err == nil {
+			t.Errorf("test %d is failed, expected an error", i)
+		}
+	}
+}
+
+// TestCurrentVersion tests the version injected at compile time.
+func TestCurrentVersion(t *testing.T) {
+	defer func(v, c string) { version, commit = v, c }(version, commit)
+
+	version, commit = "v1.2.3", "abc"
+	if v := CurrentVersion(); v.String() != "v1.2.3+abc" {
+		t.Errorf("expected v1.2.3+abc but %s", v)
+	}
+
+	if err := RequireVersion("^1.2"); err != nil {
+		t.Error(err)
+	}
+}
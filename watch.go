@@ -0,0 +1,217 @@
+package env
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// The defPollInterval is the default interval of the polling.
+const defPollInterval = time.Second
+
+// WatchMode selects how the FileWatcher finds the changes of the file.
+type WatchMode int32
+
+const (
+	// WatchAuto uses the notifications of the file system and falls
+	// back to the polling if they are unavailable or unreliable, like
+	// on the NFS and SMB shares.
+	WatchAuto WatchMode = iota
+
+	// WatchNotify uses the notifications of the file system only
+	// (inotify on Linux).
+	WatchNotify
+
+	// WatchPoll checks the file periodically.
+	WatchPoll
+)
+
+// String returns the name of the mode.
+func (m WatchMode) String() string {
+	switch m {
+	case WatchNotify:
+		return "notify"
+	case WatchPoll:
+		return "poll"
+	}
+
+	return "auto"
+}
+
+// The fileState is the state of the watched file.
+type fileState struct {
+	exists bool
+	mod    time.Time
+	size   int64
+	hash   [sha256.Size]byte
+}
+
+// The statFile returns the state of the file. The content is hashed
+// only if the modification time or size differ from the prev state.
+func statFile(filename string, prev fileState) (fileState, error) {
+	info, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileState{}, nil
+	} else if err != nil {
+		return prev, err
+	}
+
+	state := fileState{exists: true, mod: info.ModTime(), size: info.Size()}
+	if prev.exists && state.mod.Equal(prev.mod) && state.size == prev.size {
+		state.hash = prev.hash
+		return state, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileState{}, nil
+	} else if err != nil {
+		return prev, err
+	}
+
+	state.hash = sha256.Sum256(data)
+	return state, nil
+}
+
+// The notifier delivers the notifications of the file system
+// about the changes in the directory of the watched file.
+type notifier struct {
+	file   *os.File
+	events chan struct{} // closed when the notifier stops
+	err    error         // reading error, valid after events is closed
+}
+
+// The close stops the notifier.
+func (n *notifier) close() error {
+	return n.file.Close()
+}
+
+// FileWatcher watches the file (like the env-file or mounted secret) and
+// calls the function when its content changes. The change is found by
+// the modification time and the hash of the content, so touching the
+// file or rewriting it with the same content isn't a change, while the
+// appearance and removal of the file are changes.
+type FileWatcher struct {
+	filename string
+	mode     WatchMode
+	interval time.Duration
+	onChange func()
+
+	state  fileState
+	active atomic.Int32 // mode used by Run
+}
+
+// NewFileWatcher returns the watcher of the file. The interval is the
+// interval of the polling (one second if it's zero), the onChange is
+// called from the goroutine of the Run method on each change.
+//
+// The notifications of the file system are unreliable on some platforms
+// and file systems (like NFS or some container volumes), the WatchPoll
+// mode checks the file periodically instead, and the WatchAuto mode
+// selects the polling automatically in these cases.
+//
+// # Examples
+//
+//	w := env.NewFileWatcher(".env", env.WatchAuto, 0, func() {
+//		if err := env.Update(".env"); err == nil {
+//			r.Trigger() // see Reloader
+//		}
+//	})
+//
+//	go w.Run(ctx)
+func NewFileWatcher(filename string, mode WatchMode, interval time.Duration,
+	onChange func()) *FileWatcher {
+	if interval <= 0 {
+		interval = defPollInterval
+	}
+
+	w := &FileWatcher{
+		filename: filename,
+		mode:     mode,
+		interval: interval,
+		onChange: onChange,
+	}
+	w.active.Store(int32(mode))
+
+	return w
+}
+
+// Mode returns the mode used by the Run method, it's WatchNotify or
+// WatchPoll for the WatchAuto mode after the watching is started.
+func (w *FileWatcher) Mode() WatchMode {
+	return WatchMode(w.active.Load())
+}
+
+// Run watches the file until the context is canceled and returns the
+// error of the context. Returns ErrNotifyUnsupported at once in the
+// WatchNotify mode if the notifications aren't supported.
+func (w *FileWatcher) Run(ctx context.Context) error {
+	state, err := statFile(w.filename, fileState{})
+	if err != nil {
+		return err
+	}
+	w.state = state
+
+	mode := w.mode
+	if mode == WatchAuto && isRemoteFS(w.filename) {
+		mode = WatchPoll
+	}
+
+	var n *notifier
+	if mode != WatchPoll {
+		n, err = newNotifier(w.filename)
+		if err != nil && mode == WatchNotify {
+			return err
+		} else if err != nil {
+			mode = WatchPoll // automatic fallback
+		} else {
+			mode = WatchNotify
+			defer n.close()
+		}
+	}
+	w.active.Store(int32(mode))
+
+	if mode == WatchPoll {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-n.events:
+			if !ok {
+				return n.err
+			}
+			w.check()
+		}
+	}
+}
+
+// The check calls the onChange function if the file has changed.
+func (w *FileWatcher) check() {
+	state, err := statFile(w.filename, w.state)
+	if err != nil {
+		return // the file will be checked on the next event
+	}
+
+	changed := state.exists != w.state.exists || state.hash != w.state.hash
+	w.state = state
+	if changed && w.onChange != nil {
+		w.onChange()
+	}
+}
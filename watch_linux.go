@@ -0,0 +1,85 @@
+//go:build linux
+
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// The magic numbers of the remote file systems, the inotify doesn't
+// report the changes made on other hosts.
+const (
+	nfsSuperMagic  = 0x6969
+	smbSuperMagic  = 0x517b
+	cifsSuperMagic = 0xff534d42
+	smb2SuperMagic = 0xfe534d42
+)
+
+// The isRemoteFS returns true if the directory
+// of the file is on the remote file system.
+func isRemoteFS(filename string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(filepath.Dir(filename), &st); err != nil {
+		return false
+	}
+
+	switch uint32(st.Type) {
+	case nfsSuperMagic, smbSuperMagic, cifsSuperMagic, smb2SuperMagic:
+		return true
+	}
+
+	return false
+}
+
+// The newNotifier starts the inotify watching of the directory of the
+// file, so the replacement of the file (by rename, like editors and
+// Kubernetes do) is found as well as writing into it.
+func newNotifier(filename string) (*notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	// The file is checked when the writing is finished, not on each
+	// write (so the truncated file isn't reported while it's rewritten).
+	const mask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_ATTRIB |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+	_, err = syscall.InotifyAddWatch(fd, filepath.Dir(filename), mask)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// The non-blocking descriptor is served by the runtime poller,
+	// so the reading is interrupted by closing the file.
+	n := &notifier{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan struct{}, 1),
+	}
+
+	go func() {
+		defer close(n.events)
+
+		buf := make([]byte, 4096)
+		for {
+			if _, err := n.file.Read(buf); err != nil {
+				if !errors.Is(err, os.ErrClosed) {
+					n.err = err
+				}
+				return
+			}
+
+			// The events are merged, the file is checked anyway.
+			select {
+			case n.events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return n, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package env
+
+// The isRemoteFS returns true if the directory of the file is on the
+// remote file system, it's unknown on this platform.
+func isRemoteFS(filename string) bool {
+	return false
+}
+
+// The newNotifier returns ErrNotifyUnsupported,
+// the notifications aren't supported on this platform.
+func newNotifier(filename string) (*notifier, error) {
+	return nil, ErrNotifyUnsupported
+}
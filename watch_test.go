@@ -0,0 +1,117 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// The watchFile runs the watcher of the file in the given mode and
+// returns the channel of the change events and the watcher.
+func watchFile(t *testing.T, filename string,
+	mode WatchMode) (chan struct{}, *FileWatcher) {
+	changes := make(chan struct{}, 10)
+	w := NewFileWatcher(filename, mode, 10*time.Millisecond, func() {
+		changes <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("expected `%v` but `%v`", context.Canceled, err)
+		}
+	})
+
+	// Wait for the watcher to start.
+	for i := 0; i < 100 && w.Mode() == WatchAuto; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	return changes, w
+}
+
+// The expectChange checks whether the change has been reported.
+func expectChange(t *testing.T, changes chan struct{}, expected bool) {
+	t.Helper()
+
+	timeout := 100 * time.Millisecond
+	if expected {
+		timeout = 2 * time.Second
+	}
+
+	select {
+	case <-changes:
+		if !expected {
+			t.Error("unexpected change")
+		}
+	case <-time.After(timeout):
+		if expected {
+			t.Error("expected change but none")
+		}
+	}
+}
+
+// TestFileWatcher tests FileWatcher in all modes.
+func TestFileWatcher(t *testing.T) {
+	modes := []WatchMode{WatchPoll, WatchAuto}
+	if runtime.GOOS == "linux" {
+		modes = append(modes, WatchNotify)
+	}
+
+	for _, mode := range modes {
+		t.Run(mode.String(), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(filename, []byte("A=1\n"),
+				0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			changes, w := watchFile(t, filename, mode)
+			if mode == WatchPoll && w.Mode() != WatchPoll {
+				t.Errorf("expected poll mode but %s", w.Mode())
+			}
+
+			// Modification of the content.
+			os.WriteFile(filename, []byte("A=2\n"), 0o644)
+			expectChange(t, changes, true)
+
+			// The same content isn't a change.
+			later := time.Now().Add(time.Minute)
+			os.WriteFile(filename, []byte("A=2\n"), 0o644)
+			os.Chtimes(filename, later, later)
+			expectChange(t, changes, false)
+
+			// Replacement by renaming.
+			tmp := filename + ".tmp"
+			os.WriteFile(tmp, []byte("A=3\n"), 0o644)
+			os.Rename(tmp, filename)
+			expectChange(t, changes, true)
+
+			// Removal.
+			os.Remove(filename)
+			expectChange(t, changes, true)
+		})
+	}
+}
+
+// TestWatchMode tests WatchMode.String method.
+func TestWatchMode(t *testing.T) {
+	tests := map[WatchMode]string{
+		WatchAuto:   "auto",
+		WatchNotify: "notify",
+		WatchPoll:   "poll",
+	}
+
+	for mode, expected := range tests {
+		if s := mode.String(); s != expected {
+			t.Errorf("expected `%s` but `%s`", expected, s)
+		}
+	}
+}
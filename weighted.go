@@ -0,0 +1,125 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Weighted is the value with its weight, the item of the WeightedList.
+type Weighted struct {
+	Value  string
+	Weight float64
+}
+
+// WeightedList is the list of the values with weights, like the
+// traffic splitting (a;0.7,b;0.3) or the preferences in the style of
+// the Accept header (gzip;q=1.0,br;q=0.8). The items are separated by
+// commas, the weight follows the value after a semicolon, optionally
+// with the q= prefix. The weight is 1 if it isn't set, the weights
+// can't be negative, infinite or NaN.
+//
+// # Examples
+//
+//	type Config struct {
+//		Backends env.WeightedList `env:"BACKENDS" def:"blue;0.9,green;0.1"`
+//	}
+//
+//	var config Config
+//	env.Unmarshal("", &config)
+//	backend := config.Backends.Pick(rand.Float64()) // blue in 90% cases
+type WeightedList []Weighted
+
+// Initializer.
+func init() {
+	RegisterDecoder(ParseWeightedList)
+}
+
+// ParseWeightedList parses the list of the weighted values.
+func ParseWeightedList(value string) (WeightedList, error) {
+	var list WeightedList
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		v, w, ok := strings.Cut(item, ";")
+		weighted := Weighted{Value: strings.TrimSpace(v), Weight: 1}
+		if ok {
+			w = strings.TrimSpace(w)
+			w = strings.TrimSpace(strings.TrimPrefix(w, "q="))
+			f, err := strconv.ParseFloat(w, 64)
+			if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+				return nil, fmt.Errorf("invalid weight of %q: %q",
+					weighted.Value, w)
+			}
+			weighted.Weight = f
+		}
+
+		if weighted.Value == "" {
+			return nil, fmt.Errorf("empty value in %q", item)
+		}
+		list = append(list, weighted)
+	}
+
+	return list, nil
+}
+
+// Total returns the sum of the weights.
+func (l WeightedList) Total() float64 {
+	var total float64
+	for _, item := range l {
+		total += item.Weight
+	}
+
+	return total
+}
+
+// Sorted returns the copy of the list sorted by the weights in
+// descending order (the items with equal weights keep their order),
+// like the preferences from the most to the least preferred.
+func (l WeightedList) Sorted() WeightedList {
+	sorted := make(WeightedList, len(l))
+	copy(sorted, l)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Weight > sorted[j].Weight
+	})
+
+	return sorted
+}
+
+// Pick returns the value chosen by x from [0, 1) in proportion to
+// the weights, like the random backend for x = rand.Float64().
+// Returns the empty string if the list is empty or all weights
+// are zero.
+func (l WeightedList) Pick(x float64) string {
+	threshold := x * l.Total()
+	for _, item := range l {
+		if item.Weight > 0 && threshold < item.Weight {
+			return item.Value
+		}
+		threshold -= item.Weight
+	}
+
+	// The rounding error for x close to 1.
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].Weight > 0 {
+			return l[i].Value
+		}
+	}
+
+	return ""
+}
+
+// String returns the list in the format of ParseWeightedList.
+func (l WeightedList) String() string {
+	items := make([]string, len(l))
+	for i, item := range l {
+		items[i] = item.Value + ";" +
+			strconv.FormatFloat(item.Weight, 'g', -1, 64)
+	}
+
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,76 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestParseWeightedList tests ParseWeightedList function.
+func TestParseWeightedList(t *testing.T) {
+	list, err := ParseWeightedList("a;0.7, b ; q=0.2,c,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := WeightedList{{"a", 0.7}, {"b", 0.2}, {"c", 1}}
+	if !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected `%v` but `%v`", expected, list)
+	}
+
+	if s := list.String(); s != "a;0.7,b;0.2,c;1" {
+		t.Errorf("expected `a;0.7,b;0.2,c;1` but `%s`", s)
+	}
+
+	sorted := list.Sorted()
+	if sorted[0].Value != "c" || sorted[2].Value != "b" ||
+		list[0].Value != "a" {
+		t.Errorf("incorrect sorting: %v", sorted)
+	}
+
+	for _, value := range []string{"a;-1", "a;x", ";0.5", "a;q=Inf",
+		"a;NaN", "a;q=nan"} {
+		if _, err := ParseWeightedList(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+// TestWeightedListPick tests the choice of the values by weights.
+func TestWeightedListPick(t *testing.T) {
+	list := WeightedList{{"a", 0.7}, {"b", 0}, {"c", 0.3}}
+	for x, want := range map[float64]string{
+		0: "a", 0.69: "a", 0.7: "c", 0.99: "c", 0.9999999999: "c",
+	} {
+		if v := list.Pick(x); v != want {
+			t.Errorf("%v: expected `%s` but `%s`", x, want, v)
+		}
+	}
+
+	if v := (WeightedList{{"a", 0}}).Pick(0.5); v != "" {
+		t.Errorf("expected empty value but `%s`", v)
+	}
+}
+
+// TestUnmarshalWeightedList tests the WeightedList fields.
+func TestUnmarshalWeightedList(t *testing.T) {
+	type config struct {
+		Backends WeightedList `env:"BACKENDS" def:"blue;0.9,green;0.1"`
+	}
+
+	os.Clearenv()
+	var data config
+	if err := Unmarshal("", &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data.Backends) != 2 || data.Backends[1].Weight != 0.1 {
+		t.Errorf("incorrect values: %+v", data)
+	}
+
+	items, err := marshalEnv("", data, true)
+	if err != nil || items[0] != "BACKENDS=blue;0.9,green;0.1" {
+		t.Errorf("expected `BACKENDS=blue;0.9,green;0.1` but `%v, %v`",
+			items, err)
+	}
+}